@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"gbackup-new/backend/internal/handler"
@@ -64,22 +68,41 @@ func main() {
 	logRepo := repository.NewLogRepository(dbInstance)
 	monitorRepo := repository.NewMonitoringRepository(dbInstance)
 	browserRepo := repository.NewBrowserRepository()
+	queueRepo := repository.NewQueueRepository(dbInstance)
+	runRepo := repository.NewJobRunRepository(dbInstance)
+	blackoutRepo := repository.NewBlackoutRepository(dbInstance)
+	workflowRepo := repository.NewWorkflowRepository(dbInstance)
+	snapshotRepo := repository.NewSnapshotRepository(dbInstance)
+	catalogRepo := repository.NewCatalogRepository(dbInstance)
+	restorePreviewRepo := repository.NewRestorePreviewRepository(dbInstance)
+	restoreRecordRepo := repository.NewRestoreRecordRepository(dbInstance)
 
 	// Services
 	authSvc := service.NewAuthService(userRepo, jwtSecretKey)
 	monitorSvc := service.NewMonitoringService(monitorRepo, logRepo, jobRepo)
-	backupSvc := service.NewBackupService(jobRepo, logRepo, monitorRepo, monitorSvc)
-	schedulerSvc := service.NewSchedulerService(jobRepo, backupSvc)
+	dispatcher := service.NewJobDispatcher(queueRepo, jobRepo, service.LoadDispatcherConfig())
+	progressBus := service.NewProgressBus()
+	backupSvc := service.NewBackupService(jobRepo, logRepo, runRepo, snapshotRepo, catalogRepo, restoreRecordRepo, restorePreviewRepo, monitorRepo, monitorSvc, dispatcher, progressBus)
+	workflowSvc := service.NewWorkflowService(workflowRepo, jobRepo, runRepo, dispatcher)
+	schedulerSvc := service.NewSchedulerService(jobRepo, runRepo, blackoutRepo, backupSvc, workflowSvc, dispatcher)
 	browserSvc := service.NewBrowserService(browserRepo)
+	restoreSvc := service.NewRestoreService(jobRepo, snapshotRepo, restorePreviewRepo, restoreRecordRepo, runRepo, backupSvc, dispatcher)
 
 	// Handlers
 	authHandler := handler.NewAuthHandler(authSvc)
 	monitorHandler := handler.NewMonitoringHandler(monitorSvc, schedulerSvc, logRepo)
 	jobHandler := handler.NewJobHandler(schedulerSvc, backupSvc, jobRepo)
-	backupHandler := handler.NewBackupHandler(backupSvc)
-	restoreHandler := handler.NewRestoreHandler(backupSvc)
+	backupHandler := handler.NewBackupHandler(backupSvc, restoreSvc)
+	restoreHandler := handler.NewRestoreHandler(backupSvc, restoreSvc)
 	browserHandler := handler.NewBrowserHandler(browserSvc)
 	setupHandler := handler.NewSetupHandler(authSvc)
+	queueHandler := handler.NewQueueHandler(dispatcher)
+	progressHandler := handler.NewProgressHandler(progressBus)
+	runHandler := handler.NewRunHandler(runRepo)
+	blackoutHandler := handler.NewBlackoutHandler(blackoutRepo)
+	workflowHandler := handler.NewWorkflowHandler(workflowSvc)
+	snapshotHandler := handler.NewSnapshotHandler(snapshotRepo)
+	catalogHandler := handler.NewCatalogHandler(catalogRepo, snapshotRepo)
 
 	// Echo Setup
 	e := echo.New()
@@ -115,6 +138,17 @@ func main() {
 	r.GET("/jobs/scheduled", jobHandler.GetScheduledJobs)
 	r.GET("/jobs/script/:id", jobHandler.GetJobScript)
 	r.POST("/jobs/trigger/:id", jobHandler.TriggerManualJob)
+	r.POST("/jobs/:id/cancel", jobHandler.CancelJob)
+	r.POST("/jobs/:id/pause", jobHandler.PauseJob)
+	r.POST("/jobs/:id/resume", jobHandler.ResumeJob)
+	r.GET("/jobs/:id/retention/preview", jobHandler.GetRetentionPreview)
+	r.GET("/jobs/:id/snapshots", snapshotHandler.GetJobSnapshots)
+	r.POST("/jobs/:id/snapshots/claim", jobHandler.ClaimLegacySnapshots)
+	r.GET("/jobs/:id/progress", progressHandler.GetProgress)
+	r.GET("/jobs/:id/progress/stream", progressHandler.StreamProgress)
+	r.GET("/jobs/:id/runs", runHandler.GetJobRuns)
+	r.GET("/runs/:runId", runHandler.GetRunByID)
+	r.GET("/runs/:runId/restore-results", runHandler.GetRestoreResults)
 	r.GET("/jobs/manual", jobHandler.GetManualJob)
 	r.DELETE("/jobs/delete/:id", jobHandler.DeleteJob)
 	r.PUT("/jobs/update/:id", jobHandler.UpdateJob)
@@ -124,11 +158,60 @@ func main() {
 	// Actions
 	r.POST("/jobs/new", backupHandler.CreateNewJob)
 	r.POST("/jobs/restore", restoreHandler.TriggerRestore)
+	r.POST("/jobs/:id/restore", restoreHandler.TriggerSnapshotRestore)
+	r.POST("/restore/preview", restoreHandler.PreviewRestore)
+	r.GET("/restore/preview/:id", restoreHandler.GetRestorePreview)
+	r.POST("/restore/preview/:id/execute", restoreHandler.ExecuteRestorePreview)
+	r.GET("/restores", restoreHandler.ListRestores)
+	r.GET("/restores/:id", restoreHandler.GetRestore)
+	r.POST("/restore/records/:id/cancel", restoreHandler.CancelRestore)
+	r.GET("/restore/records/:id/progress", progressHandler.GetRestoreProgress)
+	r.GET("/restore/records/:id/progress/stream", progressHandler.StreamRestoreProgress)
 	r.GET("/browser/files", browserHandler.ListFiles)
 	r.GET("/browser/remotes", browserHandler.GetAvailableRemotes)
 	r.GET("/browser/info", browserHandler.GetFileInfo)
+	r.GET("/queue", queueHandler.GetQueue)
+	r.GET("/schedule/calendar", jobHandler.GetScheduleCalendar)
+
+	// Blackout Windows
+	r.GET("/blackouts", blackoutHandler.ListBlackouts)
+	r.POST("/blackouts", blackoutHandler.CreateBlackout)
+	r.PUT("/blackouts/:id", blackoutHandler.UpdateBlackout)
+	r.DELETE("/blackouts/:id", blackoutHandler.DeleteBlackout)
+
+	// Snapshots
+	r.POST("/snapshots/:id/pin", snapshotHandler.PinSnapshot)
+	r.POST("/snapshots/:id/unpin", snapshotHandler.UnpinSnapshot)
+	r.GET("/snapshots/:id/manifest", catalogHandler.GetSnapshotManifest)
+	r.GET("/catalog/search", catalogHandler.SearchCatalog)
+
+	// Workflows
+	r.GET("/workflows", workflowHandler.ListWorkflows)
+	r.POST("/workflows", workflowHandler.CreateWorkflow)
+	r.GET("/workflows/:id", workflowHandler.GetWorkflow)
+	r.PUT("/workflows/:id", workflowHandler.UpdateWorkflow)
+	r.DELETE("/workflows/:id", workflowHandler.DeleteWorkflow)
+	r.POST("/workflows/:id/trigger", workflowHandler.TriggerWorkflow)
+	r.GET("/workflows/:id/runs", workflowHandler.GetWorkflowRuns)
+	r.GET("/workflow-runs/:runId", workflowHandler.GetWorkflowRun)
 
 	// Start Daemons
+	// Antrean tersimpan dimuat lebih dulu agar antre ulang saat recovery tidak menduplikasi entry
+	if err := dispatcher.LoadQueue(); err != nil {
+		log.Fatalf("Gagal memuat antrean dispatcher: %v", err)
+	}
+	// Job yang tertinggal RUNNING dari proses sebelumnya pasti yatim. Harus sebelum dispatcher
+	// mulai, agar run baru dari antrean tidak ikut ditandai INTERRUPTED.
+	if _, err := backupSvc.RecoverInterruptedJobs(0); err != nil {
+		fmt.Printf("❌ ERROR: Gagal rekonsiliasi job RUNNING: %v\n", err)
+	}
+	// Step workflow yang selesai/terputus saat backend mati dilanjutkan
+	if err := workflowSvc.ReconcileRuns(); err != nil {
+		fmt.Printf("❌ ERROR: Gagal rekonsiliasi workflow: %v\n", err)
+	}
+	if err := dispatcher.Start(); err != nil {
+		log.Fatalf("Gagal menjalankan dispatcher: %v", err)
+	}
 	schedulerSvc.StartDaemon()
 	monitorSvc.StartMonitoringDaemon()
 
@@ -160,5 +243,41 @@ func main() {
 	}()
 
 	fmt.Println("\n✅ Backend diinisialisasi. Menjalankan Echo server di port 8080")
-	e.Logger.Fatal(e.Start(":8080"))
+
+	stopCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := e.Start(":8080"); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	<-stopCtx.Done()
+	stop()
+	fmt.Println("\n[SHUTDOWN] Sinyal diterima, menghentikan backend...")
+
+	// 1. Tolak request HTTP baru (stream SSE yang masih terbuka diputus setelah timeout)
+	httpCtx, cancelHTTP := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	if err := e.Shutdown(httpCtx); err != nil {
+		fmt.Printf("⚠️ [SHUTDOWN] HTTP server dipaksa berhenti: %v\n", err)
+		e.Close()
+	}
+	cancelHTTP()
+
+	// 2. Hentikan daemon agar tidak ada job/pengecekan baru
+	schedulerSvc.StopDaemon()
+	monitorSvc.StopMonitoringDaemon()
+
+	// 3. Tunggu job berjalan, sisanya dihentikan sebagai INTERRUPTED
+	backupSvc.Shutdown(service.LoadShutdownGrace())
+
+	// 4. Koneksi DB ditutup paling akhir
+	if sqlDB, err := dbInstance.DB(); err == nil {
+		sqlDB.Close()
+	}
+	fmt.Println("✅ [SHUTDOWN] Backend berhenti.")
 }
+
+// httpShutdownTimeout: Batas waktu request HTTP yang sedang berjalan saat shutdown
+const httpShutdownTimeout = 10 * time.Second
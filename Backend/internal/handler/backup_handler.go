@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"gbackup-new/backend/internal/models"
 	"gbackup-new/backend/internal/service"
@@ -23,15 +24,150 @@ type BackupRequestDTO struct {
 	PreScript     string `json:"pre_script"`
 	PostScript    string `json:"post_script"`
 	MaxRetention  int    `json:"max_retention"`
+	// Batas waktu per fase (detik, 0 = tanpa batas)
+	PreScriptTimeoutSec  int `json:"pre_script_timeout_sec"`
+	TransferTimeoutSec   int `json:"transfer_timeout_sec"`
+	PostScriptTimeoutSec int `json:"post_script_timeout_sec"`
+	// Retry otomatis (retry_max_attempts = total percobaan, 0 = tanpa retry)
+	RetryMaxAttempts     int      `json:"retry_max_attempts"`
+	RetryInitialDelaySec int      `json:"retry_initial_delay_sec"`
+	RetryMultiplier      float64  `json:"retry_multiplier"`
+	RetryOn              []string `json:"retry_on"`
+	// Antre ulang otomatis jika run terputus karena crash/restart
+	RequeueOnInterrupt bool `json:"requeue_on_interrupt"`
+	// Jadwal terlewat: run_once (default), skip, run_all_missed; max_lateness_sec 0 = tanpa batas
+	MisfirePolicy  string `json:"misfire_policy"`
+	MaxLatenessSec int    `json:"max_lateness_sec"`
+	// Zona waktu schedule_cron (IANA, mis. Asia/Jakarta) dan blackout window yang berlaku
+	TimeZone          string `json:"time_zone"`
+	BlackoutWindowIDs []uint `json:"blackout_window_ids"`
+	// Retensi GFS untuk snapshot bertimestamp (kosong = max_retention sebagai keep_last)
+	Retention *RetentionRequestDTO `json:"retention"`
+}
+
+// RetentionRequestDTO: Kebijakan retensi GFS. Saat update, field yang tidak dikirim mempertahankan nilai lama.
+type RetentionRequestDTO struct {
+	KeepLast    *int    `json:"keep_last"`
+	KeepHourly  *int    `json:"keep_hourly"`
+	KeepDaily   *int    `json:"keep_daily"`
+	KeepWeekly  *int    `json:"keep_weekly"`
+	KeepMonthly *int    `json:"keep_monthly"`
+	KeepYearly  *int    `json:"keep_yearly"`
+	KeepWithin  *string `json:"keep_within"` // mis. 30d, 1y6m, 2w, 12h
+	DryRun      *bool   `json:"dry_run"`     // Hanya log keputusan retensi, tidak menghapus
+	// MinSuccessful: Jumlah minimal snapshot sukses yang tidak pernah dihapus (default 1)
+	MinSuccessful *int `json:"min_successful"`
+}
+
+// resolveRetention: Gabungkan request dengan aturan yang ada (current) lalu validasi
+func resolveRetention(req *RetentionRequestDTO, current service.RetentionPolicy) (service.RetentionPolicy, error) {
+	policy := current
+	if req != nil {
+		for _, f := range []struct {
+			src *int
+			dst *int
+		}{
+			{req.KeepLast, &policy.KeepLast},
+			{req.KeepHourly, &policy.KeepHourly},
+			{req.KeepDaily, &policy.KeepDaily},
+			{req.KeepWeekly, &policy.KeepWeekly},
+			{req.KeepMonthly, &policy.KeepMonthly},
+			{req.KeepYearly, &policy.KeepYearly},
+		} {
+			if f.src != nil {
+				*f.dst = *f.src
+			}
+		}
+		if req.KeepWithin != nil {
+			policy.KeepWithin = strings.ToLower(strings.TrimSpace(*req.KeepWithin))
+		}
+		if req.DryRun != nil {
+			policy.DryRun = *req.DryRun
+		}
+		if req.MinSuccessful != nil {
+			policy.MinSuccessful = *req.MinSuccessful
+		}
+	}
+	if err := service.ValidateRetentionPolicy(policy); err != nil {
+		return service.RetentionPolicy{}, err
+	}
+	return policy, nil
+}
+
+// MaxPhaseTimeoutSec: Batas atas timeout per fase (24 jam)
+const MaxPhaseTimeoutSec = 24 * 60 * 60
+
+// validatePhaseTimeouts: Timeout harus 0 (tanpa batas) atau 1..MaxPhaseTimeoutSec detik
+func validatePhaseTimeouts(pre, transfer, post int) error {
+	fields := []string{"pre_script_timeout_sec", "transfer_timeout_sec", "post_script_timeout_sec"}
+	for i, val := range []int{pre, transfer, post} {
+		if val < 0 || val > MaxPhaseTimeoutSec {
+			return fmt.Errorf("%s harus antara 0 dan %d detik", fields[i], MaxPhaseTimeoutSec)
+		}
+	}
+	return nil
+}
+
+// Batas kebijakan retry
+const (
+	MaxRetryAttempts     = 10
+	MaxRetryInitialDelay = 24 * 60 * 60
+	MaxRetryMultiplier   = 10.0
+)
+
+// validateRetryPolicy: Validasi kebijakan retry dan kembalikan retry_on dalam format kolom ("A,B")
+func validateRetryPolicy(maxAttempts, initialDelaySec int, multiplier float64, retryOn []string) (string, error) {
+	if maxAttempts < 0 || maxAttempts > MaxRetryAttempts {
+		return "", fmt.Errorf("retry_max_attempts harus antara 0 dan %d", MaxRetryAttempts)
+	}
+	if initialDelaySec < 0 || initialDelaySec > MaxRetryInitialDelay {
+		return "", fmt.Errorf("retry_initial_delay_sec harus antara 0 dan %d detik", MaxRetryInitialDelay)
+	}
+	if multiplier < 1 || multiplier > MaxRetryMultiplier {
+		return "", fmt.Errorf("retry_multiplier harus antara 1 dan %.0f", MaxRetryMultiplier)
+	}
+
+	var statuses []string
+	for _, status := range retryOn {
+		status = strings.ToUpper(strings.TrimSpace(status))
+		if !service.IsValidRetryStatus(status) {
+			return "", fmt.Errorf("status retry '%s' tidak valid. Pilihan: %s", status, strings.Join(service.RetryableStatuses, ", "))
+		}
+		statuses = append(statuses, status)
+	}
+	if len(statuses) == 0 {
+		return service.DefaultRetryOnStatuses, nil
+	}
+	return strings.Join(statuses, ","), nil
+}
+
+// MaxLatenessSec: Batas atas max_lateness_sec (7 hari)
+const MaxLatenessSec = 7 * 24 * 60 * 60
+
+// validateMisfirePolicy: Validasi kebijakan jadwal terlewat ("" = run_once)
+func validateMisfirePolicy(policy string, maxLatenessSec int) (string, error) {
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	if policy == "" {
+		policy = service.MisfirePolicyRunOnce
+	}
+	if !service.IsValidMisfirePolicy(policy) {
+		return "", fmt.Errorf("misfire_policy '%s' tidak valid. Pilihan: %s, %s, %s", policy,
+			service.MisfirePolicyRunOnce, service.MisfirePolicySkip, service.MisfirePolicyRunAllMissed)
+	}
+	if maxLatenessSec < 0 || maxLatenessSec > MaxLatenessSec {
+		return "", fmt.Errorf("max_lateness_sec harus antara 0 dan %d detik", MaxLatenessSec)
+	}
+	return policy, nil
 }
 
 type BackupHandler struct {
-	BackupSvc service.BackupService
+	BackupSvc  service.BackupService
+	RestoreSvc service.RestoreService
 }
 
 // NewBackupHandler adalah constructor (Factory)
-func NewBackupHandler(svc service.BackupService) *BackupHandler {
-	return &BackupHandler{BackupSvc: svc}
+func NewBackupHandler(svc service.BackupService, rSvc service.RestoreService) *BackupHandler {
+	return &BackupHandler{BackupSvc: svc, RestoreSvc: rSvc}
 }
 
 // ============================================================
@@ -68,6 +204,28 @@ func (h *BackupHandler) CreateNewJob(c echo.Context) error {
 		})
 	}
 
+	// RESTORE one-shot dicatat sebagai RestoreRecord (bukan template job)
+	if req.OperationMode == "RESTORE" {
+		record, err := h.RestoreSvc.StartPathRestore(service.PathRestoreRequest{
+			RemoteName:      req.RemoteName,
+			SourcePath:      req.SourcePath,
+			DestinationPath: req.DestinationPath,
+			RequestedBy:     restoreRequester(c),
+		})
+		if err != nil {
+			return c.JSON(restoreErrorStatus(err), map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusAccepted, map[string]interface{}{
+			"success":        true,
+			"message":        "Restore job dimulai.",
+			"restore_id":     record.ID,
+			"status":         record.Status,
+			"operation_mode": req.OperationMode,
+		})
+	}
+
 	// ⭐ HIGHLIGHT 2: SET DEFAULT RCLONE MODE
 	// ✅ Jika RcloneMode kosong, default ke "copy"
 	if req.RcloneMode == "" {
@@ -107,6 +265,52 @@ func (h *BackupHandler) CreateNewJob(c echo.Context) error {
 		fmt.Printf("[HANDLER VALIDATION] SYNC Mode: MaxRetention forced to 0 ✅\n")
 	}
 
+	if err := validatePhaseTimeouts(req.PreScriptTimeoutSec, req.TransferTimeoutSec, req.PostScriptTimeoutSec); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	// Default kebijakan retry
+	if req.RetryInitialDelaySec == 0 {
+		req.RetryInitialDelaySec = 60
+	}
+	if req.RetryMultiplier == 0 {
+		req.RetryMultiplier = 2
+	}
+	if err := service.ValidateCronExpression(req.ScheduleCron); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	retryOn, err := validateRetryPolicy(req.RetryMaxAttempts, req.RetryInitialDelaySec, req.RetryMultiplier, req.RetryOn)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := service.ValidateTimeZone(req.TimeZone); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	misfirePolicy, err := validateMisfirePolicy(req.MisfirePolicy, req.MaxLatenessSec)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	retention, err := resolveRetention(req.Retention, service.RetentionPolicy{MinSuccessful: service.DefaultMinSuccessfulSnapshots})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
 	// Placeholder untuk user ID
 	userID := uint(1)
 
@@ -127,7 +331,22 @@ func (h *BackupHandler) CreateNewJob(c echo.Context) error {
 		ScheduleCron:    req.ScheduleCron,
 		StatusQueue:     "PENDING",
 		MaxRetention:    req.MaxRetention, // ⭐ SUDAH DIVALIDASI
+
+		PreScriptTimeoutSec:  req.PreScriptTimeoutSec,
+		TransferTimeoutSec:   req.TransferTimeoutSec,
+		PostScriptTimeoutSec: req.PostScriptTimeoutSec,
+
+		RetryMaxAttempts:     req.RetryMaxAttempts,
+		RetryInitialDelaySec: req.RetryInitialDelaySec,
+		RetryMultiplier:      req.RetryMultiplier,
+		RetryOnStatuses:      retryOn,
+		RequeueOnInterrupt:   req.RequeueOnInterrupt,
+		MisfirePolicy:        misfirePolicy,
+		MaxLatenessSec:       req.MaxLatenessSec,
+		TimeZone:             req.TimeZone,
+		BlackoutWindowIDs:    service.FormatWindowIDs(req.BlackoutWindowIDs),
 	}
+	retention.ApplyToJob(&newJob)
 
 	// 4. Panggil Service untuk Dispatch Job
 	if err := h.BackupSvc.CreateJobAndDispatch(&newJob); err != nil {
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/repository"
+	"gbackup-new/backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BlackoutRequestDTO: Input create/update blackout window
+type BlackoutRequestDTO struct {
+	Name      string   `json:"name"`
+	Days      []string `json:"days"`       // ["MON","TUE",...]
+	StartTime string   `json:"start_time"` // HH:MM
+	EndTime   string   `json:"end_time"`   // HH:MM (<= start_time = melewati tengah malam)
+	TimeZone  string   `json:"time_zone"`  // Kosong = zona waktu job
+	IsActive  *bool    `json:"is_active"`
+}
+
+// BlackoutHandler mengelola blackout window yang bisa dipakai ulang oleh banyak job
+type BlackoutHandler struct {
+	BlackoutRepo repository.BlackoutRepository
+}
+
+func NewBlackoutHandler(blRepo repository.BlackoutRepository) *BlackoutHandler {
+	return &BlackoutHandler{BlackoutRepo: blRepo}
+}
+
+func blackoutResponse(w models.BlackoutWindow) map[string]interface{} {
+	days := []string{}
+	if w.Days != "" {
+		days = strings.Split(w.Days, ",")
+	}
+	return map[string]interface{}{
+		"id":         w.ID,
+		"name":       w.Name,
+		"days":       days,
+		"start_time": w.StartTime,
+		"end_time":   w.EndTime,
+		"time_zone":  w.TimeZone,
+		"is_active":  w.IsActive,
+	}
+}
+
+// applyBlackoutRequest: Isi model dari request lalu validasi
+func applyBlackoutRequest(w *models.BlackoutWindow, req BlackoutRequestDTO) error {
+	w.Name = req.Name
+	w.Days = strings.Join(req.Days, ",")
+	w.StartTime = req.StartTime
+	w.EndTime = req.EndTime
+	w.TimeZone = req.TimeZone
+	if req.IsActive != nil {
+		w.IsActive = *req.IsActive
+	}
+	return service.ValidateBlackoutWindow(w)
+}
+
+// ============================================================
+// ListBlackouts: GET /api/v1/blackouts
+// ============================================================
+func (h *BlackoutHandler) ListBlackouts(c echo.Context) error {
+	windows, err := h.BlackoutRepo.FindAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengambil blackout window: " + err.Error(),
+		})
+	}
+
+	data := make([]map[string]interface{}, 0, len(windows))
+	for _, w := range windows {
+		data = append(data, blackoutResponse(w))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// ============================================================
+// CreateBlackout: POST /api/v1/blackouts
+// ============================================================
+func (h *BlackoutHandler) CreateBlackout(c echo.Context) error {
+	var req BlackoutRequestDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	window := models.BlackoutWindow{IsActive: true}
+	if err := applyBlackoutRequest(&window, req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := h.BlackoutRepo.Create(&window); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    blackoutResponse(window),
+	})
+}
+
+// ============================================================
+// UpdateBlackout: PUT /api/v1/blackouts/:id
+// ============================================================
+func (h *BlackoutHandler) UpdateBlackout(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Blackout window ID tidak valid",
+		})
+	}
+
+	window, err := h.BlackoutRepo.FindByID(uint(id))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	var req BlackoutRequestDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	if err := applyBlackoutRequest(window, req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := h.BlackoutRepo.Save(window); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    blackoutResponse(*window),
+	})
+}
+
+// ============================================================
+// DeleteBlackout: DELETE /api/v1/blackouts/:id
+// ============================================================
+func (h *BlackoutHandler) DeleteBlackout(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Blackout window ID tidak valid",
+		})
+	}
+
+	if err := h.BlackoutRepo.Delete(uint(id)); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Blackout window berhasil dihapus",
+	})
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/repository"
+	"gbackup-new/backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultCatalogSearchLimit: Jumlah hasil pencarian catalog jika limit tidak diisi
+const defaultCatalogSearchLimit = 100
+
+// CatalogHandler mengelola pencarian file di manifest snapshot
+type CatalogHandler struct {
+	CatalogRepo repository.CatalogRepository
+	SnapRepo    repository.SnapshotRepository
+}
+
+func NewCatalogHandler(cRepo repository.CatalogRepository, sRepo repository.SnapshotRepository) *CatalogHandler {
+	return &CatalogHandler{CatalogRepo: cRepo, SnapRepo: sRepo}
+}
+
+func catalogEntryResponse(e models.CatalogEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"path":        e.Path,
+		"source_path": e.SourcePath,
+		"size_bytes":  e.SizeBytes,
+		"mod_time":    e.ModTime,
+		"hash_type":   e.HashType,
+		"hash":        e.Hash,
+	}
+}
+
+// ============================================================
+// SearchCatalog: GET /api/v1/catalog/search?path=&job_id=&from=&to=&limit=
+// ============================================================
+func (h *CatalogHandler) SearchCatalog(c echo.Context) error {
+	filter := repository.CatalogSearchFilter{
+		Path:  strings.TrimSpace(c.QueryParam("path")),
+		Limit: defaultCatalogSearchLimit,
+	}
+	if filter.Path == "" || filter.Path == "/" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "path wajib diisi",
+		})
+	}
+
+	if raw := c.QueryParam("job_id"); raw != "" {
+		jobID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "job_id tidak valid",
+			})
+		}
+		filter.JobID = uint(jobID)
+	}
+	if raw := c.QueryParam("from"); raw != "" {
+		t, err := parseCalendarTime(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "from harus RFC3339 atau YYYY-MM-DD",
+			})
+		}
+		filter.From = &t
+	}
+	if raw := c.QueryParam("to"); raw != "" {
+		t, err := parseCalendarTime(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "to harus RFC3339 atau YYYY-MM-DD",
+			})
+		}
+		filter.To = &t
+	}
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > 1000 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "limit harus antara 1 dan 1000",
+			})
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.CatalogRepo.Search(filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mencari catalog: " + err.Error(),
+		})
+	}
+
+	snapIDs := make([]uint, 0, len(entries))
+	seen := make(map[uint]bool)
+	for _, e := range entries {
+		if !seen[e.SnapshotID] {
+			seen[e.SnapshotID] = true
+			snapIDs = append(snapIDs, e.SnapshotID)
+		}
+	}
+	snapshots, err := h.SnapRepo.FindByIDs(snapIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengambil snapshot: " + err.Error(),
+		})
+	}
+	byID := make(map[uint]models.Snapshot, len(snapshots))
+	for _, snap := range snapshots {
+		byID[snap.ID] = snap
+	}
+
+	now := time.Now()
+	data := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		snap := byID[e.SnapshotID]
+		item := catalogEntryResponse(e)
+		item["snapshot_id"] = e.SnapshotID
+		item["run_id"] = e.RunID
+		item["job_id"] = e.JobID
+		item["snapshot_name"] = snap.Name
+		item["snapshot_at"] = snap.SnapshotAt
+		item["snapshot_status"] = snap.Status
+		item["remote_name"] = snap.RemoteName
+		item["destination_path"] = snap.DestinationPath
+		item["pinned"] = service.IsPinActive(snap, now)
+		data = append(data, item)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"path":      filter.Path,
+		"matches":   data,
+		"truncated": len(entries) >= filter.Limit,
+	})
+}
+
+// ============================================================
+// GetSnapshotManifest: GET /api/v1/snapshots/:id/manifest
+// ============================================================
+func (h *CatalogHandler) GetSnapshotManifest(c echo.Context) error {
+	snapID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Snapshot ID tidak valid",
+		})
+	}
+
+	snap, err := h.SnapRepo.FindByID(uint(snapID))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	entries, err := h.CatalogRepo.FindBySnapshotID(snap.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengambil manifest: " + err.Error(),
+		})
+	}
+
+	files := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		files = append(files, catalogEntryResponse(e))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"snapshot": snapshotResponse(*snap),
+		"files":    files,
+	})
+}
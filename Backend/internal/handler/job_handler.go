@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"gbackup-new/backend/internal/models"
 	"gbackup-new/backend/internal/repository"
 	"gbackup-new/backend/internal/service"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -72,6 +74,24 @@ func (h *JobHandler) GetJobByID(c echo.Context) error {
 			"last_run":         job.LastRun,
 			"pre_script":       job.PreScript,
 			"post_script":      job.PostScript,
+
+			"pre_script_timeout_sec":  job.PreScriptTimeoutSec,
+			"transfer_timeout_sec":    job.TransferTimeoutSec,
+			"post_script_timeout_sec": job.PostScriptTimeoutSec,
+
+			"retry_max_attempts":      job.RetryMaxAttempts,
+			"retry_initial_delay_sec": job.RetryInitialDelaySec,
+			"retry_multiplier":        job.RetryMultiplier,
+			"retry_on":                service.ParseRetryStatuses(job.RetryOnStatuses),
+			"requeue_on_interrupt":    job.RequeueOnInterrupt,
+			"misfire_policy":          job.MisfirePolicy,
+			"max_lateness_sec":        job.MaxLatenessSec,
+			"time_zone":               job.TimeZone,
+			"blackout_window_ids":     service.ParseWindowIDs(job.BlackoutWindowIDs),
+			"retention":               service.JobRetentionRules(*job),
+			"is_active":               job.IsActive,
+			"paused_at":               job.PausedAt,
+			"resume_at":               job.ResumeAt,
 		},
 	})
 }
@@ -90,6 +110,58 @@ func (h *JobHandler) GetScheduledJobs(c echo.Context) error {
 	return c.JSON(http.StatusOK, jobsDTO)
 }
 
+// defaultCalendarRange: Rentang kalender jika query to tidak dikirim
+const defaultCalendarRange = 7 * 24 * time.Hour
+
+// parseCalendarTime: Terima RFC3339 atau tanggal YYYY-MM-DD (zona waktu server)
+func parseCalendarTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", raw, time.Local)
+}
+
+// ============================================================
+// GetScheduleCalendar: GET /api/v1/schedule/calendar?from=&to=
+// ============================================================
+func (h *JobHandler) GetScheduleCalendar(c echo.Context) error {
+	from := time.Now().Truncate(time.Minute)
+	if raw := c.QueryParam("from"); raw != "" {
+		t, err := parseCalendarTime(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "from harus RFC3339 atau YYYY-MM-DD",
+			})
+		}
+		from = t
+	}
+
+	to := from.Add(defaultCalendarRange)
+	if raw := c.QueryParam("to"); raw != "" {
+		t, err := parseCalendarTime(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "to harus RFC3339 atau YYYY-MM-DD",
+			})
+		}
+		to = t
+	}
+
+	calendar, err := h.SchedulerSvc.GetScheduleCalendar(from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCalendarRange) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal menyusun kalender jadwal: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, calendar)
+}
+
 // ============================================================
 // GetManualJob: GET /api/v1/jobs/manual
 // ============================================================
@@ -142,6 +214,11 @@ func (h *JobHandler) TriggerManualJob(c echo.Context) error {
 	}
 
 	if err := h.BackupSvc.TriggerManualJob(uint(jobID)); err != nil {
+		if errors.Is(err, service.ErrJobAlreadyQueued) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": fmt.Sprintf("Job %d sudah menunggu di antrean", jobID),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("Gagal memicu Job: %v", err),
 		})
@@ -151,11 +228,194 @@ func (h *JobHandler) TriggerManualJob(c echo.Context) error {
 
 	return c.JSON(http.StatusAccepted, map[string]interface{}{
 		"success": true,
-		"message": "Job berhasil dipicu",
+		"message": "Job berhasil dipicu dan masuk antrean",
 		"job_id":  jobID,
 	})
 }
 
+// ============================================================
+// CancelJob: POST /api/v1/jobs/:id/cancel
+// Membatalkan run yang berjalan sekaligus entry job yang masih menunggu di antrean
+// ============================================================
+func (h *JobHandler) CancelJob(c echo.Context) error {
+	jobIDStr := c.Param("id")
+	jobID, err := strconv.ParseUint(jobIDStr, 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	if err := h.BackupSvc.CancelJob(uint(jobID)); err != nil {
+		if errors.Is(err, service.ErrRunNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": fmt.Sprintf("Job %d tidak sedang berjalan maupun menunggu di antrean", jobID),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("Gagal membatalkan Job: %v", err),
+		})
+	}
+
+	fmt.Printf("[HANDLER] Job cancel requested - ID: %d\n", jobID)
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"success": true,
+		"message": "Permintaan pembatalan Job dikirim",
+		"job_id":  jobID,
+	})
+}
+
+// ============================================================
+// PauseJob: POST /api/v1/jobs/:id/pause
+// Body opsional: {"resume_at": "2026-01-02T15:04:05+07:00"}
+// ============================================================
+func (h *JobHandler) PauseJob(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	var req struct {
+		ResumeAt *time.Time `json:"resume_at"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format (resume_at harus RFC3339)",
+		})
+	}
+	if req.ResumeAt != nil && !req.ResumeAt.After(time.Now()) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "resume_at harus di masa depan",
+		})
+	}
+
+	if err := h.BackupSvc.PauseJob(uint(jobID), req.ResumeAt); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"message":   "Job di-pause",
+		"job_id":    jobID,
+		"resume_at": req.ResumeAt,
+	})
+}
+
+// ============================================================
+// ResumeJob: POST /api/v1/jobs/:id/resume
+// ============================================================
+func (h *JobHandler) ResumeJob(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	if err := h.BackupSvc.ResumeJob(uint(jobID)); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Job di-resume",
+		"job_id":  jobID,
+	})
+}
+
+// ============================================================
+// GetRetentionPreview: GET /api/v1/jobs/:id/retention/preview
+// ============================================================
+func (h *JobHandler) GetRetentionPreview(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	if _, err := h.JobRepo.FindJobByID(uint(jobID)); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	preview, err := h.BackupSvc.PreviewRetention(uint(jobID))
+	if err != nil {
+		if errors.Is(err, service.ErrRetentionNotApplicable) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal menyusun preview retensi: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+// ============================================================
+// ClaimLegacySnapshots: POST /api/v1/jobs/:id/snapshots/claim
+// Body: {"names": ["db_20250101_020000"], "force": false}
+// Backup lama tidak pernah diadopsi otomatis; force melewati cek job lain dengan pola nama yang sama.
+// ============================================================
+func (h *JobHandler) ClaimLegacySnapshots(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	var req struct {
+		Names []string `json:"names"`
+		Force bool     `json:"force"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	if _, err := h.JobRepo.FindJobByID(uint(jobID)); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	claimed, err := h.BackupSvc.ClaimLegacySnapshots(uint(jobID), req.Names, req.Force)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRetentionNotApplicable), errors.Is(err, service.ErrInvalidClaim):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrClaimAmbiguous):
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengklaim backup lama: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"message":   fmt.Sprintf("%d backup lama diklaim", len(claimed)),
+		"job_id":    jobID,
+		"snapshots": claimed,
+	})
+}
+
 // ============================================================
 // DeleteJob: DELETE /api/v1/jobs/:id
 // ============================================================
@@ -212,6 +472,23 @@ func (h *JobHandler) UpdateJob(c echo.Context) error {
 		PostScript      *string `json:"post_script"`
 		MaxRetention    *int    `json:"max_retention"` // ⭐ NEW: dapat di-update
 		IsActive        *bool   `json:"is_active"`
+
+		PreScriptTimeoutSec  *int `json:"pre_script_timeout_sec"`
+		TransferTimeoutSec   *int `json:"transfer_timeout_sec"`
+		PostScriptTimeoutSec *int `json:"post_script_timeout_sec"`
+
+		RetryMaxAttempts     *int      `json:"retry_max_attempts"`
+		RetryInitialDelaySec *int      `json:"retry_initial_delay_sec"`
+		RetryMultiplier      *float64  `json:"retry_multiplier"`
+		RetryOn              *[]string `json:"retry_on"`
+
+		RequeueOnInterrupt *bool   `json:"requeue_on_interrupt"`
+		MisfirePolicy      *string `json:"misfire_policy"`
+		MaxLatenessSec     *int    `json:"max_lateness_sec"`
+		TimeZone           *string `json:"time_zone"`
+		BlackoutWindowIDs  *[]uint `json:"blackout_window_ids"`
+
+		Retention *RetentionRequestDTO `json:"retention"`
 	}
 
 	if err := c.Bind(&req); err != nil {
@@ -265,9 +542,108 @@ func (h *JobHandler) UpdateJob(c echo.Context) error {
 		}
 	}
 
+	// ✅ Timeout: field yang tidak dikirim mempertahankan nilai lama
+	current, err := h.JobRepo.FindJobByID(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	preTimeout, transferTimeout, postTimeout := current.PreScriptTimeoutSec, current.TransferTimeoutSec, current.PostScriptTimeoutSec
+	if req.PreScriptTimeoutSec != nil {
+		preTimeout = *req.PreScriptTimeoutSec
+	}
+	if req.TransferTimeoutSec != nil {
+		transferTimeout = *req.TransferTimeoutSec
+	}
+	if req.PostScriptTimeoutSec != nil {
+		postTimeout = *req.PostScriptTimeoutSec
+	}
+	if err := validatePhaseTimeouts(preTimeout, transferTimeout, postTimeout); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	// ✅ Retry: field yang tidak dikirim mempertahankan nilai lama
+	retryAttempts, retryDelay, retryMultiplier := current.RetryMaxAttempts, current.RetryInitialDelaySec, current.RetryMultiplier
+	retryOnList := service.ParseRetryStatuses(current.RetryOnStatuses)
+	if req.RetryMaxAttempts != nil {
+		retryAttempts = *req.RetryMaxAttempts
+	}
+	if req.RetryInitialDelaySec != nil {
+		retryDelay = *req.RetryInitialDelaySec
+	}
+	if req.RetryMultiplier != nil {
+		retryMultiplier = *req.RetryMultiplier
+	}
+	if req.RetryOn != nil {
+		retryOnList = *req.RetryOn
+	}
+	retryOn, err := validateRetryPolicy(retryAttempts, retryDelay, retryMultiplier, retryOnList)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	requeueOnInterrupt := current.RequeueOnInterrupt
+	if req.RequeueOnInterrupt != nil {
+		requeueOnInterrupt = *req.RequeueOnInterrupt
+	}
+
+	misfirePolicy, maxLateness := current.MisfirePolicy, current.MaxLatenessSec
+	if req.MisfirePolicy != nil {
+		misfirePolicy = *req.MisfirePolicy
+	}
+	if req.MaxLatenessSec != nil {
+		maxLateness = *req.MaxLatenessSec
+	}
+	misfirePolicy, err = validateMisfirePolicy(misfirePolicy, maxLateness)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	timeZone, blackoutIDs := current.TimeZone, current.BlackoutWindowIDs
+	if req.TimeZone != nil {
+		timeZone = *req.TimeZone
+	}
+	if err := service.ValidateTimeZone(timeZone); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	if req.BlackoutWindowIDs != nil {
+		blackoutIDs = service.FormatWindowIDs(*req.BlackoutWindowIDs)
+	}
+
+	retention, err := resolveRetention(req.Retention, service.JobRetentionRules(*current))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
 	// ⭐ HIGHLIGHT 5: BUILD UPDATED JOB
 	// ✅ Hanya set field yang dikirim (pointer pattern)
-	updated := &models.ScheduledJob{}
+	updated := &models.ScheduledJob{
+		PreScriptTimeoutSec:  preTimeout,
+		TransferTimeoutSec:   transferTimeout,
+		PostScriptTimeoutSec: postTimeout,
+
+		RetryMaxAttempts:     retryAttempts,
+		RetryInitialDelaySec: retryDelay,
+		RetryMultiplier:      retryMultiplier,
+		RetryOnStatuses:      retryOn,
+		RequeueOnInterrupt:   requeueOnInterrupt,
+		MisfirePolicy:        misfirePolicy,
+		MaxLatenessSec:       maxLateness,
+		TimeZone:             timeZone,
+		BlackoutWindowIDs:    blackoutIDs,
+	}
+	retention.ApplyToJob(updated)
 
 	if req.JobName != nil {
 		updated.JobName = *req.JobName
@@ -288,6 +664,11 @@ func (h *JobHandler) UpdateJob(c echo.Context) error {
 		updated.RemoteName = *req.RemoteName
 	}
 	if req.ScheduleCron != nil {
+		if err := service.ValidateCronExpression(*req.ScheduleCron); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		updated.ScheduleCron = *req.ScheduleCron
 	}
 	if req.PreScript != nil {
@@ -308,6 +689,20 @@ func (h *JobHandler) UpdateJob(c echo.Context) error {
 		})
 	}
 
+	// ✅ is_active: pause/resume lewat service agar jadwal ikut diperbarui
+	if req.IsActive != nil && *req.IsActive != current.IsActive {
+		if *req.IsActive {
+			err = h.BackupSvc.ResumeJob(id)
+		} else {
+			err = h.BackupSvc.PauseJob(id, nil)
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	fmt.Printf("[HANDLER] Job updated successfully - ID: %d\n", id)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
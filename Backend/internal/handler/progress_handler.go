@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gbackup-new/backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sseHeartbeatInterval: Komentar SSE periodik agar koneksi tidak diputus proxy
+const sseHeartbeatInterval = 15 * time.Second
+
+// ProgressHandler menyajikan progress job yang sedang berjalan
+type ProgressHandler struct {
+	Progress *service.ProgressBus
+}
+
+func NewProgressHandler(progress *service.ProgressBus) *ProgressHandler {
+	return &ProgressHandler{Progress: progress}
+}
+
+// ============================================================
+// GetProgress: GET /api/v1/jobs/:id/progress (snapshot untuk polling)
+// ============================================================
+func (h *ProgressHandler) GetProgress(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	ev, ok := h.Progress.Snapshot(uint(jobID))
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": fmt.Sprintf("Belum ada progress untuk Job %d", jobID),
+		})
+	}
+
+	return c.JSON(http.StatusOK, ev)
+}
+
+// ============================================================
+// StreamProgress: GET /api/v1/jobs/:id/progress/stream (Server-Sent Events)
+// ============================================================
+func (h *ProgressHandler) StreamProgress(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	events, unsubscribe := h.Progress.Subscribe(uint(jobID))
+	defer unsubscribe()
+
+	snapshot, ok := h.Progress.Snapshot(uint(jobID))
+	return streamSSE(c, events, snapshot, ok)
+}
+
+// ============================================================
+// GetRestoreProgress: GET /api/v1/restore/records/:id/progress
+// ============================================================
+func (h *ProgressHandler) GetRestoreProgress(c echo.Context) error {
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Restore ID tidak valid",
+		})
+	}
+
+	ev, ok := h.Progress.SnapshotRestore(uint(recordID))
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": fmt.Sprintf("Tidak ada progress aktif untuk Restore #%d (hasil akhir ada di record restore)", recordID),
+		})
+	}
+
+	return c.JSON(http.StatusOK, ev)
+}
+
+// ============================================================
+// StreamRestoreProgress: GET /api/v1/restore/records/:id/progress/stream (Server-Sent Events)
+// ============================================================
+func (h *ProgressHandler) StreamRestoreProgress(c echo.Context) error {
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Restore ID tidak valid",
+		})
+	}
+
+	events, unsubscribe := h.Progress.SubscribeRestore(uint(recordID))
+	defer unsubscribe()
+
+	snapshot, ok := h.Progress.SnapshotRestore(uint(recordID))
+	return streamSSE(c, events, snapshot, ok)
+}
+
+// streamSSE: Mengirim snapshot terakhir (jika ada) lalu setiap event baru, sampai event DONE terkirim
+// atau client memutus koneksi
+func streamSSE(c echo.Context, events <-chan service.ProgressEvent, snapshot service.ProgressEvent, hasSnapshot bool) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	// Kirim snapshot terakhir dulu agar client langsung punya state
+	if hasSnapshot {
+		if err := writeSSE(res, "progress", snapshot); err != nil || snapshot.Phase == service.PhaseDone {
+			return nil
+		}
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			if err := writeSSE(res, "progress", ev); err != nil || ev.Phase == service.PhaseDone {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// writeSSE: Menulis satu event SSE (event + data JSON) lalu flush
+func writeSSE(res *echo.Response, event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"gbackup-new/backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// QueueHandler menampilkan isi antrean dispatcher
+type QueueHandler struct {
+	Dispatcher service.JobDispatcher
+}
+
+func NewQueueHandler(dispatcher service.JobDispatcher) *QueueHandler {
+	return &QueueHandler{Dispatcher: dispatcher}
+}
+
+// ============================================================
+// GetQueue: GET /api/v1/queue
+// ============================================================
+func (h *QueueHandler) GetQueue(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.Dispatcher.GetQueueStatus())
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"gbackup-new/backend/internal/models"
 	"gbackup-new/backend/internal/service"
 	"net/http"
+	"strconv"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
@@ -13,14 +16,146 @@ type RestoreRequestDTO struct {
 	SourcePath      string `json:"source_path" validate:"required"`
 	RemoteName      string `json:"remote_name" validate:"required"`
 	DestinationPath string `json:"destination_path" validate:"required"`
+	ConflictPolicy  string `json:"conflict_policy"`
+	SafetySnapshot  bool   `json:"safety_snapshot"`
+}
+
+// SnapshotRestoreRequestDTO: Input restore point-in-time (snapshot_id atau as_of)
+type SnapshotRestoreRequestDTO struct {
+	SnapshotID uint   `json:"snapshot_id"`
+	AsOf       string `json:"as_of"`       // mis. "2026-10-01 03:00" (zona waktu job) atau RFC3339
+	TargetPath string `json:"target_path"` // Kosong = SourcePath asli
+	// Paths: File/folder yang dipulihkan (relatif, path remote dari browser, atau source_path dari catalog)
+	Paths   []string `json:"paths"`
+	Include []string `json:"include"` // Pola filter rclone, mis. "*.conf"
+	// ConflictPolicy: overwrite (default) | skip-existing | keep-newer | rename-restored
+	ConflictPolicy string `json:"conflict_policy"`
+	// SafetySnapshot: Pindahkan file yang akan ditimpa ke folder quarantine sebelum ditimpa
+	SafetySnapshot bool `json:"safety_snapshot"`
+}
+
+// RestorePreviewRequestDTO: Input POST /api/v1/restore/preview (rencana sama dengan restore snapshot)
+type RestorePreviewRequestDTO struct {
+	JobID uint `json:"job_id"`
+	SnapshotRestoreRequestDTO
 }
 
 type RestoreHandler struct {
-	BackupSvc service.BackupService
+	BackupSvc  service.BackupService
+	RestoreSvc service.RestoreService
+}
+
+func NewRestoreHandler(svc service.BackupService, rSvc service.RestoreService) *RestoreHandler {
+	return &RestoreHandler{BackupSvc: svc, RestoreSvc: rSvc}
+}
+
+// restorePreviewResponse: Preview beserta rencana dan daftar file per kategori
+func restorePreviewResponse(p *models.RestorePreview) map[string]interface{} {
+	paths, include := service.PreviewPaths(p)
+	return map[string]interface{}{
+		"id":              p.ID,
+		"job_id":          p.JobID,
+		"snapshot_id":     p.SnapshotID,
+		"remote_path":     p.RemotePath,
+		"target_path":     p.TargetPath,
+		"in_place":        p.InPlace,
+		"paths":           paths,
+		"include":         include,
+		"conflict_policy": p.ConflictPolicy,
+		"safety_snapshot": p.SafetySnapshot,
+		"counts": map[string]int{
+			"added":     p.AddedCount,
+			"modified":  p.ModifiedCount,
+			"deleted":   p.DeletedCount,
+			"identical": p.IdenticalCount,
+			"errors":    p.ErrorCount,
+		},
+		"files":       service.LoadPreviewFiles(p),
+		"truncated":   p.Truncated,
+		"status":      p.Status,
+		"expires_at":  p.ExpiresAt,
+		"executed_at": p.ExecutedAt,
+		"created_at":  p.CreatedAt,
+	}
+}
+
+// restoreRequester: User peminta restore dari token JWT (claim user_id & username)
+func restoreRequester(c echo.Context) service.RestoreRequester {
+	var requester service.RestoreRequester
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return requester
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return requester
+	}
+	if userID, ok := claims["user_id"].(float64); ok && userID > 0 {
+		requester.UserID = uint(userID)
+	}
+	if username, ok := claims["username"].(string); ok {
+		requester.Username = username
+	}
+	return requester
 }
 
-func NewRestoreHandler(svc service.BackupService) *RestoreHandler {
-	return &RestoreHandler{BackupSvc: svc}
+// restoreRecordResponse: Record restore beserta run dan hasil per file (restore selektif)
+func restoreRecordResponse(r *models.RestoreRecord, run *models.JobRun) map[string]interface{} {
+	paths, include := service.RecordPaths(r)
+	data := map[string]interface{}{
+		"id":                r.ID,
+		"requested_by":      r.RequestedBy,
+		"requested_by_id":   r.RequestedByID,
+		"source":            r.Source,
+		"job_id":            r.JobID,
+		"snapshot_id":       r.SnapshotID,
+		"preview_id":        r.PreviewID,
+		"run_id":            r.RunID,
+		"remote_name":       r.RemoteName,
+		"source_path":       r.SourcePath,
+		"destination_path":  r.DestinationPath,
+		"in_place":          r.InPlace,
+		"paths":             paths,
+		"include":           include,
+		"conflict_policy":   r.ConflictPolicy,
+		"quarantine_path":   r.QuarantinePath,
+		"status":            r.Status,
+		"transferred_bytes": r.TransferredBytes,
+		"transferred_files": r.TransferredFiles,
+		"error_message":     r.ErrorMessage,
+		"started_at":        r.StartedAt,
+		"finished_at":       r.FinishedAt,
+		"created_at":        r.CreatedAt,
+	}
+	if run != nil {
+		data["run_id"] = run.ID
+		data["run"] = map[string]interface{}{
+			"id":           run.ID,
+			"job_name":     run.JobName,
+			"status":       run.Status,
+			"started_at":   run.StartedAt,
+			"finished_at":  run.FinishedAt,
+			"duration_sec": run.DurationSec,
+		}
+		if report := service.LoadRestoreReport(run); report != nil {
+			data["results"] = report
+		}
+	}
+	return data
+}
+
+// restoreErrorStatus: 400 untuk input tidak valid, 404 snapshot tidak ada, 409 snapshot tidak bisa dipakai
+func restoreErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRestore):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrSnapshotNotFound), errors.Is(err, service.ErrPreviewNotFound),
+		errors.Is(err, service.ErrRestoreRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSnapshotNotRestorable), errors.Is(err, service.ErrPreviewNotExecutable):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
 }
 
 func (h *RestoreHandler) TriggerRestore(c echo.Context) error {
@@ -32,30 +167,272 @@ func (h *RestoreHandler) TriggerRestore(c echo.Context) error {
 		})
 	}
 
-	userID := uint(1)
-
-	restoreJob := &models.ScheduledJob{
-		UserID:          userID,
-		JobName:         fmt.Sprintf("Restore-%s", req.RemoteName),
-		OperationMode:   "RESTORE",
-		RcloneMode:      "copy",
-		SourcePath:      req.SourcePath,
+	record, err := h.RestoreSvc.StartPathRestore(service.PathRestoreRequest{
 		RemoteName:      req.RemoteName,
+		SourcePath:      req.SourcePath,
 		DestinationPath: req.DestinationPath,
-		ScheduleCron:    "",
-		StatusQueue:     "PENDING",
-	}
-
-	if err := h.BackupSvc.CreateJobAndDispatch(restoreJob); err != nil {
+		ConflictPolicy:  req.ConflictPolicy,
+		SafetySnapshot:  req.SafetySnapshot,
+		RequestedBy:     restoreRequester(c),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRestore) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("Gagal memulai Restore: %v", err.Error()),
 		})
 	}
 
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message":         "Restore job dimulai.",
+		"restore_id":      record.ID,
+		"status":          record.Status,
+		"source":          req.SourcePath,
+		"remote":          req.RemoteName,
+		"destination":     req.DestinationPath,
+		"conflict_policy": record.ConflictPolicy,
+		"quarantine_path": record.QuarantinePath,
+	})
+}
+
+// ============================================================
+// TriggerSnapshotRestore: POST /api/v1/jobs/:id/restore
+// ============================================================
+func (h *RestoreHandler) TriggerSnapshotRestore(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	var req SnapshotRestoreRequestDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Format input JSON tidak valid.",
+		})
+	}
+	if req.SnapshotID != 0 && req.AsOf != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Isi salah satu: snapshot_id atau as_of",
+		})
+	}
+
+	plan, err := h.RestoreSvc.StartPointInTimeRestore(service.PointInTimeRestoreRequest{
+		JobID:      uint(jobID),
+		SnapshotID: req.SnapshotID,
+		AsOf:       req.AsOf,
+		TargetPath: req.TargetPath,
+		Paths:      req.Paths,
+		Include:    req.Include,
+
+		ConflictPolicy: req.ConflictPolicy,
+		SafetySnapshot: req.SafetySnapshot,
+		RequestedBy:    restoreRequester(c),
+	})
+	if err != nil {
+		return c.JSON(restoreErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
 	return c.JSON(http.StatusAccepted, map[string]interface{}{
 		"message":     "Restore job dimulai.",
-		"source":      req.SourcePath,
-		"remote":      req.RemoteName,
-		"destination": req.DestinationPath,
+		"restore_id":  plan.Record.ID,
+		"status":      plan.Record.Status,
+		"job_id":      plan.Job.ID,
+		"snapshot":    snapshotResponse(plan.Snapshot),
+		"resolved_by": plan.ResolvedBy,
+		"source":      plan.RemotePath,
+		"remote":      plan.Snapshot.RemoteName,
+		"destination": plan.TargetPath,
+		"in_place":    plan.InPlace,
+		"paths":       plan.Paths,
+		"include":     plan.Include,
+
+		"conflict_policy": plan.ConflictPolicy,
+		"quarantine_path": plan.QuarantinePath,
+	})
+}
+
+// ============================================================
+// PreviewRestore: POST /api/v1/restore/preview
+// ============================================================
+func (h *RestoreHandler) PreviewRestore(c echo.Context) error {
+	var req RestorePreviewRequestDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Format input JSON tidak valid.",
+		})
+	}
+	if req.JobID == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "job_id wajib diisi",
+		})
+	}
+	if req.SnapshotID != 0 && req.AsOf != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Isi salah satu: snapshot_id atau as_of",
+		})
+	}
+
+	preview, err := h.RestoreSvc.PreviewRestore(c.Request().Context(), service.PointInTimeRestoreRequest{
+		JobID:      req.JobID,
+		SnapshotID: req.SnapshotID,
+		AsOf:       req.AsOf,
+		TargetPath: req.TargetPath,
+		Paths:      req.Paths,
+		Include:    req.Include,
+
+		ConflictPolicy: req.ConflictPolicy,
+		SafetySnapshot: req.SafetySnapshot,
+	})
+	if err != nil {
+		return c.JSON(restoreErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, restorePreviewResponse(preview))
+}
+
+// ============================================================
+// GetRestorePreview: GET /api/v1/restore/preview/:id
+// ============================================================
+func (h *RestoreHandler) GetRestorePreview(c echo.Context) error {
+	previewID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Preview ID tidak valid",
+		})
+	}
+
+	preview, err := h.RestoreSvc.GetPreview(uint(previewID))
+	if err != nil {
+		return c.JSON(restoreErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, restorePreviewResponse(preview))
+}
+
+// ============================================================
+// ExecuteRestorePreview: POST /api/v1/restore/preview/:id/execute
+// ============================================================
+func (h *RestoreHandler) ExecuteRestorePreview(c echo.Context) error {
+	previewID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Preview ID tidak valid",
+		})
+	}
+
+	plan, err := h.RestoreSvc.ExecutePreview(uint(previewID), restoreRequester(c))
+	if err != nil {
+		return c.JSON(restoreErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message":         "Restore job dimulai.",
+		"restore_id":      plan.Record.ID,
+		"status":          plan.Record.Status,
+		"preview_id":      previewID,
+		"job_id":          plan.Job.ID,
+		"snapshot":        snapshotResponse(plan.Snapshot),
+		"source":          plan.RemotePath,
+		"remote":          plan.Snapshot.RemoteName,
+		"destination":     plan.TargetPath,
+		"in_place":        plan.InPlace,
+		"paths":           plan.Paths,
+		"include":         plan.Include,
+		"conflict_policy": plan.ConflictPolicy,
+		"quarantine_path": plan.QuarantinePath,
+	})
+}
+
+// ============================================================
+// ListRestores: GET /api/v1/restores?limit=50
+// ============================================================
+func (h *RestoreHandler) ListRestores(c echo.Context) error {
+	limit := 50
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 500 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "limit harus antara 1 dan 500",
+			})
+		}
+	}
+
+	records, err := h.RestoreSvc.ListRestoreRecords(limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengambil riwayat restore: " + err.Error(),
+		})
+	}
+
+	data := make([]map[string]interface{}, 0, len(records))
+	for i := range records {
+		data = append(data, restoreRecordResponse(&records[i], nil))
+	}
+	return c.JSON(http.StatusOK, data)
+}
+
+// ============================================================
+// GetRestore: GET /api/v1/restores/:id
+// ============================================================
+func (h *RestoreHandler) GetRestore(c echo.Context) error {
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Restore ID tidak valid",
+		})
+	}
+
+	record, run, err := h.RestoreSvc.GetRestoreRecord(uint(recordID))
+	if err != nil {
+		return c.JSON(restoreErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, restoreRecordResponse(record, run))
+}
+
+// ============================================================
+// CancelRestore: POST /api/v1/restore/records/:id/cancel
+// ============================================================
+func (h *RestoreHandler) CancelRestore(c echo.Context) error {
+	recordID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Restore ID tidak valid",
+		})
+	}
+
+	if err := h.RestoreSvc.CancelRestore(uint(recordID)); err != nil {
+		if errors.Is(err, service.ErrRunNotFound) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": fmt.Sprintf("Restore #%d tidak sedang berjalan", recordID),
+			})
+		}
+		return c.JSON(restoreErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	fmt.Printf("[HANDLER] Restore cancel requested - ID: %d\n", recordID)
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"success":    true,
+		"message":    "Permintaan pembatalan restore dikirim",
+		"restore_id": recordID,
 	})
 }
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"gbackup-new/backend/internal/repository"
+	"gbackup-new/backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultRunHistoryLimit: Jumlah run yang dikembalikan jika query limit tidak dikirim
+const defaultRunHistoryLimit = 50
+
+// RunHandler menyajikan riwayat eksekusi (JobRun)
+type RunHandler struct {
+	RunRepo repository.JobRunRepository
+}
+
+func NewRunHandler(rRepo repository.JobRunRepository) *RunHandler {
+	return &RunHandler{RunRepo: rRepo}
+}
+
+// ============================================================
+// GetJobRuns: GET /api/v1/jobs/:id/runs?limit=50
+// ============================================================
+func (h *RunHandler) GetJobRuns(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	limit := defaultRunHistoryLimit
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 500 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "limit harus antara 1 dan 500",
+			})
+		}
+	}
+
+	runs, err := h.RunRepo.FindByJobID(uint(jobID), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengambil riwayat run: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, runs)
+}
+
+// ============================================================
+// GetRunByID: GET /api/v1/runs/:runId
+// ============================================================
+func (h *RunHandler) GetRunByID(c echo.Context) error {
+	runID, err := strconv.ParseUint(c.Param("runId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Run ID tidak valid",
+		})
+	}
+
+	run, err := h.RunRepo.FindByID(uint(runID))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, run)
+}
+
+// ============================================================
+// GetRestoreResults: GET /api/v1/runs/:runId/restore-results
+// ============================================================
+func (h *RunHandler) GetRestoreResults(c echo.Context) error {
+	runID, err := strconv.ParseUint(c.Param("runId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Run ID tidak valid",
+		})
+	}
+
+	run, err := h.RunRepo.FindByID(uint(runID))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	report := service.LoadRestoreReport(run)
+	if report == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Run ini tidak memiliki hasil restore per file",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"run_id":  run.ID,
+		"status":  run.Status,
+		"results": report,
+	})
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/repository"
+	"gbackup-new/backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PinSnapshotRequestDTO: Input pin snapshot
+type PinSnapshotRequestDTO struct {
+	Reason    string `json:"reason"`
+	ExpiresAt string `json:"expires_at"` // RFC3339, kosong = pin tanpa batas waktu
+}
+
+// SnapshotHandler mengelola katalog snapshot per job (daftar & pin)
+type SnapshotHandler struct {
+	SnapRepo repository.SnapshotRepository
+}
+
+func NewSnapshotHandler(sRepo repository.SnapshotRepository) *SnapshotHandler {
+	return &SnapshotHandler{SnapRepo: sRepo}
+}
+
+func snapshotResponse(snap models.Snapshot) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               snap.ID,
+		"job_id":           snap.JobID,
+		"run_id":           snap.RunID,
+		"remote_name":      snap.RemoteName,
+		"destination_path": snap.DestinationPath,
+		"name":             snap.Name,
+		"is_dir":           snap.IsDir,
+		"size_bytes":       snap.SizeBytes,
+		"status":           snap.Status,
+		"snapshot_at":      snap.SnapshotAt,
+		"verified_at":      snap.VerifiedAt,
+		"pruned_at":        snap.PrunedAt,
+		"pinned":           service.IsPinActive(snap, time.Now()),
+		"pin_reason":       snap.PinReason,
+		"pinned_at":        snap.PinnedAt,
+		"pinned_until":     snap.PinnedUntil,
+		"manifest_files":   snap.ManifestFiles,
+		"cataloged_at":     snap.CatalogedAt,
+	}
+}
+
+// ============================================================
+// GetJobSnapshots: GET /api/v1/jobs/:id/snapshots
+// ============================================================
+func (h *SnapshotHandler) GetJobSnapshots(c echo.Context) error {
+	jobID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Job ID tidak valid",
+		})
+	}
+
+	snapshots, err := h.SnapRepo.FindByJobID(uint(jobID))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengambil snapshot: " + err.Error(),
+		})
+	}
+
+	data := make([]map[string]interface{}, 0, len(snapshots))
+	for _, snap := range snapshots {
+		data = append(data, snapshotResponse(snap))
+	}
+	return c.JSON(http.StatusOK, data)
+}
+
+// ============================================================
+// PinSnapshot: POST /api/v1/snapshots/:id/pin
+// ============================================================
+func (h *SnapshotHandler) PinSnapshot(c echo.Context) error {
+	snapID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Snapshot ID tidak valid",
+		})
+	}
+
+	var req PinSnapshotRequestDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "reason wajib diisi",
+		})
+	}
+	if len(req.Reason) > 255 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "reason maksimal 255 karakter",
+		})
+	}
+
+	var until *time.Time
+	if req.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "expires_at harus format RFC3339",
+			})
+		}
+		if !t.After(time.Now()) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "expires_at harus di masa depan",
+			})
+		}
+		until = &t
+	}
+
+	snap, err := h.SnapRepo.FindByID(uint(snapID))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	if snap.Status == "PRUNED" || snap.Status == "MISSING" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Snapshot berstatus " + snap.Status + " tidak bisa di-pin",
+		})
+	}
+
+	if err := h.SnapRepo.SetPin(snap.ID, true, req.Reason, until); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal pin snapshot: " + err.Error(),
+		})
+	}
+	fmt.Printf("📌 Snapshot %d (%s) di-pin: %s\n", snap.ID, snap.Name, req.Reason)
+
+	snap, err = h.SnapRepo.FindByID(snap.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    snapshotResponse(*snap),
+	})
+}
+
+// ============================================================
+// UnpinSnapshot: POST /api/v1/snapshots/:id/unpin
+// ============================================================
+func (h *SnapshotHandler) UnpinSnapshot(c echo.Context) error {
+	snapID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Snapshot ID tidak valid",
+		})
+	}
+
+	snap, err := h.SnapRepo.FindByID(uint(snapID))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := h.SnapRepo.SetPin(snap.ID, false, "", nil); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal unpin snapshot: " + err.Error(),
+		})
+	}
+	fmt.Printf("📌 Snapshot %d (%s) di-unpin\n", snap.ID, snap.Name)
+
+	snap, err = h.SnapRepo.FindByID(snap.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    snapshotResponse(*snap),
+	})
+}
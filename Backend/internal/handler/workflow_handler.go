@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WorkflowEdgeDTO: Satu edge DAG (to_job_id dijalankan setelah from_job_id)
+type WorkflowEdgeDTO struct {
+	FromJobID uint   `json:"from_job_id"`
+	ToJobID   uint   `json:"to_job_id"`
+	Condition string `json:"condition"` // on_success (default) | on_failure
+}
+
+// WorkflowRequestDTO: Input create/update workflow
+type WorkflowRequestDTO struct {
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	ScheduleCron string            `json:"schedule_cron"` // Kosong = hanya dipicu manual
+	TimeZone     string            `json:"time_zone"`
+	JobIDs       []uint            `json:"job_ids"`
+	Edges        []WorkflowEdgeDTO `json:"edges"`
+}
+
+// WorkflowHandler mengelola workflow (DAG dari job yang sudah ada)
+type WorkflowHandler struct {
+	WorkflowSvc service.WorkflowService
+}
+
+func NewWorkflowHandler(wfSvc service.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{WorkflowSvc: wfSvc}
+}
+
+func workflowResponse(wf models.Workflow) map[string]interface{} {
+	jobIDs := make([]uint, 0, len(wf.Steps))
+	for _, step := range wf.Steps {
+		jobIDs = append(jobIDs, step.JobID)
+	}
+	edges := make([]WorkflowEdgeDTO, 0, len(wf.Edges))
+	for _, e := range wf.Edges {
+		edges = append(edges, WorkflowEdgeDTO{
+			FromJobID: e.FromJobID,
+			ToJobID:   e.ToJobID,
+			Condition: strings.ToLower(e.Condition),
+		})
+	}
+	return map[string]interface{}{
+		"id":            wf.ID,
+		"name":          wf.Name,
+		"description":   wf.Description,
+		"schedule_cron": wf.ScheduleCron,
+		"time_zone":     wf.TimeZone,
+		"job_ids":       jobIDs,
+		"edges":         edges,
+	}
+}
+
+func workflowRunResponse(run models.WorkflowRun) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             run.ID,
+		"workflow_id":    run.WorkflowID,
+		"workflow_name":  run.WorkflowName,
+		"trigger_source": run.TriggerSource,
+		"status":         run.Status,
+		"started_at":     run.StartedAt,
+		"finished_at":    run.FinishedAt,
+		"duration_sec":   run.DurationSec,
+		"message":        run.Message,
+		"steps":          service.LoadStepStates(&run),
+	}
+}
+
+// applyWorkflowRequest: Isi model dari request (validasi DAG dilakukan di service)
+func applyWorkflowRequest(wf *models.Workflow, req WorkflowRequestDTO) {
+	wf.Name = req.Name
+	wf.Description = req.Description
+	wf.ScheduleCron = strings.TrimSpace(req.ScheduleCron)
+	wf.TimeZone = req.TimeZone
+
+	wf.Steps = make([]models.WorkflowStep, 0, len(req.JobIDs))
+	for _, jobID := range req.JobIDs {
+		wf.Steps = append(wf.Steps, models.WorkflowStep{JobID: jobID})
+	}
+	wf.Edges = make([]models.WorkflowEdge, 0, len(req.Edges))
+	for _, e := range req.Edges {
+		condition := strings.ToUpper(strings.TrimSpace(e.Condition))
+		if condition == "" {
+			condition = service.EdgeOnSuccess
+		}
+		wf.Edges = append(wf.Edges, models.WorkflowEdge{
+			FromJobID: e.FromJobID,
+			ToJobID:   e.ToJobID,
+			Condition: condition,
+		})
+	}
+}
+
+// workflowErrorStatus: 400 untuk definisi tidak valid, 500 untuk error lain
+func workflowErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidWorkflow) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ============================================================
+// ListWorkflows: GET /api/v1/workflows
+// ============================================================
+func (h *WorkflowHandler) ListWorkflows(c echo.Context) error {
+	workflows, err := h.WorkflowSvc.ListWorkflows()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengambil workflow: " + err.Error(),
+		})
+	}
+
+	data := make([]map[string]interface{}, 0, len(workflows))
+	for _, wf := range workflows {
+		data = append(data, workflowResponse(wf))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// ============================================================
+// CreateWorkflow: POST /api/v1/workflows
+// ============================================================
+func (h *WorkflowHandler) CreateWorkflow(c echo.Context) error {
+	var req WorkflowRequestDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	var wf models.Workflow
+	applyWorkflowRequest(&wf, req)
+	if err := h.WorkflowSvc.CreateWorkflow(&wf); err != nil {
+		return c.JSON(workflowErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    workflowResponse(wf),
+	})
+}
+
+// ============================================================
+// GetWorkflow: GET /api/v1/workflows/:id
+// ============================================================
+func (h *WorkflowHandler) GetWorkflow(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Workflow ID tidak valid",
+		})
+	}
+
+	wf, err := h.WorkflowSvc.GetWorkflow(uint(id))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    workflowResponse(*wf),
+	})
+}
+
+// ============================================================
+// UpdateWorkflow: PUT /api/v1/workflows/:id
+// ============================================================
+func (h *WorkflowHandler) UpdateWorkflow(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Workflow ID tidak valid",
+		})
+	}
+
+	wf, err := h.WorkflowSvc.GetWorkflow(uint(id))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	var req WorkflowRequestDTO
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	applyWorkflowRequest(wf, req)
+	if err := h.WorkflowSvc.UpdateWorkflow(wf); err != nil {
+		return c.JSON(workflowErrorStatus(err), map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    workflowResponse(*wf),
+	})
+}
+
+// ============================================================
+// DeleteWorkflow: DELETE /api/v1/workflows/:id
+// ============================================================
+func (h *WorkflowHandler) DeleteWorkflow(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Workflow ID tidak valid",
+		})
+	}
+
+	if err := h.WorkflowSvc.DeleteWorkflow(uint(id)); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Workflow berhasil dihapus",
+	})
+}
+
+// ============================================================
+// TriggerWorkflow: POST /api/v1/workflows/:id/trigger
+// ============================================================
+func (h *WorkflowHandler) TriggerWorkflow(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Workflow ID tidak valid",
+		})
+	}
+
+	run, err := h.WorkflowSvc.StartWorkflow(uint(id), service.TriggerManual)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrWorkflowRunning) {
+			status = http.StatusConflict
+		}
+		return c.JSON(status, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"success": true,
+		"message": "Workflow dijalankan",
+		"data":    workflowRunResponse(*run),
+	})
+}
+
+// ============================================================
+// GetWorkflowRuns: GET /api/v1/workflows/:id/runs?limit=50
+// ============================================================
+func (h *WorkflowHandler) GetWorkflowRuns(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Workflow ID tidak valid",
+		})
+	}
+
+	limit := 0
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 500 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "limit harus antara 1 dan 500",
+			})
+		}
+	}
+
+	runs, err := h.WorkflowSvc.GetWorkflowRuns(uint(id), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal mengambil riwayat workflow: " + err.Error(),
+		})
+	}
+
+	data := make([]map[string]interface{}, 0, len(runs))
+	for _, run := range runs {
+		data = append(data, workflowRunResponse(run))
+	}
+	return c.JSON(http.StatusOK, data)
+}
+
+// ============================================================
+// GetWorkflowRun: GET /api/v1/workflow-runs/:runId
+// ============================================================
+func (h *WorkflowHandler) GetWorkflowRun(c echo.Context) error {
+	runID, err := strconv.ParseUint(c.Param("runId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Run ID tidak valid",
+		})
+	}
+
+	run, stepRuns, err := h.WorkflowSvc.GetWorkflowRun(uint(runID))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	data := workflowRunResponse(*run)
+	data["job_runs"] = stepRuns
+	return c.JSON(http.StatusOK, data)
+}
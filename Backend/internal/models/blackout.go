@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// BlackoutWindow merepresentasikan jendela waktu berulang (mis. jam kerja Senin–Jumat 08:00–17:00)
+// di mana scheduler menunda job yang jatuh tempo hingga jendela berakhir. Satu jendela bisa dipakai banyak job.
+type BlackoutWindow struct {
+	ID        uint   `gorm:"primaryKey;type:int unsigned"`
+	Name      string `gorm:"size:100;unique;not null"`
+	Days      string `gorm:"size:50;not null"`                  // Hari mulai jendela, dipisah koma: MON,TUE,...
+	StartTime string `gorm:"column:start_time;size:5;not null"` // HH:MM
+	EndTime   string `gorm:"column:end_time;size:5;not null"`   // HH:MM, <= StartTime = melewati tengah malam
+	TimeZone  string `gorm:"column:time_zone;size:64"`          // Kosong = zona waktu job
+	IsActive  bool   `gorm:"default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// CatalogEntry merepresentasikan satu file di dalam snapshot (manifest per run).
+// Dipakai untuk mencari snapshot mana saja yang berisi sebuah file.
+type CatalogEntry struct {
+	ID         uint   `gorm:"primaryKey;type:int unsigned"`
+	SnapshotID uint   `gorm:"column:snapshot_id;index;not null"`
+	RunID      uint   `gorm:"column:run_id;index"`
+	JobID      uint   `gorm:"column:job_id;index;not null"`
+	Path       string `gorm:"size:1024;not null"` // Relatif terhadap root snapshot di remote
+	SourcePath string `gorm:"size:1024;not null"` // Path asli file di sumber saat backup
+	SizeBytes  int64  `gorm:"default:0"`
+	ModTime    time.Time
+	HashType   string `gorm:"size:32"` // mis. md5, sha1 (kosong jika remote tidak mendukung hash)
+	Hash       string `gorm:"size:128"`
+	CreatedAt  time.Time
+}
@@ -19,10 +19,53 @@ type ScheduledJob struct {
 	PreScript    string `gorm:"column:pre_script;type:text"`
 	PostScript   string `gorm:"column:post_script;type:text"`
 	MaxRetention int    `gorm:"default:10"`
+
+	// Retensi GFS untuk snapshot bertimestamp (semua 0/kosong = pakai max_retention sebagai keep_last)
+	KeepLast    int    `gorm:"column:keep_last;default:0"`
+	KeepHourly  int    `gorm:"column:keep_hourly;default:0"`
+	KeepDaily   int    `gorm:"column:keep_daily;default:0"`
+	KeepWeekly  int    `gorm:"column:keep_weekly;default:0"`
+	KeepMonthly int    `gorm:"column:keep_monthly;default:0"`
+	KeepYearly  int    `gorm:"column:keep_yearly;default:0"`
+	KeepWithin  string `gorm:"column:keep_within;size:32"` // mis. 30d, 1y6m
+	// MinSuccessfulSnapshots: Jumlah minimal snapshot sukses yang selalu dipertahankan retensi
+	MinSuccessfulSnapshots int `gorm:"column:min_successful_snapshots;default:1"`
+	// RetentionDryRun: Keputusan retensi hanya dicatat ke log, tidak ada yang dihapus
+	RetentionDryRun bool `gorm:"column:retention_dry_run;default:false"`
+
+	// Batas waktu per fase dalam detik (0 = tanpa batas)
+	PreScriptTimeoutSec  int `gorm:"column:pre_script_timeout_sec;default:0"`
+	TransferTimeoutSec   int `gorm:"column:transfer_timeout_sec;default:0"`
+	PostScriptTimeoutSec int `gorm:"column:post_script_timeout_sec;default:0"`
+
+	// Retry otomatis: RetryMaxAttempts = total percobaan termasuk run pertama (0/1 = tanpa retry)
+	RetryMaxAttempts     int     `gorm:"column:retry_max_attempts;default:0"`
+	RetryInitialDelaySec int     `gorm:"column:retry_initial_delay_sec;default:60"`
+	RetryMultiplier      float64 `gorm:"column:retry_multiplier;default:2"`
+	RetryOnStatuses      string  `gorm:"column:retry_on_statuses;size:255;default:'FAIL_RCLONE'"` // Dipisah koma
+
+	// RequeueOnInterrupt: Job yang terputus (crash/restart) langsung dimasukkan lagi ke antrean
+	RequeueOnInterrupt bool `gorm:"column:requeue_on_interrupt;default:false"`
+
+	// Jadwal terlewat saat backend mati: run_once, skip, run_all_missed
+	MisfirePolicy   string     `gorm:"column:misfire_policy;type:enum('run_once','skip','run_all_missed');default:'run_once'"`
+	MaxLatenessSec  int        `gorm:"column:max_lateness_sec;default:0"` // 0 = tanpa batas keterlambatan
+	LastScheduledAt *time.Time `gorm:"column:last_scheduled_at;nullable"` // Jadwal cron terakhir yang sudah ditangani
+
+	// Zona waktu IANA untuk schedule_cron (mis. Asia/Jakarta), kosong = zona waktu server
+	TimeZone string `gorm:"column:time_zone;size:64"`
+	// Blackout window yang berlaku untuk job ini (ID dipisah koma)
+	BlackoutWindowIDs string `gorm:"column:blackout_window_ids;size:255"`
+
+	// Pause/resume: job nonaktif tidak dipicu cron (trigger manual tetap bisa)
+	IsActive bool       `gorm:"column:is_active;default:true"`
+	PausedAt *time.Time `gorm:"column:paused_at;nullable"`
+	ResumeAt *time.Time `gorm:"column:resume_at;nullable"` // Resume otomatis, NULL = sampai di-resume manual
+
 	// Penjadwalan dan Status
-	ScheduleCron string     `gorm:"size:50;nullable"` // Boleh NULL
+	ScheduleCron string     `gorm:"size:100;nullable"` // Boleh NULL (detik opsional, @descriptor, CRON_TZ=)
 	Priority     int        `gorm:"default:5"`
-	StatusQueue  string     `gorm:"type:enum('PENDING','RUNNING','COMPLETED','FAIL_PRE_SCRIPT','FAIL_RCLONE','FAIL_POST_SCRIPT','FAIL_SOURCE_CHECK');default:'PENDING'"`
+	StatusQueue  string     `gorm:"type:enum('PENDING','RUNNING','COMPLETED','FAIL_PRE_SCRIPT','FAIL_RCLONE','FAIL_POST_SCRIPT','FAIL_SOURCE_CHECK','FAILED','CANCELLED','TIMEOUT_PRE_SCRIPT','TIMEOUT_RCLONE','TIMEOUT_POST_SCRIPT','INTERRUPTED');default:'PENDING'"`
 	LastRun      *time.Time `gorm:"column:last_run_at;nullable"`
 
 	CreatedAt time.Time
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// JobRun merepresentasikan satu eksekusi Job (backup terjadwal/manual maupun restore one-shot)
+type JobRun struct {
+	ID            uint   `gorm:"primaryKey;type:int unsigned"`
+	JobID         *uint  `gorm:"column:job_id;index"` // NULL untuk restore one-shot
+	JobName       string `gorm:"size:100"`
+	OperationMode string `gorm:"type:enum('BACKUP','RESTORE')"`
+	RemoteName    string `gorm:"size:100"`
+	TriggerSource string `gorm:"type:enum('SCHEDULE','MANUAL','API','RETRY','RECOVERY','WORKFLOW');not null"`
+	Attempt       int    `gorm:"default:1"` // Percobaan ke-N (retry otomatis)
+
+	// Eksekusi workflow yang memicu run ini (NULL jika berdiri sendiri)
+	WorkflowRunID *uint `gorm:"column:workflow_run_id;index"`
+
+	// Record restore yang dilayani run ini (NULL untuk backup)
+	RestoreRecordID *uint `gorm:"column:restore_record_id;index"`
+
+	// Waktu jadwal cron yang diwakili run ini (NULL untuk run manual/API)
+	ScheduledFor *time.Time `gorm:"column:scheduled_for;nullable"`
+
+	// RUNNING selama berjalan, lalu status akhir (SUCCESS, FAIL_*, TIMEOUT_*, CANCELLED, ...).
+	// SKIPPED = jadwal yang tidak dijalankan (misfire/run sebelumnya masih berjalan).
+	Status      string     `gorm:"size:30;index"`
+	StartedAt   time.Time  `gorm:"index"`
+	FinishedAt  *time.Time `gorm:"nullable"`
+	DurationSec int        `gorm:"column:duration_sec;default:0"`
+
+	// Status & durasi per fase (SKIPPED jika fase tidak dijalankan)
+	PreScriptStatus      string `gorm:"size:20"`
+	PreScriptDurationMs  int64  `gorm:"default:0"`
+	TransferStatus       string `gorm:"size:20"`
+	TransferDurationMs   int64  `gorm:"default:0"`
+	PostScriptStatus     string `gorm:"size:20"`
+	PostScriptDurationMs int64  `gorm:"default:0"`
+
+	// Hasil Fase 1.5 & transfer
+	RuntimeDestPath  string `gorm:"size:255"`
+	TransferredBytes int64  `gorm:"default:0"`
+	TransferredFiles int64  `gorm:"default:0"`
+	ErrorMessage     string `gorm:"type:text"`
+
+	// RestoreResults: JSON hasil per file restore selektif (kosong untuk backup & restore penuh)
+	RestoreResults string `gorm:"type:longtext"`
+}
@@ -5,9 +5,11 @@ import "time"
 type Log struct {
 	ID               uint    `gorm:"primaryKey"`
 	JobID            *uint   `gorm:"column:job_id;index"`
+	RunID            *uint   `gorm:"column:run_id;index"`
+	Attempt          int     `gorm:"default:1"`
 	JobName          string  `gorm:"size:100;nullable"` // ✅ BARU: Nama job
 	SourcePath       string  `gorm:"size:255;nullable"`
-	Status           string  `gorm:"type:enum('SUCCESS', 'FAIL_PRE_SCRIPT', 'FAIL_RCLONE', 'FAIL_POST_SCRIPT', 'FAIL_SOURCE_CHECK', 'ERROR')"`
+	Status           string  `gorm:"type:enum('SUCCESS', 'FAIL_PRE_SCRIPT', 'FAIL_RCLONE', 'FAIL_POST_SCRIPT', 'FAIL_SOURCE_CHECK', 'CANCELLED', 'TIMEOUT_PRE_SCRIPT', 'TIMEOUT_RCLONE', 'TIMEOUT_POST_SCRIPT', 'INTERRUPTED', 'NOT_ENOUGH_SPACE', 'ERROR')"`
 	ConfigSnapshot   *string `gorm:"type:json;nullable"`
 	Message          string  `gorm:"type:text"`
 	DurationSec      int     `gorm:"column:duration_sec"`
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// QueueEntry merepresentasikan satu job yang menunggu slot eksekusi di dispatcher.
+// Disimpan di DB agar antrean tidak hilang saat backend restart.
+type QueueEntry struct {
+	ID         uint      `gorm:"primaryKey;type:int unsigned"`
+	JobID      *uint     `gorm:"column:job_id;index"` // NULL untuk restore one-shot
+	JobName    string    `gorm:"size:100"`
+	RemoteName string    `gorm:"size:100;index"`
+	Priority   int       `gorm:"default:5"`
+	DueAt      time.Time `gorm:"column:due_at;index"`
+
+	// JobSnapshot: JSON ScheduledJob saat di-enqueue (dipakai untuk job yang tidak disimpan di DB)
+	JobSnapshot string `gorm:"column:job_snapshot;type:text"`
+
+	// Options: JSON RunOptions (trigger, dsb.) yang diteruskan ke worker
+	Options string `gorm:"column:options;type:text"`
+
+	CreatedAt time.Time
+}
+
+func (QueueEntry) TableName() string {
+	return "job_queue"
+}
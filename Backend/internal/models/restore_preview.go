@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// RestorePreview menyimpan hasil perbandingan snapshot dengan tujuan restore beserta rencananya.
+// Rencana yang sama bisa dijalankan sekali lewat ID preview.
+type RestorePreview struct {
+	ID         uint   `gorm:"primaryKey;type:int unsigned"`
+	JobID      uint   `gorm:"column:job_id;index;not null"`
+	SnapshotID uint   `gorm:"column:snapshot_id;index;not null"`
+	RemotePath string `gorm:"size:512;not null"`  // Path snapshot di remote
+	TargetPath string `gorm:"size:1024;not null"` // Folder tujuan, atau path file untuk snapshot file
+	InPlace    bool   `gorm:"default:false"`
+
+	// Rencana restore (Paths/Include dalam JSON array)
+	Paths          string `gorm:"type:text"`
+	Include        string `gorm:"type:text"`
+	ConflictPolicy string `gorm:"size:20"`
+	SafetySnapshot bool   `gorm:"default:false"`
+
+	// Hasil perbandingan
+	AddedCount     int    `gorm:"default:0"`
+	ModifiedCount  int    `gorm:"default:0"`
+	DeletedCount   int    `gorm:"default:0"`
+	IdenticalCount int    `gorm:"default:0"`
+	ErrorCount     int    `gorm:"default:0"`
+	Files          string `gorm:"type:longtext"` // JSON daftar file per kategori
+	Truncated      bool   `gorm:"default:false"` // Daftar file dipotong (jumlah tetap akurat)
+
+	// READY = bisa dijalankan, EXECUTED = sudah dijalankan
+	Status     string     `gorm:"type:enum('READY','EXECUTED');default:'READY';index"`
+	ExpiresAt  time.Time  `gorm:"index"`
+	ExecutedAt *time.Time `gorm:"nullable"`
+	CreatedAt  time.Time
+}
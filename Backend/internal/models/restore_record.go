@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// RestoreRecord mencatat satu permintaan restore (siapa yang meminta, sumber, tujuan) beserta hasil akhirnya.
+// Restore dijalankan sebagai job one-shot (tanpa baris ScheduledJob), sehingga record ini adalah pegangannya.
+type RestoreRecord struct {
+	ID            uint   `gorm:"primaryKey;type:int unsigned"`
+	RequestedBy   string `gorm:"size:100"`                 // Username dari token JWT
+	RequestedByID *uint  `gorm:"column:requested_by_id"`   // User ID dari token JWT
+	Source        string `gorm:"size:20;not null"`         // PATH | SNAPSHOT | PREVIEW
+	JobID         *uint  `gorm:"column:job_id;index"`      // Job asal snapshot (NULL untuk restore path mentah)
+	SnapshotID    *uint  `gorm:"column:snapshot_id;index"` // NULL untuk restore path mentah
+	PreviewID     *uint  `gorm:"column:preview_id"`        // Preview yang dijalankan (jika ada)
+	RunID         *uint  `gorm:"column:run_id;index"`      // JobRun eksekusi restore (diisi saat selesai)
+
+	// Sumber & tujuan
+	RemoteName      string `gorm:"size:100;not null"`
+	SourcePath      string `gorm:"size:512;not null"`  // Path di remote
+	DestinationPath string `gorm:"size:1024;not null"` // Folder tujuan, atau path file untuk snapshot file
+	InPlace         bool   `gorm:"default:false"`
+
+	// Rencana restore (Paths/Include dalam JSON array)
+	Paths          string `gorm:"type:text"`
+	Include        string `gorm:"type:text"`
+	ConflictPolicy string `gorm:"size:20"`
+	QuarantinePath string `gorm:"size:1024"`
+
+	// QUEUED selama menunggu/berjalan, lalu status akhir run (SUCCESS, FAIL_*, TIMEOUT_*, CANCELLED, ...).
+	// FAIL_ENQUEUE = gagal masuk antrean dispatcher.
+	Status           string     `gorm:"size:30;index;default:'QUEUED'"`
+	TransferredBytes int64      `gorm:"default:0"`
+	TransferredFiles int64      `gorm:"default:0"`
+	ErrorMessage     string     `gorm:"type:text"`
+	StartedAt        *time.Time `gorm:"nullable"`
+	FinishedAt       *time.Time `gorm:"nullable"`
+	CreatedAt        time.Time  `gorm:"index"`
+}
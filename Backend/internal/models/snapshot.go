@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Snapshot merepresentasikan satu artefak backup (folder/file bertimestamp) yang dibuat oleh job.
+// Retensi hanya boleh menghapus item yang tercatat di sini.
+type Snapshot struct {
+	ID              uint   `gorm:"primaryKey;type:int unsigned"`
+	JobID           uint   `gorm:"column:job_id;index;not null"`
+	RunID           uint   `gorm:"column:run_id;index"`
+	RemoteName      string `gorm:"size:100;not null"`
+	DestinationPath string `gorm:"size:255;not null"` // Folder induk (DestinationPath job saat run)
+	Name            string `gorm:"size:255;not null"` // Nama item di dalam DestinationPath
+	SourcePath      string `gorm:"size:255"`          // SourcePath job saat run (target restore default)
+	IsDir           bool   `gorm:"default:false"`
+	SizeBytes       int64  `gorm:"default:0"`
+
+	// COMPLETE = run sukses (termasuk post-script) & isi terverifikasi, INCOMPLETE = transfer gagal/terputus,
+	// run gagal, gagal verifikasi, atau run masih berjalan (bisa berisi sebagian data),
+	// PRUNED = dihapus retensi, MISSING = tidak ditemukan lagi di remote
+	Status     string     `gorm:"type:enum('COMPLETE','INCOMPLETE','PRUNED','MISSING');default:'COMPLETE';index"`
+	SnapshotAt time.Time  `gorm:"index"`    // Timestamp di nama snapshot
+	VerifiedAt *time.Time `gorm:"nullable"` // Diisi setelah isi snapshot dicek terhadap file yang di-upload run
+	PrunedAt   *time.Time `gorm:"nullable"`
+
+	// Manifest: Jumlah file yang tercatat di catalog (CatalogedAt NULL = belum ada manifest)
+	ManifestFiles int        `gorm:"default:0"`
+	CatalogedAt   *time.Time `gorm:"nullable"`
+
+	// Pin: Snapshot yang di-pin tidak pernah dihapus dan tidak dihitung retensi (NULL PinnedUntil = tanpa batas)
+	Pinned      bool       `gorm:"default:false"`
+	PinReason   string     `gorm:"size:255"`
+	PinnedAt    *time.Time `gorm:"nullable"`
+	PinnedUntil *time.Time `gorm:"nullable"`
+	CreatedAt   time.Time
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Workflow merepresentasikan DAG dari ScheduledJob yang dijalankan berurutan
+// (mis. dump DB → backup folder dump → replikasi ke remote kedua)
+type Workflow struct {
+	ID           uint   `gorm:"primaryKey;type:int unsigned"`
+	Name         string `gorm:"size:100;unique;not null"`
+	Description  string `gorm:"size:255"`
+	ScheduleCron string `gorm:"size:100;nullable"` // Kosong = hanya dipicu manual
+	TimeZone     string `gorm:"column:time_zone;size:64"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Steps []WorkflowStep `gorm:"foreignKey:WorkflowID;constraint:OnDelete:CASCADE"`
+	Edges []WorkflowEdge `gorm:"foreignKey:WorkflowID;constraint:OnDelete:CASCADE"`
+}
+
+// WorkflowStep: Satu node DAG (job yang sudah ada)
+type WorkflowStep struct {
+	ID         uint `gorm:"primaryKey;type:int unsigned"`
+	WorkflowID uint `gorm:"column:workflow_id;type:int unsigned;index;not null"`
+	JobID      uint `gorm:"column:job_id;not null"`
+}
+
+// WorkflowEdge: Job ToJobID dijalankan setelah FromJobID selesai dengan hasil sesuai Condition
+type WorkflowEdge struct {
+	ID         uint   `gorm:"primaryKey;type:int unsigned"`
+	WorkflowID uint   `gorm:"column:workflow_id;type:int unsigned;index;not null"`
+	FromJobID  uint   `gorm:"column:from_job_id;not null"`
+	ToJobID    uint   `gorm:"column:to_job_id;not null"`
+	Condition  string `gorm:"type:enum('ON_SUCCESS','ON_FAILURE');default:'ON_SUCCESS'"`
+}
+
+// WorkflowRun: Satu eksekusi workflow dengan status agregat seluruh step
+type WorkflowRun struct {
+	ID            uint       `gorm:"primaryKey;type:int unsigned"`
+	WorkflowID    uint       `gorm:"column:workflow_id;index"`
+	WorkflowName  string     `gorm:"size:100"`
+	TriggerSource string     `gorm:"size:20"`
+	Status        string     `gorm:"size:20;index"` // RUNNING, SUCCESS, FAILED
+	StartedAt     time.Time  `gorm:"index"`
+	FinishedAt    *time.Time `gorm:"nullable"`
+	DurationSec   int        `gorm:"column:duration_sec;default:0"`
+
+	// StepStates: JSON map job ID -> status step (PENDING, QUEUED, SUCCESS, FAIL_*, SKIPPED, ...)
+	StepStates string `gorm:"column:step_states;type:text"`
+	Message    string `gorm:"type:text"`
+}
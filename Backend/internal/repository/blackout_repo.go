@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gbackup-new/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BlackoutRepository mendefinisikan kontrak untuk blackout window
+type BlackoutRepository interface {
+	Create(window *models.BlackoutWindow) error
+	Save(window *models.BlackoutWindow) error
+	Delete(windowID uint) error
+	FindByID(windowID uint) (*models.BlackoutWindow, error)
+	FindByIDs(windowIDs []uint) ([]models.BlackoutWindow, error)
+	FindAll() ([]models.BlackoutWindow, error)
+}
+
+type blackoutRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewBlackoutRepository(db *gorm.DB) BlackoutRepository {
+	return &blackoutRepositoryImpl{DB: db}
+}
+
+// Create: Menyimpan blackout window baru
+func (r *blackoutRepositoryImpl) Create(window *models.BlackoutWindow) error {
+	if err := r.DB.Create(window).Error; err != nil {
+		return fmt.Errorf("gagal menyimpan blackout window: %w", err)
+	}
+	return nil
+}
+
+// Save: Menyimpan perubahan blackout window
+func (r *blackoutRepositoryImpl) Save(window *models.BlackoutWindow) error {
+	if err := r.DB.Save(window).Error; err != nil {
+		return fmt.Errorf("gagal update blackout window %d: %w", window.ID, err)
+	}
+	return nil
+}
+
+// Delete: Menghapus blackout window (referensi di job diabaikan saat evaluasi)
+func (r *blackoutRepositoryImpl) Delete(windowID uint) error {
+	result := r.DB.Delete(&models.BlackoutWindow{}, windowID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("blackout window ID %d tidak ditemukan", windowID)
+	}
+	return nil
+}
+
+func (r *blackoutRepositoryImpl) FindByID(windowID uint) (*models.BlackoutWindow, error) {
+	var window models.BlackoutWindow
+	if err := r.DB.First(&window, windowID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("blackout window ID %d tidak ditemukan", windowID)
+		}
+		return nil, err
+	}
+	return &window, nil
+}
+
+// FindByIDs: Mengambil beberapa blackout window sekaligus (ID yang tidak ada dilewati)
+func (r *blackoutRepositoryImpl) FindByIDs(windowIDs []uint) ([]models.BlackoutWindow, error) {
+	var windows []models.BlackoutWindow
+	if len(windowIDs) == 0 {
+		return windows, nil
+	}
+	result := r.DB.Where("id IN ?", windowIDs).Find(&windows)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return windows, nil
+}
+
+func (r *blackoutRepositoryImpl) FindAll() ([]models.BlackoutWindow, error) {
+	var windows []models.BlackoutWindow
+	result := r.DB.Order("name ASC").Find(&windows)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return windows, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"fmt"
+	"gbackup-new/backend/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// catalogBatchSize: Jumlah baris per INSERT saat menyimpan manifest besar
+const catalogBatchSize = 500
+
+// CatalogSearchFilter: Kriteria pencarian file di catalog
+type CatalogSearchFilter struct {
+	Path  string     // Path absolut di sumber, atau potongan akhir path (mis. nginx/nginx.conf)
+	JobID uint       // 0 = semua job
+	From  *time.Time // Batas bawah snapshot_at (inklusif)
+	To    *time.Time // Batas atas snapshot_at (eksklusif)
+	Limit int
+}
+
+// CatalogRepository mendefinisikan kontrak untuk manifest file per snapshot
+type CatalogRepository interface {
+	ReplaceManifest(snapshotID uint, entries []models.CatalogEntry) error
+	FindBySnapshotID(snapshotID uint) ([]models.CatalogEntry, error)
+	Search(filter CatalogSearchFilter) ([]models.CatalogEntry, error)
+	DeleteBySnapshotID(snapshotID uint) error
+}
+
+type catalogRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewCatalogRepository(db *gorm.DB) CatalogRepository {
+	return &catalogRepositoryImpl{DB: db}
+}
+
+// ReplaceManifest: Menyimpan manifest snapshot (manifest lama untuk snapshot yang sama diganti)
+func (r *catalogRepositoryImpl) ReplaceManifest(snapshotID uint, entries []models.CatalogEntry) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("snapshot_id = ?", snapshotID).Delete(&models.CatalogEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(entries, catalogBatchSize).Error; err != nil {
+			return fmt.Errorf("gagal menyimpan manifest snapshot %d: %w", snapshotID, err)
+		}
+		return nil
+	})
+}
+
+// FindBySnapshotID: Isi manifest satu snapshot, terurut berdasarkan path
+func (r *catalogRepositoryImpl) FindBySnapshotID(snapshotID uint) ([]models.CatalogEntry, error) {
+	var entries []models.CatalogEntry
+	result := r.DB.Where("snapshot_id = ?", snapshotID).
+		Order("path ASC").
+		Find(&entries)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return entries, nil
+}
+
+// Search: File yang cocok di snapshot yang masih ada di remote, snapshot terbaru lebih dulu
+func (r *catalogRepositoryImpl) Search(filter CatalogSearchFilter) ([]models.CatalogEntry, error) {
+	var entries []models.CatalogEntry
+
+	path := strings.TrimSpace(filter.Path)
+	suffix := "%/" + escapeLike(strings.TrimLeft(path, "/"))
+
+	query := r.DB.Model(&models.CatalogEntry{}).
+		Select("catalog_entries.*").
+		Joins("JOIN snapshots ON snapshots.id = catalog_entries.snapshot_id").
+		Where("snapshots.status IN ?", []string{"COMPLETE", "INCOMPLETE"}).
+		Where("catalog_entries.source_path = ? OR catalog_entries.source_path LIKE ?", path, suffix)
+
+	if filter.JobID != 0 {
+		query = query.Where("catalog_entries.job_id = ?", filter.JobID)
+	}
+	if filter.From != nil {
+		query = query.Where("snapshots.snapshot_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("snapshots.snapshot_at < ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	result := query.Order("snapshots.snapshot_at DESC, catalog_entries.id ASC").Find(&entries)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return entries, nil
+}
+
+// DeleteBySnapshotID: Menghapus manifest snapshot (dipanggil saat snapshot di-prune)
+func (r *catalogRepositoryImpl) DeleteBySnapshotID(snapshotID uint) error {
+	return r.DB.Where("snapshot_id = ?", snapshotID).Delete(&models.CatalogEntry{}).Error
+}
+
+// escapeLike: Meloloskan karakter wildcard LIKE agar path dicocokkan apa adanya
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
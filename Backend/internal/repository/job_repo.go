@@ -16,11 +16,15 @@ type JobRepository interface {
 	FindAllActiveJobs() ([]models.ScheduledJob, error)
 	FindManualJob() ([]models.ScheduledJob, error)
 	UpdateLastRunStatus(jobID uint, lastRunTime time.Time, status string) error
-	UpdateJobActivity(JobID uint, isActive bool) error
+	UpdateStatus(jobID uint, status string) error
+	UpdateLastScheduledAt(jobID uint, scheduledAt time.Time) error
+	UpdateJobActivity(JobID uint, isActive bool, resumeAt *time.Time) error
+	FindJobsDueForResume(now time.Time) ([]models.ScheduledJob, error)
 	CountJobOnRemote(remoteName string) (int64, error)
 	DeleteJob(JobID uint) error
 	UpdateJob(jobID uint, updates map[string]interface{}) error
 	FindAllJobs() ([]models.ScheduledJob, error)
+	FindStaleRunningJobs(startedBefore time.Time) ([]models.ScheduledJob, error)
 }
 
 type jobRepositoryImpl struct {
@@ -90,13 +94,50 @@ func (r *jobRepositoryImpl) UpdateLastRunStatus(jobID uint, lastRunTime time.Tim
 	return result.Error
 }
 
-func (r *jobRepositoryImpl) UpdateJobActivity(jobID uint, isActive bool) error {
+// UpdateStatus: Mengupdate status_queue saja (tanpa menyentuh last_run_at)
+func (r *jobRepositoryImpl) UpdateStatus(jobID uint, status string) error {
 	result := r.DB.Model(&models.ScheduledJob{}).
 		Where("id = ?", jobID).
-		Update("is_active", isActive)
+		Update("status_queue", status)
 	return result.Error
 }
 
+// UpdateLastScheduledAt: Menandai jadwal cron terakhir yang sudah ditangani (basis deteksi misfire)
+func (r *jobRepositoryImpl) UpdateLastScheduledAt(jobID uint, scheduledAt time.Time) error {
+	result := r.DB.Model(&models.ScheduledJob{}).
+		Where("id = ?", jobID).
+		Update("last_scheduled_at", scheduledAt)
+	return result.Error
+}
+
+// UpdateJobActivity: Pause (isActive=false, resumeAt opsional) atau resume job
+func (r *jobRepositoryImpl) UpdateJobActivity(jobID uint, isActive bool, resumeAt *time.Time) error {
+	updates := map[string]interface{}{
+		"is_active": isActive,
+		"paused_at": nil,
+		"resume_at": nil,
+	}
+	if !isActive {
+		updates["paused_at"] = time.Now()
+		updates["resume_at"] = resumeAt
+	}
+	result := r.DB.Model(&models.ScheduledJob{}).
+		Where("id = ?", jobID).
+		Updates(updates)
+	return result.Error
+}
+
+// FindJobsDueForResume: Job yang di-pause dengan resume_at yang sudah lewat
+func (r *jobRepositoryImpl) FindJobsDueForResume(now time.Time) ([]models.ScheduledJob, error) {
+	var jobs []models.ScheduledJob
+	result := r.DB.Where("is_active = ? AND resume_at IS NOT NULL AND resume_at <= ?", false, now).Find(&jobs)
+
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return jobs, nil
+}
+
 func (r *jobRepositoryImpl) FindManualJob() ([]models.ScheduledJob, error) {
 	var jobs []models.ScheduledJob
 	result := r.DB.Where("schedule_cron IS NULL OR schedule_cron = ?", "").Find(&jobs)
@@ -142,6 +183,18 @@ func (r *jobRepositoryImpl) UpdateJob(jobID uint, updates map[string]interface{}
 	return nil
 }
 
+// FindStaleRunningJobs: Job berstatus RUNNING yang mulai sebelum batas waktu (kandidat lock yatim)
+func (r *jobRepositoryImpl) FindStaleRunningJobs(startedBefore time.Time) ([]models.ScheduledJob, error) {
+	var jobs []models.ScheduledJob
+	result := r.DB.Where("status_queue = ?", "RUNNING").
+		Where("last_run_at IS NULL OR last_run_at <= ?", startedBefore).
+		Find(&jobs)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return jobs, nil
+}
+
 func (r *jobRepositoryImpl) FindAllJobs() ([]models.ScheduledJob, error) {
 	var jobs []models.ScheduledJob
 	err := r.DB.Where("operation_mode != ?", "RESTORE").
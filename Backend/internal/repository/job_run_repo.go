@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gbackup-new/backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobRunRepository mendefinisikan kontrak untuk riwayat eksekusi (JobRun)
+type JobRunRepository interface {
+	Create(run *models.JobRun) error
+	Save(run *models.JobRun) error
+	FindByID(runID uint) (*models.JobRun, error)
+	FindByJobID(jobID uint, limit int) ([]models.JobRun, error)
+	FindStaleRunning(startedBefore time.Time) ([]models.JobRun, error)
+	FindByWorkflowRunID(workflowRunID uint) ([]models.JobRun, error)
+	FindLatestByRestoreRecordID(recordID uint) (*models.JobRun, error)
+}
+
+type jobRunRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewJobRunRepository(db *gorm.DB) JobRunRepository {
+	return &jobRunRepositoryImpl{DB: db}
+}
+
+// Create: Mencatat run baru (status RUNNING)
+func (r *jobRunRepositoryImpl) Create(run *models.JobRun) error {
+	if err := r.DB.Create(run).Error; err != nil {
+		return fmt.Errorf("gagal menyimpan job run: %w", err)
+	}
+	return nil
+}
+
+// Save: Menyimpan perubahan run (fase selesai / status akhir)
+func (r *jobRunRepositoryImpl) Save(run *models.JobRun) error {
+	if err := r.DB.Save(run).Error; err != nil {
+		return fmt.Errorf("gagal update job run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+// FindByID: Mengambil satu run
+func (r *jobRunRepositoryImpl) FindByID(runID uint) (*models.JobRun, error) {
+	var run models.JobRun
+	result := r.DB.First(&run, runID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("run ID %d tidak ditemukan", runID)
+		}
+		return nil, result.Error
+	}
+	return &run, nil
+}
+
+// FindByJobID: Riwayat run satu job, terbaru lebih dulu
+func (r *jobRunRepositoryImpl) FindByJobID(jobID uint, limit int) ([]models.JobRun, error) {
+	var runs []models.JobRun
+	result := r.DB.Where("job_id = ?", jobID).
+		Order("started_at DESC, id DESC").
+		Limit(limit).
+		Find(&runs)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return runs, nil
+}
+
+// FindStaleRunning: Run berstatus RUNNING yang mulai sebelum batas waktu
+func (r *jobRunRepositoryImpl) FindStaleRunning(startedBefore time.Time) ([]models.JobRun, error) {
+	var runs []models.JobRun
+	result := r.DB.Where("status = ? AND started_at <= ?", "RUNNING", startedBefore).Find(&runs)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return runs, nil
+}
+
+// FindByWorkflowRunID: Semua run step dari satu eksekusi workflow, urut waktu mulai
+func (r *jobRunRepositoryImpl) FindByWorkflowRunID(workflowRunID uint) ([]models.JobRun, error) {
+	var runs []models.JobRun
+	result := r.DB.Where("workflow_run_id = ?", workflowRunID).Order("started_at ASC, id ASC").Find(&runs)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return runs, nil
+}
+
+// FindLatestByRestoreRecordID: Run terbaru yang melayani satu record restore (nil jika belum mulai)
+func (r *jobRunRepositoryImpl) FindLatestByRestoreRecordID(recordID uint) (*models.JobRun, error) {
+	var run models.JobRun
+	result := r.DB.Where("restore_record_id = ?", recordID).Order("started_at DESC, id DESC").First(&run)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &run, nil
+}
@@ -40,7 +40,7 @@ func (r *logRepositoryImpl) CreateLog(log *models.Log) error {
 		var oldlogs []models.Log
 
 		if err := r.DB.Order("timestamp ASC").Limit(int(toDelete)).Find(&oldlogs).Error; err != nil {
-			return fmt.Errorf("gagal mengambil log paling tua: %w", &err)
+			return fmt.Errorf("gagal mengambil log paling tua: %w", err)
 		}
 
 		if len(oldlogs) > 0 {
@@ -54,7 +54,7 @@ func (r *logRepositoryImpl) CreateLog(log *models.Log) error {
 			if err := r.DB.Where("id IN (?)", idsToDelete).Delete(&models.Log{}).Error; err != nil {
 				return fmt.Errorf("gagal menghapus log tertua: %w", err)
 			}
-			fmt.Sprintf("[LOG CLEANUP] Berhasil menghapus %d log tertua (Max: %d)\n", toDelete, maxLogs)
+			fmt.Printf("[LOG CLEANUP] Berhasil menghapus %d log tertua (Max: %d)\n", toDelete, maxLogs)
 
 		}
 	}
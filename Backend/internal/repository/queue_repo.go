@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"fmt"
+	"gbackup-new/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// QueueRepository mendefinisikan kontrak untuk antrean persisten dispatcher
+type QueueRepository interface {
+	Create(entry *models.QueueEntry) error
+	Delete(entryID uint) error
+	FindAll() ([]models.QueueEntry, error)
+}
+
+type queueRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewQueueRepository(db *gorm.DB) QueueRepository {
+	return &queueRepositoryImpl{DB: db}
+}
+
+// Create: Menyimpan entry antrean baru
+func (r *queueRepositoryImpl) Create(entry *models.QueueEntry) error {
+	if err := r.DB.Create(entry).Error; err != nil {
+		return fmt.Errorf("gagal menyimpan antrean job: %w", err)
+	}
+	return nil
+}
+
+// Delete: Menghapus entry yang sudah di-dispatch
+func (r *queueRepositoryImpl) Delete(entryID uint) error {
+	return r.DB.Delete(&models.QueueEntry{}, entryID).Error
+}
+
+// FindAll: Mengambil seluruh antrean, urut Priority lalu waktu jatuh tempo
+func (r *queueRepositoryImpl) FindAll() ([]models.QueueEntry, error) {
+	var entries []models.QueueEntry
+	result := r.DB.Order("priority ASC, due_at ASC, id ASC").Find(&entries)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return entries, nil
+}
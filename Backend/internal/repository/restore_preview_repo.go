@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gbackup-new/backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrPreviewAlreadyExecuted: Preview sudah pernah dijalankan (atau sedang dijalankan request lain)
+var ErrPreviewAlreadyExecuted = errors.New("preview restore sudah dijalankan")
+
+// RestorePreviewRepository mendefinisikan kontrak untuk preview restore
+type RestorePreviewRepository interface {
+	Create(preview *models.RestorePreview) error
+	FindByID(previewID uint) (*models.RestorePreview, error)
+	MarkExecuted(previewID uint, executedAt time.Time) error
+	ResetExecuted(previewID uint) error
+	FindReadySnapshotIDs(now time.Time) ([]uint, error)
+}
+
+type restorePreviewRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewRestorePreviewRepository(db *gorm.DB) RestorePreviewRepository {
+	return &restorePreviewRepositoryImpl{DB: db}
+}
+
+// Create: Menyimpan preview baru
+func (r *restorePreviewRepositoryImpl) Create(preview *models.RestorePreview) error {
+	if err := r.DB.Create(preview).Error; err != nil {
+		return fmt.Errorf("gagal menyimpan preview restore: %w", err)
+	}
+	return nil
+}
+
+// FindByID: Mengambil satu preview
+func (r *restorePreviewRepositoryImpl) FindByID(previewID uint) (*models.RestorePreview, error) {
+	var preview models.RestorePreview
+	result := r.DB.First(&preview, previewID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("preview restore ID %d tidak ditemukan", previewID)
+		}
+		return nil, result.Error
+	}
+	return &preview, nil
+}
+
+// MarkExecuted: READY -> EXECUTED secara atomik agar preview hanya bisa dijalankan sekali
+func (r *restorePreviewRepositoryImpl) MarkExecuted(previewID uint, executedAt time.Time) error {
+	result := r.DB.Model(&models.RestorePreview{}).
+		Where("id = ? AND status = ?", previewID, "READY").
+		Updates(map[string]interface{}{
+			"status":      "EXECUTED",
+			"executed_at": executedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPreviewAlreadyExecuted
+	}
+	return nil
+}
+
+// ResetExecuted: EXECUTED -> READY, dipakai jika restore gagal masuk antrean setelah preview diklaim
+func (r *restorePreviewRepositoryImpl) ResetExecuted(previewID uint) error {
+	return r.DB.Model(&models.RestorePreview{}).
+		Where("id = ? AND status = ?", previewID, "EXECUTED").
+		Updates(map[string]interface{}{
+			"status":      "READY",
+			"executed_at": nil,
+		}).Error
+}
+
+// FindReadySnapshotIDs: ID snapshot yang dipakai preview READY yang belum kedaluwarsa
+func (r *restorePreviewRepositoryImpl) FindReadySnapshotIDs(now time.Time) ([]uint, error) {
+	var ids []uint
+	result := r.DB.Model(&models.RestorePreview{}).
+		Where("status = ? AND expires_at > ?", "READY", now).
+		Distinct().
+		Pluck("snapshot_id", &ids)
+	return ids, result.Error
+}
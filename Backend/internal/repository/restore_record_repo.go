@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gbackup-new/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RestoreRecordRepository mendefinisikan kontrak untuk catatan permintaan restore
+type RestoreRecordRepository interface {
+	Create(record *models.RestoreRecord) error
+	Save(record *models.RestoreRecord) error
+	FindByID(recordID uint) (*models.RestoreRecord, error)
+	FindRecent(limit int) ([]models.RestoreRecord, error)
+	FindSnapshotIDsByStatus(statuses []string) ([]uint, error)
+}
+
+type restoreRecordRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewRestoreRecordRepository(db *gorm.DB) RestoreRecordRepository {
+	return &restoreRecordRepositoryImpl{DB: db}
+}
+
+// Create: Mencatat permintaan restore baru (status QUEUED)
+func (r *restoreRecordRepositoryImpl) Create(record *models.RestoreRecord) error {
+	if err := r.DB.Create(record).Error; err != nil {
+		return fmt.Errorf("gagal menyimpan record restore: %w", err)
+	}
+	return nil
+}
+
+// Save: Memperbarui record (status & hasil akhir)
+func (r *restoreRecordRepositoryImpl) Save(record *models.RestoreRecord) error {
+	return r.DB.Save(record).Error
+}
+
+// FindByID: Mengambil satu record restore
+func (r *restoreRecordRepositoryImpl) FindByID(recordID uint) (*models.RestoreRecord, error) {
+	var record models.RestoreRecord
+	result := r.DB.First(&record, recordID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("record restore ID %d tidak ditemukan", recordID)
+		}
+		return nil, result.Error
+	}
+	return &record, nil
+}
+
+// FindRecent: Record restore terbaru lebih dulu
+func (r *restoreRecordRepositoryImpl) FindRecent(limit int) ([]models.RestoreRecord, error) {
+	var records []models.RestoreRecord
+	result := r.DB.Order("created_at DESC, id DESC").Limit(limit).Find(&records)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return records, nil
+}
+
+// FindSnapshotIDsByStatus: ID snapshot yang dipakai record restore berstatus tertentu (mis. QUEUED/RUNNING)
+func (r *restoreRecordRepositoryImpl) FindSnapshotIDsByStatus(statuses []string) ([]uint, error) {
+	var ids []uint
+	result := r.DB.Model(&models.RestoreRecord{}).
+		Where("snapshot_id IS NOT NULL AND status IN ?", statuses).
+		Distinct().
+		Pluck("snapshot_id", &ids)
+	return ids, result.Error
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gbackup-new/backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotRepository mendefinisikan kontrak untuk artefak backup per job
+type SnapshotRepository interface {
+	Create(snapshot *models.Snapshot) error
+	FindByID(snapshotID uint) (*models.Snapshot, error)
+	FindByJobID(jobID uint) ([]models.Snapshot, error)
+	FindLiveByJobID(jobID uint) ([]models.Snapshot, error)
+	UpdateStatus(snapshotID uint, status string) error
+	MarkPruned(snapshotID uint, prunedAt time.Time) error
+	MarkVerified(snapshotID uint, verifiedAt time.Time) error
+	SetPin(snapshotID uint, pinned bool, reason string, until *time.Time) error
+	MarkCataloged(snapshotID uint, files int, catalogedAt time.Time) error
+	FindByIDs(snapshotIDs []uint) ([]models.Snapshot, error)
+	FindLatestCompleteAsOf(jobID uint, asOf time.Time) (*models.Snapshot, error)
+	ExistsAt(remoteName, destinationPath, name string) (bool, error)
+}
+
+type snapshotRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewSnapshotRepository(db *gorm.DB) SnapshotRepository {
+	return &snapshotRepositoryImpl{DB: db}
+}
+
+// Create: Mencatat artefak baru hasil sebuah run
+func (r *snapshotRepositoryImpl) Create(snapshot *models.Snapshot) error {
+	if err := r.DB.Create(snapshot).Error; err != nil {
+		return fmt.Errorf("gagal menyimpan snapshot: %w", err)
+	}
+	return nil
+}
+
+// FindByID: Mengambil satu snapshot
+func (r *snapshotRepositoryImpl) FindByID(snapshotID uint) (*models.Snapshot, error) {
+	var snapshot models.Snapshot
+	result := r.DB.First(&snapshot, snapshotID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("snapshot ID %d tidak ditemukan", snapshotID)
+		}
+		return nil, result.Error
+	}
+	return &snapshot, nil
+}
+
+// FindByIDs: Mengambil beberapa snapshot sekaligus (urutan tidak dijamin)
+func (r *snapshotRepositoryImpl) FindByIDs(snapshotIDs []uint) ([]models.Snapshot, error) {
+	var snapshots []models.Snapshot
+	if len(snapshotIDs) == 0 {
+		return snapshots, nil
+	}
+	result := r.DB.Where("id IN ?", snapshotIDs).Find(&snapshots)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return snapshots, nil
+}
+
+// FindByJobID: Semua snapshot satu job (termasuk yang sudah dihapus), terbaru lebih dulu
+func (r *snapshotRepositoryImpl) FindByJobID(jobID uint) ([]models.Snapshot, error) {
+	var snapshots []models.Snapshot
+	result := r.DB.Where("job_id = ?", jobID).
+		Order("snapshot_at DESC, id DESC").
+		Find(&snapshots)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return snapshots, nil
+}
+
+// FindLatestCompleteAsOf: Snapshot COMPLETE terbaru yang dibuat pada/sebelum asOf (nil jika tidak ada)
+func (r *snapshotRepositoryImpl) FindLatestCompleteAsOf(jobID uint, asOf time.Time) (*models.Snapshot, error) {
+	var snapshot models.Snapshot
+	result := r.DB.Where("job_id = ? AND status = ? AND snapshot_at <= ?", jobID, "COMPLETE", asOf).
+		Order("snapshot_at DESC, id DESC").
+		First(&snapshot)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &snapshot, nil
+}
+
+// FindLiveByJobID: Snapshot yang seharusnya masih ada di remote (COMPLETE/INCOMPLETE)
+func (r *snapshotRepositoryImpl) FindLiveByJobID(jobID uint) ([]models.Snapshot, error) {
+	var snapshots []models.Snapshot
+	result := r.DB.Where("job_id = ? AND status IN ?", jobID, []string{"COMPLETE", "INCOMPLETE"}).
+		Order("snapshot_at DESC, id DESC").
+		Find(&snapshots)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return snapshots, nil
+}
+
+// ExistsAt: true jika item di lokasi tersebut pernah tercatat sebagai snapshot (job mana pun, status apa pun)
+func (r *snapshotRepositoryImpl) ExistsAt(remoteName, destinationPath, name string) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.Snapshot{}).
+		Where("remote_name = ? AND destination_path = ? AND name = ?", remoteName, destinationPath, name).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+// UpdateStatus: Mengubah status snapshot (mis. MISSING)
+func (r *snapshotRepositoryImpl) UpdateStatus(snapshotID uint, status string) error {
+	result := r.DB.Model(&models.Snapshot{}).
+		Where("id = ?", snapshotID).
+		Update("status", status)
+	return result.Error
+}
+
+// MarkVerified: Menandai isi snapshot sudah dicek terhadap file yang di-upload
+func (r *snapshotRepositoryImpl) MarkVerified(snapshotID uint, verifiedAt time.Time) error {
+	result := r.DB.Model(&models.Snapshot{}).
+		Where("id = ?", snapshotID).
+		Update("verified_at", verifiedAt)
+	return result.Error
+}
+
+// SetPin: Pin (reason & until opsional) atau unpin snapshot
+func (r *snapshotRepositoryImpl) SetPin(snapshotID uint, pinned bool, reason string, until *time.Time) error {
+	updates := map[string]interface{}{
+		"pinned":       pinned,
+		"pin_reason":   "",
+		"pinned_at":    nil,
+		"pinned_until": nil,
+	}
+	if pinned {
+		updates["pin_reason"] = reason
+		updates["pinned_at"] = time.Now()
+		updates["pinned_until"] = until
+	}
+	result := r.DB.Model(&models.Snapshot{}).
+		Where("id = ?", snapshotID).
+		Updates(updates)
+	return result.Error
+}
+
+// MarkCataloged: Mencatat bahwa manifest snapshot sudah tersimpan di catalog
+func (r *snapshotRepositoryImpl) MarkCataloged(snapshotID uint, files int, catalogedAt time.Time) error {
+	result := r.DB.Model(&models.Snapshot{}).
+		Where("id = ?", snapshotID).
+		Updates(map[string]interface{}{
+			"manifest_files": files,
+			"cataloged_at":   catalogedAt,
+		})
+	return result.Error
+}
+
+// MarkPruned: Menandai snapshot sudah dihapus oleh retensi
+func (r *snapshotRepositoryImpl) MarkPruned(snapshotID uint, prunedAt time.Time) error {
+	result := r.DB.Model(&models.Snapshot{}).
+		Where("id = ?", snapshotID).
+		Updates(map[string]interface{}{
+			"status":    "PRUNED",
+			"pruned_at": prunedAt,
+		})
+	return result.Error
+}
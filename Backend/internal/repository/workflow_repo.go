@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gbackup-new/backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkflowRepository mendefinisikan kontrak untuk workflow (DAG job) dan riwayat eksekusinya
+type WorkflowRepository interface {
+	Create(wf *models.Workflow) error
+	Update(wf *models.Workflow) error
+	Delete(workflowID uint) error
+	FindByID(workflowID uint) (*models.Workflow, error)
+	FindAll() ([]models.Workflow, error)
+
+	CreateRun(run *models.WorkflowRun) error
+	SaveRun(run *models.WorkflowRun) error
+	FindRunByID(runID uint) (*models.WorkflowRun, error)
+	FindRunsByWorkflowID(workflowID uint, limit int) ([]models.WorkflowRun, error)
+	FindRunningRuns() ([]models.WorkflowRun, error)
+}
+
+type workflowRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+func NewWorkflowRepository(db *gorm.DB) WorkflowRepository {
+	return &workflowRepositoryImpl{DB: db}
+}
+
+// Create: Menyimpan workflow beserta step dan edge-nya
+func (r *workflowRepositoryImpl) Create(wf *models.Workflow) error {
+	if err := r.DB.Create(wf).Error; err != nil {
+		return fmt.Errorf("gagal menyimpan workflow: %w", err)
+	}
+	return nil
+}
+
+// Update: Menyimpan field workflow dan mengganti seluruh step & edge dalam satu transaksi
+func (r *workflowRepositoryImpl) Update(wf *models.Workflow) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workflow_id = ?", wf.ID).Delete(&models.WorkflowStep{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workflow_id = ?", wf.ID).Delete(&models.WorkflowEdge{}).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Workflow{}).Where("id = ?", wf.ID).Updates(map[string]interface{}{
+			"name":          wf.Name,
+			"description":   wf.Description,
+			"schedule_cron": wf.ScheduleCron,
+			"time_zone":     wf.TimeZone,
+			"updated_at":    time.Now(),
+		})
+		if result.Error != nil {
+			return fmt.Errorf("gagal update workflow %d: %w", wf.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("workflow ID %d tidak ditemukan", wf.ID)
+		}
+
+		for i := range wf.Steps {
+			wf.Steps[i].ID = 0
+			wf.Steps[i].WorkflowID = wf.ID
+		}
+		for i := range wf.Edges {
+			wf.Edges[i].ID = 0
+			wf.Edges[i].WorkflowID = wf.ID
+		}
+		if len(wf.Steps) > 0 {
+			if err := tx.Create(&wf.Steps).Error; err != nil {
+				return err
+			}
+		}
+		if len(wf.Edges) > 0 {
+			if err := tx.Create(&wf.Edges).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete: Menghapus workflow beserta step & edge (riwayat run tetap disimpan)
+func (r *workflowRepositoryImpl) Delete(workflowID uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("workflow_id = ?", workflowID).Delete(&models.WorkflowStep{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workflow_id = ?", workflowID).Delete(&models.WorkflowEdge{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Workflow{}, workflowID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("workflow ID %d tidak ditemukan", workflowID)
+		}
+		return nil
+	})
+}
+
+func (r *workflowRepositoryImpl) FindByID(workflowID uint) (*models.Workflow, error) {
+	var wf models.Workflow
+	if err := r.DB.Preload("Steps").Preload("Edges").First(&wf, workflowID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("workflow ID %d tidak ditemukan", workflowID)
+		}
+		return nil, err
+	}
+	return &wf, nil
+}
+
+func (r *workflowRepositoryImpl) FindAll() ([]models.Workflow, error) {
+	var workflows []models.Workflow
+	result := r.DB.Preload("Steps").Preload("Edges").Order("name ASC").Find(&workflows)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return workflows, nil
+}
+
+// CreateRun: Mencatat eksekusi workflow baru
+func (r *workflowRepositoryImpl) CreateRun(run *models.WorkflowRun) error {
+	if err := r.DB.Create(run).Error; err != nil {
+		return fmt.Errorf("gagal menyimpan workflow run: %w", err)
+	}
+	return nil
+}
+
+// SaveRun: Menyimpan perubahan status step / status agregat
+func (r *workflowRepositoryImpl) SaveRun(run *models.WorkflowRun) error {
+	if err := r.DB.Save(run).Error; err != nil {
+		return fmt.Errorf("gagal update workflow run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+func (r *workflowRepositoryImpl) FindRunByID(runID uint) (*models.WorkflowRun, error) {
+	var run models.WorkflowRun
+	if err := r.DB.First(&run, runID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("workflow run ID %d tidak ditemukan", runID)
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindRunsByWorkflowID: Riwayat eksekusi workflow, terbaru lebih dulu
+func (r *workflowRepositoryImpl) FindRunsByWorkflowID(workflowID uint, limit int) ([]models.WorkflowRun, error) {
+	var runs []models.WorkflowRun
+	result := r.DB.Where("workflow_id = ?", workflowID).
+		Order("started_at DESC").
+		Limit(limit).
+		Find(&runs)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return runs, nil
+}
+
+// FindRunningRuns: Eksekusi workflow yang belum selesai (untuk rekonsiliasi saat startup)
+func (r *workflowRepositoryImpl) FindRunningRuns() ([]models.WorkflowRun, error) {
+	var runs []models.WorkflowRun
+	result := r.DB.Where("status = ?", "RUNNING").Find(&runs)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, result.Error
+	}
+	return runs, nil
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gbackup-new/backend/internal/models"
 	"gbackup-new/backend/internal/repository"
@@ -10,6 +12,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,16 +20,53 @@ import (
 type BackupService interface {
 	CreateJobAndDispatch(job *models.ScheduledJob) error
 	TriggerManualJob(jobID uint) error
+	TriggerJob(jobID uint, opts RunOptions) error
 	DeleteJob(JobId uint) error
 	UpdateJob(jobID uint, updatedJob *models.ScheduledJob) error
 	GetJobByID(jobID uint) (*models.ScheduledJob, error)
+	PauseJob(jobID uint, resumeAt *time.Time) error
+	ResumeJob(jobID uint) error
+	PreviewRetention(jobID uint) (*RetentionPreviewDTO, error)
+	ClaimLegacySnapshots(jobID uint, names []string, force bool) ([]models.Snapshot, error)
+	CancelJob(jobID uint) error
+	CancelRestore(recordID uint) error
+	OnJobCompleted(listener JobCompletionListener)
+	OnJobChanged(listener JobChangeListener)
+	RecoverInterruptedJobs(lease time.Duration) (int, error)
+	RecordSkippedRun(job models.ScheduledJob, scheduledFor time.Time, reason string) error
+	Shutdown(grace time.Duration)
 }
 
+// JobCompletion: Hasil akhir satu run yang dilaporkan ke listener (mis. scheduler untuk retry)
+type JobCompletion struct {
+	Job  models.ScheduledJob
+	Run  models.JobRun
+	Opts RunOptions
+}
+
+// JobCompletionListener: Callback yang dipanggil setelah handleJobCompletion selesai mencatat hasil
+type JobCompletionListener func(c JobCompletion)
+
+// JobChangeListener: Callback setelah job dibuat/diubah (job terbaru dari DB) atau dihapus (job nil)
+type JobChangeListener func(jobID uint, job *models.ScheduledJob)
+
 type backupServiceImpl struct {
 	MonitorRepo repository.MonitoringRepository
 	JobRepo     repository.JobRepository
 	LogRepo     repository.LogRepository
 	MonitorSvc  MonitoringService
+	Registry    *RunRegistry
+	Dispatcher  JobDispatcher
+	Progress    *ProgressBus
+	RunRepo     repository.JobRunRepository
+	SnapRepo    repository.SnapshotRepository
+	CatalogRepo repository.CatalogRepository
+	RecordRepo  repository.RestoreRecordRepository
+	PreviewRepo repository.RestorePreviewRepository
+
+	listenersMu     sync.Mutex
+	listeners       []JobCompletionListener
+	changeListeners []JobChangeListener
 }
 
 type RcloneFileInfo struct {
@@ -39,15 +79,33 @@ type RcloneFileInfo struct {
 func NewBackupService(
 	jRepo repository.JobRepository,
 	lRepo repository.LogRepository,
+	rRepo repository.JobRunRepository,
+	sRepo repository.SnapshotRepository,
+	cRepo repository.CatalogRepository,
+	recRepo repository.RestoreRecordRepository,
+	pvRepo repository.RestorePreviewRepository,
 	mRepo repository.MonitoringRepository,
 	mSvc MonitoringService,
+	dispatcher JobDispatcher,
+	progress *ProgressBus,
 ) BackupService {
-	return &backupServiceImpl{
+	s := &backupServiceImpl{
 		JobRepo:     jRepo,
 		LogRepo:     lRepo,
+		RunRepo:     rRepo,
+		SnapRepo:    sRepo,
+		CatalogRepo: cRepo,
+		RecordRepo:  recRepo,
+		PreviewRepo: pvRepo,
 		MonitorRepo: mRepo,
 		MonitorSvc:  mSvc,
+		Registry:    NewRunRegistry(),
+		Dispatcher:  dispatcher,
+		Progress:    progress,
 	}
+	// Dispatcher memanggil executeJobLifecycle ketika slot worker tersedia
+	dispatcher.SetRunner(s.executeJobLifecycle)
+	return s
 }
 
 const MinFreeGB = 1.0
@@ -66,21 +124,27 @@ func (s *backupServiceImpl) CreateJobAndDispatch(job *models.ScheduledJob) error
 		job.RcloneMode = "copy"
 	}
 	if job.OperationMode == "RESTORE" {
-		fmt.Printf("[DISPATCHER] 🔄 RESTORE Job: %s (One-Shot, TIDAK disimpan ke DB)\n", job.JobName)
-		go s.executeJobLifecycle(*job)
-		return nil
+		// Restore dicatat sebagai RestoreRecord, dijalankan lewat RestoreService
+		return fmt.Errorf("job RESTORE tidak dibuat lewat CreateJobAndDispatch, gunakan RestoreService")
 	}
+	if err := ValidateCronExpression(job.ScheduleCron); err != nil {
+		return err
+	}
+	if err := ValidateTimeZone(job.TimeZone); err != nil {
+		return err
+	}
+
 	// 1. SELALU SIMPAN JOB KE DATABASE (sebagai Template)
 	if err := s.JobRepo.Create(job); err != nil {
 		return fmt.Errorf("gagal menyimpan job template: %w", err)
 	}
+	s.notifyJobChanged(job.ID, job)
 
 	// 2. JALANKAN JIKA MANUAL
 	if job.ScheduleCron == "" {
-		fmt.Printf("[DISPATCHER] Job %s (Manual) disimpan (ID: %d) dan dipicu langsung.\n", job.JobName, job.ID)
-		// Memulai eksekusi di background (Goroutine)
-		go s.executeJobLifecycle(*job)
-		return nil
+		fmt.Printf("[DISPATCHER] Job %s (Manual) disimpan (ID: %d) dan masuk antrean.\n", job.JobName, job.ID)
+		// Eksekusi dilakukan oleh worker pool dispatcher
+		return s.Dispatcher.Enqueue(*job, RunOptions{Trigger: TriggerAPI})
 		// Job Terjadwal (Auto Backup)
 		// fmt.Printf("[DISPATCHER] Job %d (%s) disimpan untuk Scheduler.\n", job.ID, job.JobName)
 	}
@@ -88,16 +152,145 @@ func (s *backupServiceImpl) CreateJobAndDispatch(job *models.ScheduledJob) error
 	return nil
 }
 
-// TriggerManualJob: Memicu Job yang sudah ada di DB
+// TriggerManualJob: Memicu Job yang sudah ada di DB (oleh user)
 func (s *backupServiceImpl) TriggerManualJob(jobID uint) error {
+	return s.TriggerJob(jobID, RunOptions{Trigger: TriggerManual})
+}
+
+// TriggerJob: Memasukkan Job yang sudah ada di DB ke antrean dengan opsi run tertentu
+func (s *backupServiceImpl) TriggerJob(jobID uint, opts RunOptions) error {
 	job, err := s.JobRepo.FindJobByID(jobID)
 	if err != nil {
 		return err
 	}
 
-	// Langsung eksekusi di background
-	go s.executeJobLifecycle(*job)
-	return nil
+	// Masukkan ke antrean, worker pool yang menjalankan
+	return s.Dispatcher.Enqueue(*job, opts)
+}
+
+// OnJobCompleted: Mendaftarkan listener hasil akhir run
+func (s *backupServiceImpl) OnJobCompleted(listener JobCompletionListener) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// notifyCompletion: Memanggil semua listener (di luar LogMutex)
+func (s *backupServiceImpl) notifyCompletion(c JobCompletion) {
+	s.listenersMu.Lock()
+	listeners := append([]JobCompletionListener(nil), s.listeners...)
+	s.listenersMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(c)
+	}
+}
+
+// OnJobChanged: Mendaftarkan listener perubahan konfigurasi job (mis. scheduler untuk entry cron)
+func (s *backupServiceImpl) OnJobChanged(listener JobChangeListener) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.changeListeners = append(s.changeListeners, listener)
+}
+
+// notifyJobChanged: Memanggil semua listener perubahan job
+func (s *backupServiceImpl) notifyJobChanged(jobID uint, job *models.ScheduledJob) {
+	s.listenersMu.Lock()
+	listeners := append([]JobChangeListener(nil), s.changeListeners...)
+	s.listenersMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(jobID, job)
+	}
+}
+
+// CancelJob: Membatalkan run yang sedang berjalan (kill process group pre-script/rclone/post-script)
+// dan mengeluarkan entry job yang masih menunggu di antrean
+func (s *backupServiceImpl) CancelJob(jobID uint) error {
+	fmt.Printf("[AUDIT] User meminta pembatalan Job ID: %d\n", jobID)
+
+	// Entry yang masih menunggu dikeluarkan lebih dulu agar tidak dijalankan begitu run aktif berhenti
+	cancelled := s.Dispatcher.CancelQueued(jobID, ErrJobCancelled)
+	for _, entry := range cancelled {
+		if err := s.recordCancelledEntry(entry); err != nil {
+			fmt.Printf("⚠️ [AUDIT] Gagal mencatat pembatalan antrean %d Job %d: %v\n", entry.ID, jobID, err)
+		}
+	}
+
+	err := s.Registry.CancelJob(jobID, ErrJobCancelled)
+	if errors.Is(err, ErrRunNotFound) && len(cancelled) > 0 {
+		// Tidak ada run berjalan yang akan menulis status akhir: PENDING dari antrean diganti di sini
+		if err := s.JobRepo.UpdateStatus(jobID, "CANCELLED"); err != nil {
+			fmt.Printf("⚠️ [AUDIT] Gagal set status CANCELLED Job %d: %v\n", jobID, err)
+		}
+		return nil
+	}
+	return err
+}
+
+// recordCancelledEntry: Mencatat entry antrean yang dibatalkan sebelum dijalankan sebagai JobRun CANCELLED
+func (s *backupServiceImpl) recordCancelledEntry(entry models.QueueEntry) error {
+	opts := entryOptions(entry)
+	attempt := opts.Attempt
+	if attempt < 1 {
+		attempt = 1
+	}
+	now := time.Now()
+	run := &models.JobRun{
+		JobID:            entry.JobID,
+		JobName:          entry.JobName,
+		RemoteName:       entry.RemoteName,
+		TriggerSource:    opts.Trigger,
+		Attempt:          attempt,
+		ScheduledFor:     opts.ScheduledFor,
+		Status:           "CANCELLED",
+		StartedAt:        now,
+		FinishedAt:       &now,
+		PreScriptStatus:  "SKIPPED",
+		TransferStatus:   "SKIPPED",
+		PostScriptStatus: "SKIPPED",
+		ErrorMessage:     "Dibatalkan user sebelum dijalankan (masih di antrean)",
+	}
+	var job models.ScheduledJob
+	if err := json.Unmarshal([]byte(entry.JobSnapshot), &job); err == nil {
+		run.OperationMode = job.OperationMode
+	}
+	if opts.WorkflowRunID != 0 {
+		wfRunID := opts.WorkflowRunID
+		run.WorkflowRunID = &wfRunID
+	}
+	return s.RunRepo.Create(run)
+}
+
+// CancelRestore: Membatalkan restore yang sedang berjalan (job restore tidak punya ID, dicari lewat record)
+func (s *backupServiceImpl) CancelRestore(recordID uint) error {
+	fmt.Printf("[AUDIT] User meminta pembatalan Restore #%d\n", recordID)
+	return s.Registry.CancelRestore(recordID, ErrJobCancelled)
+}
+
+// failureStatus: Menentukan status akhir saat sebuah fase gagal.
+// Jika context dibatalkan oleh user, status menjadi CANCELLED; jika fase kehabisan waktu, TIMEOUT_*;
+// jika backend dimatikan, INTERRUPTED.
+func failureStatus(ctx context.Context, failStatus, timeoutStatus string) string {
+	cause := context.Cause(ctx)
+	switch {
+	case errors.Is(cause, ErrJobCancelled):
+		return "CANCELLED"
+	case errors.Is(cause, ErrPhaseTimeout):
+		return timeoutStatus
+	case errors.Is(cause, ErrShutdown):
+		return "INTERRUPTED"
+	}
+	return failStatus
+}
+
+// phaseContext: Membuat context untuk satu fase dengan deadline (timeoutSec <= 0 = tanpa batas)
+func phaseContext(parent context.Context, phase string, timeoutSec int) (context.Context, context.CancelFunc) {
+	if timeoutSec <= 0 {
+		return context.WithCancel(parent)
+	}
+	cause := fmt.Errorf("%w: %s melebihi %d detik", ErrPhaseTimeout, phase, timeoutSec)
+	return context.WithTimeoutCause(parent, time.Duration(timeoutSec)*time.Second, cause)
 }
 
 // ----------------------------------------------------
@@ -105,13 +298,32 @@ func (s *backupServiceImpl) TriggerManualJob(jobID uint) error {
 // ----------------------------------------------------
 
 // executeJobLifecycle: Menjalankan Pre-Script, Rclone, dan Post-Script
-func (s *backupServiceImpl) executeJobLifecycle(job models.ScheduledJob) {
+func (s *backupServiceImpl) executeJobLifecycle(job models.ScheduledJob, opts RunOptions) {
 	fmt.Printf("[WORKER %d] Job %s: Memulai Eksekusi 3 Fase... RcloneMode: %s\n", job.ID, job.JobName, job.RcloneMode)
 	// ⭐ HIGHLIGHT: Menampilkan mode di log
 	fmt.Printf("[WORKER %d] Menjalankan Rclone (Mode: %s)...\n", job.ID, job.RcloneMode)
+	// Daftarkan ke registry sebelum status RUNNING ditulis, agar recovery tidak pernah melihat
+	// baris RUNNING milik run yang sebenarnya hidup
+	var restoreID uint
+	if opts.Restore != nil {
+		restoreID = opts.Restore.RecordID
+	}
+	ctx, runKey := s.Registry.Start(0, job.ID, restoreID, job.JobName)
+
 	// Set Status RUNNING (Locking)
 	s.JobRepo.UpdateLastRunStatus(job.ID, time.Now(), "RUNNING")
 
+	// Catat run di riwayat (JobRun) dan kaitkan ke entry registry (pembatalan lewat API)
+	run := s.startJobRun(job, opts)
+	s.Registry.AttachRun(runKey, run.ID)
+	// Run dilepas dari registry dulu, baru listener (retry, dsb.) dipanggil setelah hasil dicatat.
+	// Slot dispatcher (runningJobs) baru dilepas setelah fungsi ini kembali, jadi listener yang
+	// mengantre ulang job ini sendiri yang menulis status PENDING.
+	defer func() {
+		s.Registry.Finish(runKey)
+		s.notifyCompletion(JobCompletion{Job: job, Run: *run, Opts: opts})
+	}()
+
 	var finalResult RcloneResult
 	var finalStatus string
 
@@ -138,7 +350,7 @@ func (s *backupServiceImpl) executeJobLifecycle(job models.ScheduledJob) {
 					requiredSpace, availableSpace, job.RemoteName,
 				)
 				fmt.Printf("[WORKER %d] %s\n", job.ID, errorMsg)
-				s.handleJobCompletion(job, RcloneResult{Success: false, ErrorMsg: errorMsg}, "NOT_ENOUGH_SPACE")
+				s.handleJobCompletion(job, run, RcloneResult{Success: false, ErrorMsg: errorMsg}, "NOT_ENOUGH_SPACE")
 				return
 			}
 			fmt.Printf("✅ [WORKER %d] Storage OK: Cukup untuk backup\n", job.ID)
@@ -149,15 +361,20 @@ func (s *backupServiceImpl) executeJobLifecycle(job models.ScheduledJob) {
 	// --- FASE 1: PRE-SCRIPT ---
 	if job.PreScript != "" {
 		fmt.Printf("[WORKER %d] Menjalankan Pre-Script...\n", job.ID)
+		s.Progress.Publish(newProgressEvent(job, run, PhasePreScript))
 		hardenedPreScript := fmt.Sprintf("set -eo pipefail; \n%s", job.PreScript)
 		preScriptArgs := []string{"bash", "-c", hardenedPreScript}
 
-		result := ExecuteCliJob(preScriptArgs)
+		preCtx, cancelPre := phaseContext(ctx, "pre-script", job.PreScriptTimeoutSec)
+		result := ExecuteCliJobContext(preCtx, preScriptArgs)
+		status := failureStatus(preCtx, "FAIL_PRE_SCRIPT", "TIMEOUT_PRE_SCRIPT")
+		cancelPre()
+		s.recordPhase(run, PhasePreScript, result, status)
 		if !result.Success {
-			fmt.Printf("❌ [WORKER %d] Pre-Script GAGAL.\n", job.ID)
+			fmt.Printf("❌ [WORKER %d] Pre-Script GAGAL (%s).\n", job.ID, status)
 			finalResult = result
-			finalStatus = "FAIL_PRE_SCRIPT"
-			s.handleJobCompletion(job, finalResult, finalStatus)
+			finalStatus = status
+			s.handleJobCompletion(job, run, finalResult, finalStatus)
 			return
 		}
 	}
@@ -167,17 +384,19 @@ func (s *backupServiceImpl) executeJobLifecycle(job models.ScheduledJob) {
 	// 🆕 FASE 1.5: TIMESTAMP & ROUND ROBIN
 	// ============================================================
 	var runtimeDestPath string
+	var snapshot *models.Snapshot // Artefak yang dibuat run ini (hanya copy mode)
 
 	if job.OperationMode == "BACKUP" {
 
 		if job.RcloneMode == "copy" {
 			fmt.Printf("⚠️ [WORKER %d] Skipping timestamping for non-backup job.\n", job.ID)
-			timestamp := time.Now().Format("20060102_150405")
+			snapshotAt := time.Now().Truncate(time.Second)
+			timestamp := snapshotAt.Format(snapshotTimeLayout)
 
 			sourceInfo, err := os.Stat(job.SourcePath)
 			if err != nil {
 				errorMsg := fmt.Sprintf("Failed to stat source path: %v", err)
-				s.handleJobCompletion(job, RcloneResult{Success: false, ErrorMsg: errorMsg}, "FAIL_SOURCE_CHECK")
+				s.handleJobCompletion(job, run, RcloneResult{Success: false, ErrorMsg: errorMsg}, "FAIL_SOURCE_CHECK")
 				return
 			}
 
@@ -203,11 +422,18 @@ func (s *backupServiceImpl) executeJobLifecycle(job models.ScheduledJob) {
 			fmt.Printf("[WORKER %d] 🎯 Runtime destination: %s:%s\n", job.ID, job.RemoteName, runtimeDestPath)
 			fmt.Printf("[WORKER %d] 💾 DB destination (unchanged): %s\n", job.ID, job.DestinationPath)
 
-			// Round Robin Cleanup
-			fmt.Printf("[WORKER %d] 🔄 Checking for old backups...\n", job.ID)
-			if err := s.CleanupOldBackups(job.RemoteName, originalDestPath, job.MaxRetention); err != nil {
-				fmt.Printf("⚠️ [WORKER %d] Cleanup warning: %v\n", job.ID, err)
+			snapshot = &models.Snapshot{
+				JobID:           job.ID,
+				RunID:           run.ID,
+				RemoteName:      job.RemoteName,
+				DestinationPath: originalDestPath,
+				Name:            newDestinationName,
+				SourcePath:      job.SourcePath,
+				IsDir:           isSourceDir,
+				SnapshotAt:      snapshotAt,
 			}
+
+			// Round Robin Cleanup dijalankan di Fase 4, setelah snapshot baru sukses & terverifikasi
 		} else if job.RcloneMode == "sync" {
 			runtimeDestPath = job.DestinationPath
 		} else {
@@ -217,56 +443,461 @@ func (s *backupServiceImpl) executeJobLifecycle(job models.ScheduledJob) {
 		runtimeDestPath = job.DestinationPath
 	}
 
+	run.RuntimeDestPath = runtimeDestPath
+
 	// ============================================================
 
 	// --- FASE 2: RCLONE EXECUTION ---
 	fmt.Printf("[WORKER %d] Menjalankan Rclone...\n", job.ID)
-	rcloneArgs := s.buildRcloneArgs(job, runtimeDestPath)
-	resultRclone := ExecuteCliJob(rcloneArgs)
+	rcloneArgs := s.buildRcloneArgs(job, runtimeDestPath, opts)
+	var tracker *transferFileTracker
+	if (job.OperationMode == "RESTORE" && opts.Restore.tracksFiles()) || snapshot != nil {
+		tracker = newTransferFileTracker()
+	}
+	transferCtx, cancelTransfer := phaseContext(ctx, "rclone", job.TransferTimeoutSec)
+	var conflicts []rcloneManifestEntry
+	if job.OperationMode == "RESTORE" && opts.Restore != nil && opts.Restore.ConflictPolicy == ConflictRenameRestored {
+		var err error
+		conflicts, err = detectRestoreConflicts(transferCtx, restoreRemoteSource(job), job.DestinationPath, opts.Restore)
+		if err != nil {
+			fmt.Printf("⚠️ [WORKER %d] Gagal memeriksa file bentrok, file yang ada tidak akan disentuh: %v\n", job.ID, err)
+		}
+	}
+	resultRclone := s.runTransferWithProgress(transferCtx, job, run, rcloneArgs, tracker)
+	if resultRclone.Success && len(conflicts) > 0 {
+		fmt.Printf("[WORKER %d] 📝 %d file bentrok dipulihkan dengan akhiran .restored-*\n", job.ID, len(conflicts))
+		if err := restoreRenamedConflicts(transferCtx, restoreRemoteSource(job), job.DestinationPath, opts.Restore, conflicts, tracker); err != nil {
+			resultRclone.Success = false
+			resultRclone.ErrorMsg = err.Error()
+		}
+	}
+	transferStatus := failureStatus(transferCtx, "FAIL_RCLONE", "TIMEOUT_RCLONE")
+	cancelTransfer()
+	s.recordPhase(run, PhaseTransfer, resultRclone, transferStatus)
+	s.recordSnapshot(snapshot, resultRclone)
+	if job.OperationMode == "RESTORE" && tracker != nil {
+		s.recordRestoreResults(ctx, job, run, opts.Restore, tracker, resultRclone.Success)
+	}
+
+	snapshotVerified := false
+	var snapshotFiles []rcloneManifestEntry
+	if resultRclone.Success && snapshot != nil {
+		files, err := s.verifySnapshot(ctx, snapshot, tracker)
+		if err != nil {
+			fmt.Printf("⚠️ [WORKER %d] Verifikasi snapshot %s gagal, retensi dilewati: %v\n", job.ID, snapshot.Name, err)
+		} else {
+			snapshotVerified = true
+			snapshotFiles = files
+			fmt.Printf("✅ [WORKER %d] Snapshot %s terverifikasi\n", job.ID, snapshot.Name)
+		}
+	}
 
 	if !resultRclone.Success {
-		fmt.Printf("❌ [WORKER %d] Rclone GAGAL.\n", job.ID)
+		fmt.Printf("❌ [WORKER %d] Rclone GAGAL (%s).\n", job.ID, transferStatus)
 		finalResult = resultRclone
-		finalStatus = "FAIL_RCLONE"
-		s.handleJobCompletion(job, finalResult, finalStatus)
+		finalStatus = transferStatus
+		s.handleJobCompletion(job, run, finalResult, finalStatus)
 		return
 	}
 
 	lines := strings.Split(resultRclone.Output, "\n")
-	var transferStatus string
+	var transferSummary string
 	for _, line := range lines {
 		if strings.Contains(line, "Transferred:") {
-			transferStatus = strings.TrimSpace(line)
+			transferSummary = strings.TrimSpace(line)
 		}
 	}
 
-	if transferStatus != "" {
-		fmt.Printf("📊 [WORKER %d] Stats: %s\n", job.ID, transferStatus)
+	if transferSummary != "" {
+		fmt.Printf("📊 [WORKER %d] Stats: %s\n", job.ID, transferSummary)
 		// menyimpan  Log
-		resultRclone.Output = fmt.Sprintf("%s\n\n%s", transferStatus, resultRclone.Output)
+		resultRclone.Output = fmt.Sprintf("%s\n\n%s", transferSummary, resultRclone.Output)
 	}
 
 	// --- FASE 3: POST-SCRIPT ---
 	if job.PostScript != "" {
 		fmt.Printf("[WORKER %d] Menjalankan Post-Script...\n", job.ID)
+		s.Progress.Publish(newProgressEvent(job, run, PhasePostScript))
 		hardenedPostScript := fmt.Sprintf("set -eo pipefail; \n%s", job.PostScript)
 		postScriptArgs := []string{"bash", "-c", hardenedPostScript}
 
-		resultPost := ExecuteCliJob(postScriptArgs)
+		postCtx, cancelPost := phaseContext(ctx, "post-script", job.PostScriptTimeoutSec)
+		resultPost := ExecuteCliJobContext(postCtx, postScriptArgs)
+		postStatus := failureStatus(postCtx, "FAIL_POST_SCRIPT", "TIMEOUT_POST_SCRIPT")
+		cancelPost()
+		s.recordPhase(run, PhasePostScript, resultPost, postStatus)
 		if !resultPost.Success {
-			fmt.Printf("❌ [WORKER %d] Post-Script GAGAL.\n", job.ID)
+			fmt.Printf("❌ [WORKER %d] Post-Script GAGAL (%s).\n", job.ID, postStatus)
 			finalResult = resultPost
-			finalStatus = "FAIL_POST_SCRIPT"
-			s.handleJobCompletion(job, finalResult, finalStatus)
+			finalStatus = postStatus
+			s.handleJobCompletion(job, run, finalResult, finalStatus)
 			return // Hentikan eksekusi
 		}
 	}
 
 	// --- FASE 4: SUKSES ---
+	// Snapshot baru dihitung sukses setelah seluruh run (termasuk post-script) sukses & terverifikasi
+	if snapshotVerified {
+		if err := s.completeSnapshot(snapshot); err != nil {
+			fmt.Printf("⚠️ [WORKER %d] Gagal menandai snapshot %s COMPLETE, retensi dilewati: %v\n", job.ID, snapshot.Name, err)
+			snapshotVerified = false
+		}
+	}
+
+	// Manifest file dicatat untuk pencarian catalog; kegagalan di sini tidak menggagalkan run
+	if snapshotVerified {
+		if files, err := s.recordManifest(snapshot, job.SourcePath, snapshotFiles); err != nil {
+			fmt.Printf("⚠️ [WORKER %d] Gagal mencatat manifest %s: %v\n", job.ID, snapshot.Name, err)
+		} else {
+			fmt.Printf("📇 [WORKER %d] Manifest %s: %d file tercatat di catalog\n", job.ID, snapshot.Name, files)
+		}
+	}
+
+	// Retensi hanya setelah backup baru sukses & terverifikasi, agar run gagal tidak menghapus backup lama
+	if snapshotVerified {
+		fmt.Printf("[WORKER %d] 🔄 Checking for old backups...\n", job.ID)
+		if err := s.CleanupOldBackups(job); err != nil {
+			fmt.Printf("⚠️ [WORKER %d] Cleanup warning: %v\n", job.ID, err)
+		}
+	}
+
 	fmt.Printf("✅ [WORKER %d] Job Selesai.\n", job.ID)
 	finalResult = resultRclone // Log output Rclone jika sukses
 	finalStatus = "SUCCESS"
-	s.handleJobCompletion(job, finalResult, finalStatus)
+	s.handleJobCompletion(job, run, finalResult, finalStatus)
+}
+
+// recordSnapshot: Mencatat artefak run agar retensi hanya menyentuh snapshot milik job.
+// Snapshot dicatat INCOMPLETE (rclone bisa sudah menulis sebagian data) dan baru menjadi COMPLETE lewat
+// completeSnapshot, setelah run sukses & terverifikasi.
+func (s *backupServiceImpl) recordSnapshot(snapshot *models.Snapshot, result RcloneResult) {
+	if snapshot == nil {
+		return
+	}
+	snapshot.SizeBytes = result.TransferredBytes
+	snapshot.Status = "INCOMPLETE"
+	if err := s.SnapRepo.Create(snapshot); err != nil {
+		fmt.Printf("⚠️ [WORKER %d] Gagal mencatat snapshot %s: %v\n", snapshot.JobID, snapshot.Name, err)
+	}
+}
+
+// completeSnapshot: INCOMPLETE -> COMPLETE untuk snapshot run yang sukses (termasuk post-script) & terverifikasi.
+// Hanya snapshot COMPLETE yang dihitung retensi (min_successful) dan dipilih restore as_of.
+func (s *backupServiceImpl) completeSnapshot(snapshot *models.Snapshot) error {
+	if snapshot.ID == 0 {
+		return fmt.Errorf("snapshot %s belum tercatat di DB", snapshot.Name)
+	}
+	if err := s.SnapRepo.UpdateStatus(snapshot.ID, "COMPLETE"); err != nil {
+		return err
+	}
+	snapshot.Status = "COMPLETE"
+	return nil
+}
+
+// verifySnapshot: Memastikan snapshot di remote berisi semua yang di-upload run ini sebelum retensi boleh
+// berjalan. Listing remote (dengan hash) dikembalikan untuk dicatat sebagai manifest catalog.
+func (s *backupServiceImpl) verifySnapshot(ctx context.Context, snapshot *models.Snapshot, uploaded *transferFileTracker) ([]rcloneManifestEntry, error) {
+	files, err := listSnapshotFiles(ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySnapshotUpload(*snapshot, uploaded, files); err != nil {
+		return nil, err
+	}
+
+	if err := s.SnapRepo.MarkVerified(snapshot.ID, time.Now()); err != nil {
+		fmt.Printf("⚠️ [WORKER %d] Gagal menandai snapshot %d terverifikasi: %v\n", snapshot.JobID, snapshot.ID, err)
+	}
+	return files, nil
+}
+
+// listSnapshotFiles: Isi snapshot di remote (rclone lsjson -R --hash, file saja)
+func listSnapshotFiles(ctx context.Context, snapshot *models.Snapshot) ([]rcloneManifestEntry, error) {
+	remotePath := fmt.Sprintf("%s:%s/%s", snapshot.RemoteName, snapshot.DestinationPath, snapshot.Name)
+	output, err := exec.CommandContext(ctx, "rclone", "lsjson", "-R", "--hash", "--files-only", remotePath).Output()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca isi snapshot di remote: %w", err)
+	}
+	var files []rcloneManifestEntry
+	if err := json.Unmarshal(output, &files); err != nil {
+		return nil, fmt.Errorf("failed to parse rclone output: %w", err)
+	}
+	return files, nil
+}
+
+// verifySnapshotUpload: Mencocokkan isi snapshot di remote dengan file yang dilaporkan rclone ter-upload,
+// bukan dengan sumber live, sehingga file yang berubah/bertambah setelah transfer tidak menggagalkan verifikasi
+func verifySnapshotUpload(snapshot models.Snapshot, uploaded *transferFileTracker, remote []rcloneManifestEntry) error {
+	present := make(map[string]bool, len(remote))
+	for _, f := range remote {
+		if !f.IsDir {
+			present[f.Path] = true
+		}
+	}
+	if !snapshot.IsDir && len(present) != 1 {
+		return fmt.Errorf("snapshot file %s berisi %d file di remote", snapshot.Name, len(present))
+	}
+
+	// copyto melaporkan nama file tujuan, copy melaporkan path relatif terhadap folder snapshot
+	uploadedPath := func(object string) string {
+		if snapshot.IsDir {
+			return object
+		}
+		return snapshot.Name
+	}
+
+	// Error per file yang akhirnya sukses pada retry rclone tidak dihitung
+	var failed, missing []string
+	for object := range uploaded.failed {
+		if !uploaded.copied[object] {
+			failed = append(failed, object)
+		}
+	}
+	for object := range uploaded.copied {
+		if !present[uploadedPath(object)] {
+			missing = append(missing, object)
+		}
+	}
+	sort.Strings(failed)
+	sort.Strings(missing)
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d file gagal di-upload (mis. %s: %s)", len(failed), failed[0], uploaded.failed[failed[0]])
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d file yang di-upload tidak ada di remote (mis. %s)", len(missing), missing[0])
+	}
+	return nil
+}
+
+// rcloneManifestEntry: Satu baris output rclone lsjson -R --hash
+type rcloneManifestEntry struct {
+	Path    string            `json:"Path"`
+	Size    int64             `json:"Size"`
+	ModTime time.Time         `json:"ModTime"`
+	IsDir   bool              `json:"IsDir"`
+	Hashes  map[string]string `json:"Hashes"`
+}
+
+// manifestHashPreference: Urutan hash yang disimpan jika remote menyediakan beberapa jenis
+var manifestHashPreference = []string{"md5", "sha1", "sha256"}
+
+// pickManifestHash: Hash yang disimpan di catalog (preferensi di atas, lalu urutan abjad)
+func pickManifestHash(hashes map[string]string) (string, string) {
+	for _, hashType := range manifestHashPreference {
+		if val := hashes[hashType]; val != "" {
+			return hashType, val
+		}
+	}
+	types := make([]string, 0, len(hashes))
+	for hashType, val := range hashes {
+		if val != "" {
+			types = append(types, hashType)
+		}
+	}
+	if len(types) == 0 {
+		return "", ""
+	}
+	sort.Strings(types)
+	return types[0], hashes[types[0]]
+}
+
+// recordManifest: Mencatat semua file di snapshot (path, size, modtime, hash) ke catalog dari listing
+// remote saat verifikasi. sourcePath adalah path sumber saat backup, untuk memetakan file ke path aslinya.
+func (s *backupServiceImpl) recordManifest(snapshot *models.Snapshot, sourcePath string, files []rcloneManifestEntry) (int, error) {
+	if snapshot.ID == 0 {
+		return 0, fmt.Errorf("snapshot %s belum tercatat di DB", snapshot.Name)
+	}
+
+	entries := make([]models.CatalogEntry, 0, len(files))
+	for _, f := range files {
+		if f.IsDir {
+			continue
+		}
+		// Snapshot berupa file: satu-satunya entry adalah file sumber itu sendiri
+		original := sourcePath
+		if snapshot.IsDir {
+			original = filepath.Join(sourcePath, filepath.FromSlash(f.Path))
+		}
+		hashType, hash := pickManifestHash(f.Hashes)
+		entries = append(entries, models.CatalogEntry{
+			SnapshotID: snapshot.ID,
+			RunID:      snapshot.RunID,
+			JobID:      snapshot.JobID,
+			Path:       f.Path,
+			SourcePath: original,
+			SizeBytes:  f.Size,
+			ModTime:    f.ModTime,
+			HashType:   hashType,
+			Hash:       hash,
+		})
+	}
+
+	if err := s.CatalogRepo.ReplaceManifest(snapshot.ID, entries); err != nil {
+		return 0, err
+	}
+	if err := s.SnapRepo.MarkCataloged(snapshot.ID, len(entries), time.Now()); err != nil {
+		fmt.Printf("⚠️ [WORKER %d] Gagal menandai snapshot %d ter-catalog: %v\n", snapshot.JobID, snapshot.ID, err)
+	}
+	return len(entries), nil
+}
+
+// runTransferWithProgress: Menjalankan rclone (--use-json-log) sambil mem-publish progress
+// ke ProgressBus. Output yang disimpan ke Log adalah pesan yang bisa dibaca, bukan JSON mentah.
+func (s *backupServiceImpl) runTransferWithProgress(ctx context.Context, job models.ScheduledJob, run *models.JobRun, rcloneArgs []string, tracker *transferFileTracker) RcloneResult {
+	progress := newProgressEvent(job, run, PhaseTransfer)
+	s.Progress.Publish(progress)
+
+	var statsBytes, statsFiles int64 = -1, 0
+	result := ExecuteCliJobStream(ctx, rcloneArgs, func(line string) string {
+		entry, ok := parseRcloneJSONLine(line)
+		if !ok {
+			return line
+		}
+
+		if tracker != nil {
+			tracker.observe(entry)
+			// Baris per file backup hanya untuk verifikasi, tidak disimpan ke Log (bisa ribuan baris)
+			if job.OperationMode == "BACKUP" && entry.Level == "info" && entry.Object != "" {
+				return ""
+			}
+		}
+
+		text := strings.TrimSpace(entry.Msg)
+		switch {
+		case entry.Stats != nil:
+			progress.applyStats(entry.Stats)
+			statsBytes = entry.Stats.Bytes
+			statsFiles = entry.Stats.Transfers
+			s.Progress.Publish(progress)
+		case entry.Level == "error":
+			if entry.Object != "" {
+				text = fmt.Sprintf("%s: %s", entry.Object, text)
+			}
+			progress.LastError = text
+			s.Progress.Publish(progress)
+		}
+		return text
+	})
+
+	// Stats JSON lebih akurat daripada parsing teks "Transferred:"
+	if statsBytes >= 0 {
+		result.TransferredBytes = statsBytes
+	}
+	result.TransferredFiles = statsFiles
+	return result
+}
+
+// restoreRemoteSource: remote:path sumber untuk job RESTORE
+func restoreRemoteSource(job models.ScheduledJob) string {
+	return fmt.Sprintf("%s:%s", job.RemoteName, job.SourcePath)
+}
+
+// recordRestoreResults: Menyimpan hasil per file restore ke JobRun
+func (s *backupServiceImpl) recordRestoreResults(ctx context.Context, job models.ScheduledJob, run *models.JobRun, o *RestoreOptions, tracker *transferFileTracker, transferOK bool) {
+	remoteSrc := restoreRemoteSource(job)
+	// Listing tetap dijalankan walau run dibatalkan, agar hasil per file tetap tercatat
+	listCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), restoreListTimeout)
+	defer cancel()
+	report := collectRestoreResults(listCtx, remoteSrc, o, tracker, transferOK)
+	fmt.Printf("[WORKER %d] 📋 Restore: %d restored, %d unchanged, %d renamed, %d failed, %d not restored, %d not found\n",
+		job.ID, report.Restored, report.Unchanged, report.Renamed, report.Failed, report.NotRestored, report.NotFound)
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		fmt.Printf("⚠️ [WORKER %d] Gagal encode hasil restore: %v\n", job.ID, err)
+		return
+	}
+	run.RestoreResults = string(encoded)
+}
+
+// startJobRun: Membuat baris JobRun berstatus RUNNING untuk eksekusi ini
+func (s *backupServiceImpl) startJobRun(job models.ScheduledJob, opts RunOptions) *models.JobRun {
+	trigger := opts.Trigger
+	if trigger == "" {
+		trigger = TriggerManual
+	}
+	attempt := opts.Attempt
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	run := &models.JobRun{
+		JobName:       job.JobName,
+		OperationMode: job.OperationMode,
+		RemoteName:    job.RemoteName,
+		TriggerSource: trigger,
+		Attempt:       attempt,
+		ScheduledFor:  opts.ScheduledFor,
+		Status:        "RUNNING",
+		StartedAt:     time.Now(),
+	}
+	if job.ID != 0 {
+		jobID := job.ID
+		run.JobID = &jobID
+	}
+	if opts.WorkflowRunID != 0 {
+		wfRunID := opts.WorkflowRunID
+		run.WorkflowRunID = &wfRunID
+	}
+	if opts.Restore != nil && opts.Restore.RecordID != 0 {
+		recordID := opts.Restore.RecordID
+		run.RestoreRecordID = &recordID
+	}
+	if job.PreScript == "" {
+		run.PreScriptStatus = "SKIPPED"
+	}
+	if job.PostScript == "" {
+		run.PostScriptStatus = "SKIPPED"
+	}
+
+	if err := s.RunRepo.Create(run); err != nil {
+		fmt.Printf("⚠️ [WORKER %d] Gagal mencatat JobRun: %v\n", job.ID, err)
+	}
+	return run
+}
+
+// recordPhase: Menyimpan status & durasi satu fase ke JobRun
+func (s *backupServiceImpl) recordPhase(run *models.JobRun, phase string, result RcloneResult, failStatus string) {
+	status := "SUCCESS"
+	if !result.Success {
+		status = failStatus
+	}
+	durationMs := result.Duration.Milliseconds()
+
+	switch phase {
+	case PhasePreScript:
+		run.PreScriptStatus, run.PreScriptDurationMs = status, durationMs
+	case PhaseTransfer:
+		run.TransferStatus, run.TransferDurationMs = status, durationMs
+		run.TransferredBytes = result.TransferredBytes
+		run.TransferredFiles = result.TransferredFiles
+	case PhasePostScript:
+		run.PostScriptStatus, run.PostScriptDurationMs = status, durationMs
+	}
+}
+
+// maxRunErrorLen: Batas panjang ErrorMessage JobRun (kolom TEXT), diambil bagian akhirnya
+const maxRunErrorLen = 16000
+
+// finishJobRun: Menyimpan status akhir JobRun
+func (s *backupServiceImpl) finishJobRun(run *models.JobRun, result RcloneResult, status string) {
+	now := time.Now()
+	run.Status = status
+	run.FinishedAt = &now
+	run.DurationSec = int(now.Sub(run.StartedAt).Seconds())
+
+	if status != "SUCCESS" {
+		msg := result.ErrorMsg
+		if len(msg) > maxRunErrorLen {
+			msg = "..." + msg[len(msg)-maxRunErrorLen:]
+		}
+		run.ErrorMessage = msg
+	}
+
+	if err := s.RunRepo.Save(run); err != nil {
+		fmt.Printf("⚠️ [WORKER] Gagal menyimpan status akhir run %d: %v\n", run.ID, err)
+	}
 }
 
 // ----------------------------------------------------
@@ -274,7 +905,7 @@ func (s *backupServiceImpl) executeJobLifecycle(job models.ScheduledJob) {
 // ----------------------------------------------------
 
 // buildRcloneArgs: Menyusun command Rclone
-func (s *backupServiceImpl) buildRcloneArgs(job models.ScheduledJob, runtimeDestPath string) []string {
+func (s *backupServiceImpl) buildRcloneArgs(job models.ScheduledJob, runtimeDestPath string, opts RunOptions) []string {
 	isRestore := job.OperationMode == "RESTORE"
 	command := strings.ToLower(job.RcloneMode)
 
@@ -286,15 +917,40 @@ func (s *backupServiceImpl) buildRcloneArgs(job models.ScheduledJob, runtimeDest
 	}
 
 	var SourcePath, Destination string
+	var filterArgs []string
 
 	if isRestore {
-		SourcePath = fmt.Sprintf("%s:%s", job.RemoteName, job.SourcePath)
+		SourcePath = restoreRemoteSource(job)
 		Destination = job.DestinationPath
 		command = "copy"
+		// Snapshot berupa file dipulihkan ke path file tujuan (tanpa timestamp di nama)
+		if opts.Restore != nil && opts.Restore.SingleFile {
+			command = "copyto"
+		}
+		// Restore selektif: hanya path/pola terpilih
+		if opts.Restore.IsSelective() {
+			filterArgs = restoreFilterArgs(opts.Restore)
+		}
+		if opts.Restore != nil {
+			filterArgs = append(filterArgs, conflictPolicyArgs(opts.Restore.ConflictPolicy)...)
+			// Safety snapshot: file yang akan ditimpa dipindahkan ke folder quarantine
+			if opts.Restore.QuarantinePath != "" {
+				filterArgs = append(filterArgs, "--backup-dir", opts.Restore.QuarantinePath)
+			}
+		}
+		// -v agar setiap file yang disalin tercatat di log (hasil per file)
+		if opts.Restore.tracksFiles() {
+			filterArgs = append(filterArgs, "-v")
+		}
 	} else {
 		SourcePath = job.SourcePath
 		Destination = fmt.Sprintf("%s:%s", job.RemoteName, runtimeDestPath)
 
+		// -v agar setiap file yang di-upload tercatat untuk verifikasi snapshot
+		if job.OperationMode == "BACKUP" && job.RcloneMode == "copy" {
+			filterArgs = append(filterArgs, "-v")
+		}
+
 		switch command {
 		case "sync":
 			command = "sync"
@@ -316,12 +972,14 @@ func (s *backupServiceImpl) buildRcloneArgs(job models.ScheduledJob, runtimeDest
 		Destination,
 		"--checksum",
 		"--no-traverse",
-		"--progress",    //Tampilkan progress dengan stats
-		"--stats", "5s", //Print stats setiap 5 detik
-		"--stats-log-level", "INFO", //Change to INFO level
+		"--use-json-log", //Log per baris dalam JSON (di-stream ke ProgressBus)
+		"--stats", "5s",  //Print stats setiap 5 detik
+		"--stats-log-level", "NOTICE", //Stats tampil tanpa -v
 		"--human-readable",
 	}
 
+	args = append(args, filterArgs...)
+
 	if command == "sync" {
 		args = append(args, "--delete-during")
 		fmt.Printf("[buildRcloneArgs] SYNC mode detected: adding --delete-during flag\n")
@@ -331,10 +989,12 @@ func (s *backupServiceImpl) buildRcloneArgs(job models.ScheduledJob, runtimeDest
 }
 
 // handleJobCompletion: Logika Logging dan Final Status Update
-func (s *backupServiceImpl) handleJobCompletion(job models.ScheduledJob, result RcloneResult, status string) {
+func (s *backupServiceImpl) handleJobCompletion(job models.ScheduledJob, run *models.JobRun, result RcloneResult, status string) {
 	LogMutex.Lock()
 	defer LogMutex.Unlock()
 
+	s.finishJobRun(run, result, status)
+
 	// --- 1. LOGIKA PESAN LOG ---
 	// Jika Sukses: Ambil output bersih (Size | Time)
 	// Jika Gagal: Ambil output + Error message
@@ -342,6 +1002,9 @@ func (s *backupServiceImpl) handleJobCompletion(job models.ScheduledJob, result
 	if status != "SUCCESS" {
 		logMessage = result.ErrorMsg
 	}
+	if job.RetryMaxAttempts > 1 {
+		logMessage = fmt.Sprintf("[Attempt %d/%d] %s", run.Attempt, job.RetryMaxAttempts, logMessage)
+	}
 
 	// --- 2. SIMPAN KE TABEL LOGS (Detail Status) ---
 	// Di sini kita simpan status spesifik (misal: FAIL_RCLONE)
@@ -353,22 +1016,40 @@ func (s *backupServiceImpl) handleJobCompletion(job models.ScheduledJob, result
 		Message:          logMessage,
 		DurationSec:      int(result.Duration.Seconds()),
 		TransferredBytes: result.TransferredBytes,
+		Attempt:          run.Attempt,
 		Timestamp:        time.Now(),
 	}
 
 	if job.ID != 0 {
 		newLog.JobID = &job.ID
 	}
+	if run.ID != 0 {
+		newLog.RunID = &run.ID
+	}
 
-	fmt.Printf("[LOG DEBUG] Saving ID: %d | Status: %s | Bytes: %d\n", job.ID, status, result.TransferredBytes)
+	// Event terakhir untuk subscriber progress (SSE)
+	base := newProgressEvent(job, run, PhaseDone)
+	doneEvent, _ := s.Progress.snapshot(base.key())
+	doneEvent.JobID = base.JobID
+	doneEvent.RunID = base.RunID
+	doneEvent.RestoreID = base.RestoreID
+	doneEvent.JobName = base.JobName
+	doneEvent.Phase = PhaseDone
+	doneEvent.Status = status
+	s.Progress.Publish(doneEvent)
+
+	fmt.Printf("[LOG DEBUG] Saving ID: %d | Status: %s | Attempt: %d | Bytes: %d\n", job.ID, status, run.Attempt, result.TransferredBytes)
 	s.LogRepo.CreateLog(newLog)
 
 	if job.ID != 0 {
 		var dbStatus string
 
-		if status == "SUCCESS" {
+		switch {
+		case status == "SUCCESS":
 			dbStatus = "COMPLETED"
-		} else {
+		case status == "CANCELLED", status == "INTERRUPTED", strings.HasPrefix(status, "TIMEOUT_"):
+			dbStatus = status
+		default:
 			dbStatus = "FAILED"
 		}
 
@@ -379,7 +1060,7 @@ func (s *backupServiceImpl) handleJobCompletion(job models.ScheduledJob, result
 	if status == "SUCCESS" {
 		// Parse stats hanya untuk print terminal yang cantik (opsional)
 		stats := parseRcloneStats(result.Output)
-		fmt.Printf("✅ [COMPLETE] Job %d: Transferred %.2f GB in %d seconds (Speed: %s)\n",
+		fmt.Printf("✅ [COMPLETE] Job %d (%s): Transferred %.2f GB in %d seconds (Speed: %s)\n",
 			job.ID,
 			job.RcloneMode,
 			float64(result.TransferredBytes)/1073741824.0,
@@ -450,6 +1131,7 @@ func (s *backupServiceImpl) DeleteJob(JobID uint) error {
 	if err := s.JobRepo.DeleteJob(JobID); err != nil {
 		return fmt.Errorf("gagal menghapus job ID %d: %w", JobID, err)
 	}
+	s.notifyJobChanged(JobID, nil)
 	return nil
 }
 
@@ -460,6 +1142,53 @@ func (s *backupServiceImpl) GetJobByID(jobID uint) (*models.ScheduledJob, error)
 	}
 	return job, nil
 }
+
+// PauseJob: Menonaktifkan jadwal job (resumeAt nil = sampai di-resume manual).
+// Run yang sedang berjalan tidak dihentikan dan trigger manual tetap bisa.
+func (s *backupServiceImpl) PauseJob(jobID uint, resumeAt *time.Time) error {
+	if _, err := s.JobRepo.FindJobByID(jobID); err != nil {
+		return err
+	}
+	if err := s.JobRepo.UpdateJobActivity(jobID, false, resumeAt); err != nil {
+		return fmt.Errorf("gagal pause job %d: %w", jobID, err)
+	}
+
+	if resumeAt != nil {
+		fmt.Printf("[AUDIT] Job %d di-pause hingga %s\n", jobID, resumeAt.Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Printf("[AUDIT] Job %d di-pause\n", jobID)
+	}
+
+	if fresh, err := s.JobRepo.FindJobByID(jobID); err == nil {
+		s.notifyJobChanged(jobID, fresh)
+	}
+	return nil
+}
+
+// ResumeJob: Mengaktifkan kembali jadwal job. Jadwal selama pause tidak disusul.
+func (s *backupServiceImpl) ResumeJob(jobID uint) error {
+	job, err := s.JobRepo.FindJobByID(jobID)
+	if err != nil {
+		return err
+	}
+	if job.IsActive {
+		return nil
+	}
+
+	if err := s.JobRepo.UpdateJobActivity(jobID, true, nil); err != nil {
+		return fmt.Errorf("gagal resume job %d: %w", jobID, err)
+	}
+	// Basis misfire digeser ke sekarang agar jadwal selama pause tidak dianggap terlewat
+	if err := s.JobRepo.UpdateLastScheduledAt(jobID, time.Now().Truncate(time.Second)); err != nil {
+		fmt.Printf("⚠️ [RESUME] Gagal update last_scheduled_at Job %d: %v\n", jobID, err)
+	}
+	fmt.Printf("[AUDIT] Job %d di-resume\n", jobID)
+
+	if fresh, err := s.JobRepo.FindJobByID(jobID); err == nil {
+		s.notifyJobChanged(jobID, fresh)
+	}
+	return nil
+}
 func (s *backupServiceImpl) UpdateJob(jobID uint, updatedJob *models.ScheduledJob) error {
 	fmt.Printf("[UPDATE] Memperbarui Job ID: %d\n", jobID)
 
@@ -496,10 +1225,40 @@ func (s *backupServiceImpl) UpdateJob(jobID uint, updatedJob *models.ScheduledJo
 	updates["post_script"] = updatedJob.PostScript
 
 	// ✅ Schedule cron bisa kosong (untuk ubah jadi manual job)
+	if err := ValidateCronExpression(updatedJob.ScheduleCron); err != nil {
+		return err
+	}
 	updates["schedule_cron"] = updatedJob.ScheduleCron
 
 	updates["updated_at"] = time.Now()
 
+	// ✅ Timeout & retry selalu ditulis, handler sudah mengisi nilai lama jika tidak dikirim
+	updates["pre_script_timeout_sec"] = updatedJob.PreScriptTimeoutSec
+	updates["transfer_timeout_sec"] = updatedJob.TransferTimeoutSec
+	updates["post_script_timeout_sec"] = updatedJob.PostScriptTimeoutSec
+	updates["retry_max_attempts"] = updatedJob.RetryMaxAttempts
+	updates["retry_initial_delay_sec"] = updatedJob.RetryInitialDelaySec
+	updates["retry_multiplier"] = updatedJob.RetryMultiplier
+	updates["retry_on_statuses"] = updatedJob.RetryOnStatuses
+	updates["requeue_on_interrupt"] = updatedJob.RequeueOnInterrupt
+	updates["max_lateness_sec"] = updatedJob.MaxLatenessSec
+	updates["time_zone"] = updatedJob.TimeZone
+	updates["blackout_window_ids"] = updatedJob.BlackoutWindowIDs
+	updates["keep_last"] = updatedJob.KeepLast
+	updates["keep_hourly"] = updatedJob.KeepHourly
+	updates["keep_daily"] = updatedJob.KeepDaily
+	updates["keep_weekly"] = updatedJob.KeepWeekly
+	updates["keep_monthly"] = updatedJob.KeepMonthly
+	updates["keep_yearly"] = updatedJob.KeepYearly
+	updates["keep_within"] = updatedJob.KeepWithin
+	updates["retention_dry_run"] = updatedJob.RetentionDryRun
+	if updatedJob.MinSuccessfulSnapshots > 0 {
+		updates["min_successful_snapshots"] = updatedJob.MinSuccessfulSnapshots
+	}
+	if updatedJob.MisfirePolicy != "" {
+		updates["misfire_policy"] = updatedJob.MisfirePolicy
+	}
+
 	if updatedJob.MaxRetention > 0 {
 		if updatedJob.MaxRetention > 100 {
 			return fmt.Errorf("max retention tidak boleh lebih dari 100")
@@ -517,90 +1276,391 @@ func (s *backupServiceImpl) UpdateJob(jobID uint, updatedJob *models.ScheduledJo
 		return fmt.Errorf("gagal update job: %w", err)
 	}
 
+	// Muat ulang agar listener (scheduler) melihat konfigurasi terbaru
+	if fresh, err := s.JobRepo.FindJobByID(jobID); err == nil {
+		s.notifyJobChanged(jobID, fresh)
+	}
+
 	fmt.Printf("[UPDATE] Job %d berhasil diperbarui (%d fields)\n", jobID, len(updates)-1)
 	return nil
 }
 
-// CleanupOldBackups - UPDATED untuk menerima maxRetention sebagai parameter
-func (s *backupServiceImpl) CleanupOldBackups(remoteName, destinationPath string, maxRetention int) error {
-	// Validate maxRetention
-	if maxRetention < 1 {
-		maxRetention = 10
-		fmt.Printf("[Round Robin] Invalid maxRetention (%d), using default: %d\n", maxRetention, maxRetention)
-	}
+// RetentionPlan: Hasil perencanaan retensi satu job terhadap isi folder tujuan
+type RetentionPlan struct {
+	Policy    RetentionPolicy     `json:"policy"`
+	Decisions []RetentionDecision `json:"decisions"` // Snapshot milik job (sisa transfer gagal selalu dihapus, tidak dihitung)
+	Foreign   []RcloneFileInfo    `json:"foreign"`   // Item yang tidak dibuat job ini, tidak pernah dihapus
+	Legacy    []RcloneFileInfo    `json:"legacy"`    // Bagian Foreign yang cocok pola nama job, menunggu diklaim user
+	Missing   []BackupSnapshot    `json:"missing"`   // Tercatat tapi sudah tidak ada di remote
+}
 
-	fmt.Printf("[Round Robin] Checking backups in %s:%s (Max: %d)...\n",
-		remoteName, destinationPath, maxRetention)
+// planRetention: Mencocokkan isi DestinationPath dengan snapshot yang tercatat untuk job,
+// lalu menerapkan kebijakan retensi hanya pada snapshot milik job tersebut
+func (s *backupServiceImpl) planRetention(job models.ScheduledJob) (*RetentionPlan, error) {
+	plan := &RetentionPlan{Policy: JobRetentionPolicy(job)}
+	if !HasRetentionRules(job) && plan.Policy.KeepLast < 1 {
+		// Kebijakan lama (max_retention)
+		plan.Policy.KeepLast = 10
+		fmt.Printf("[Round Robin] Invalid maxRetention (%d), using default: %d\n", job.MaxRetention, plan.Policy.KeepLast)
+	}
 
 	// List file dari remote menggunakan rclone lsjson
-	listCmd := fmt.Sprintf("rclone lsjson %s:%s", remoteName, destinationPath)
-	output, err := exec.Command("bash", "-c", listCmd).Output()
+	output, err := exec.Command("rclone", "lsjson", fmt.Sprintf("%s:%s", job.RemoteName, job.DestinationPath)).Output()
 	if err != nil {
-		return fmt.Errorf("failed to list remote files: %w", err)
+		return nil, fmt.Errorf("failed to list remote files: %w", err)
 	}
 
 	var files []RcloneFileInfo
 	if err := json.Unmarshal(output, &files); err != nil {
-		return fmt.Errorf("failed to parse rclone output: %w", err)
+		return nil, fmt.Errorf("failed to parse rclone output: %w", err)
 	}
 
-	backupItems := files
-	currentCount := len(backupItems)
-	fmt.Printf("[Round Robin] Found %d backup items (limit: %d)\n", currentCount, maxRetention)
+	tracked, err := s.SnapRepo.FindLiveByJobID(job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil snapshot job %d: %w", job.ID, err)
+	}
+	byName := make(map[string]models.Snapshot)
+	for _, snap := range tracked {
+		// Snapshot di remote/folder lama (sebelum DestinationPath diubah) tidak ikut dievaluasi
+		if snap.RemoteName == job.RemoteName && snap.DestinationPath == job.DestinationPath {
+			byName[snap.Name] = snap
+		}
+	}
 
-	// Jika tidak perlu cleanup
-	if currentCount < maxRetention {
-		fmt.Printf("[Round Robin] No cleanup needed (%d/%d)\n", currentCount, maxRetention)
-		return nil
+	now := time.Now()
+	inUse, err := s.snapshotsInUse(now)
+	if err != nil {
+		return nil, err
 	}
 
-	// Sort berdasarkan waktu modifikasi (ascending = oldest first)
-	sort.Slice(backupItems, func(i, j int) bool {
-		return backupItems[i].ModTime.Before(backupItems[j].ModTime)
-	})
+	var complete, incomplete []BackupSnapshot
+	var pinned []RetentionDecision
+	for _, f := range files {
+		snap, ok := byName[f.Name]
+		if !ok {
+			// Nama yang cocok pola job bisa saja milik job lain dengan sumber bernama sama:
+			// tidak pernah diadopsi otomatis, hanya ditawarkan untuk diklaim
+			plan.Foreign = append(plan.Foreign, f)
+			if _, ok := legacySnapshotTime(job.SourcePath, f.Name); ok {
+				plan.Legacy = append(plan.Legacy, f)
+			}
+			continue
+		}
+		delete(byName, f.Name)
+
+		item := BackupSnapshot{
+			SnapshotID: snap.ID,
+			RunID:      snap.RunID,
+			Name:       f.Name,
+			Time:       snap.SnapshotAt,
+			Size:       f.Size,
+			IsDir:      f.IsDir,
+		}
+		if t, ok := ParseSnapshotTime(f.Name); ok {
+			item.Time = t
+		}
+		if item.Size < 0 {
+			item.Size = snap.SizeBytes
+		}
+		// Snapshot yang di-pin selalu dipertahankan dan tidak dihitung oleh aturan retensi
+		if IsPinActive(snap, now) {
+			item.Pinned = true
+			pinned = append(pinned, RetentionDecision{Snapshot: item, Keep: true, Reasons: []string{pinReason(snap)}})
+			continue
+		}
+		// Snapshot yang sedang/akan di-restore diperlakukan seperti pin selama restore belum selesai
+		if reason, ok := inUse[snap.ID]; ok {
+			pinned = append(pinned, RetentionDecision{Snapshot: item, Keep: true, Reasons: []string{reason}})
+			continue
+		}
+		if snap.Status == "INCOMPLETE" {
+			incomplete = append(incomplete, item)
+			continue
+		}
+		complete = append(complete, item)
+	}
+	for _, snap := range byName {
+		plan.Missing = append(plan.Missing, BackupSnapshot{
+			SnapshotID: snap.ID,
+			RunID:      snap.RunID,
+			Name:       snap.Name,
+			Time:       snap.SnapshotAt,
+			Size:       snap.SizeBytes,
+			IsDir:      snap.IsDir,
+		})
+	}
+
+	plan.Decisions = buildRetentionDecisions(plan.Policy, pinned, complete, incomplete)
+	return plan, nil
+}
+
+// snapshotsInUse: Snapshot yang dipakai restore QUEUED/RUNNING atau preview READY yang belum kedaluwarsa
+func (s *backupServiceImpl) snapshotsInUse(now time.Time) (map[uint]string, error) {
+	restoring, err := s.RecordRepo.FindSnapshotIDsByStatus([]string{RestoreStatusQueued, RestoreStatusRunning})
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil snapshot yang sedang di-restore: %w", err)
+	}
+	previewed, err := s.PreviewRepo.FindReadySnapshotIDs(now)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil snapshot dengan preview restore: %w", err)
+	}
+	return inUseReasons(restoring, previewed), nil
+}
 
-	// Hitung berapa yang perlu dihapus
-	// Jika ada 12 backup dan maxRetention=10, hapus 12-10+1 = 3
-	itemsToDelete := currentCount - maxRetention + 1
-	fmt.Printf("[Round Robin] Deleting %d oldest backup(s)...\n", itemsToDelete)
+// ErrInvalidClaim: Item yang diklaim bukan backup lama job ini (tidak ada, sudah tercatat, atau nama tidak cocok)
+var ErrInvalidClaim = errors.New("item tidak bisa diklaim")
 
-	var deletedItems []string
-	for i := 0; i < itemsToDelete && i < len(backupItems); i++ {
-		itemToDelete := backupItems[i]
-		fullPath := fmt.Sprintf("%s:%s/%s", remoteName, destinationPath, itemToDelete.Name)
+// ErrClaimAmbiguous: Nama item juga cocok dengan pola backup job lain di folder tujuan yang sama
+var ErrClaimAmbiguous = errors.New("item juga cocok dengan job lain")
 
-		var deleteCmd string
-		if itemToDelete.IsDir {
-			deleteCmd = fmt.Sprintf("rclone purge %s", fullPath)
-		} else {
-			deleteCmd = fmt.Sprintf("rclone delete %s", fullPath)
+// ClaimLegacySnapshots: Mencatat backup lama (nama sumber + timestamp, tanpa baris snapshot) sebagai snapshot
+// COMPLETE milik job atas permintaan user, sehingga mulai dihitung & dihapus retensi. Nama yang juga cocok
+// dengan job lain di remote & folder tujuan yang sama ditolak kecuali force.
+func (s *backupServiceImpl) ClaimLegacySnapshots(jobID uint, names []string, force bool) ([]models.Snapshot, error) {
+	job, err := s.JobRepo.FindJobByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.OperationMode != "BACKUP" || job.RcloneMode != "copy" {
+		return nil, fmt.Errorf("job %d: %w", jobID, ErrRetentionNotApplicable)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: names wajib diisi", ErrInvalidClaim)
+	}
+
+	plan, err := s.planRetention(*job)
+	if err != nil {
+		return nil, err
+	}
+	legacy := make(map[string]RcloneFileInfo, len(plan.Legacy))
+	for _, f := range plan.Legacy {
+		legacy[f.Name] = f
+	}
+	jobs, err := s.JobRepo.FindAllJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Semua nama divalidasi dulu agar klaim tidak tercatat sebagian
+	items := make([]RcloneFileInfo, 0, len(names))
+	for _, name := range names {
+		f, ok := legacy[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s bukan backup lama job %d yang belum tercatat", ErrInvalidClaim, name, job.ID)
+		}
+		if others := legacyClaimants(jobs, *job, name); len(others) > 0 && !force {
+			return nil, fmt.Errorf("%w: %s juga cocok dengan job %v", ErrClaimAmbiguous, name, others)
+		}
+		items = append(items, f)
+	}
+
+	claimed := make([]models.Snapshot, 0, len(items))
+	for _, f := range items {
+		snap, err := s.adoptLegacySnapshot(*job, f)
+		if err != nil {
+			return claimed, err
 		}
+		claimed = append(claimed, *snap)
+	}
+	return claimed, nil
+}
 
-		// Execute delete command
-		if err := exec.Command("bash", "-c", deleteCmd).Run(); err != nil {
-			fmt.Printf("⚠️  [Round Robin] Failed to delete %s: %v\n", itemToDelete.Name, err)
+// legacyClaimants: Job lain (BACKUP copy, remote & folder tujuan sama) yang pola nama backup-nya juga cocok
+func legacyClaimants(jobs []models.ScheduledJob, job models.ScheduledJob, name string) []uint {
+	var ids []uint
+	for _, other := range jobs {
+		if other.ID == job.ID || other.OperationMode != "BACKUP" || other.RcloneMode != "copy" {
+			continue
+		}
+		if other.RemoteName != job.RemoteName || filepath.Clean(other.DestinationPath) != filepath.Clean(job.DestinationPath) {
 			continue
 		}
+		if _, ok := legacySnapshotTime(other.SourcePath, name); ok {
+			ids = append(ids, other.ID)
+		}
+	}
+	return ids
+}
+
+// adoptLegacySnapshot: Mencatat item yang diklaim sebagai snapshot COMPLETE, dengan waktu dari nama
+func (s *backupServiceImpl) adoptLegacySnapshot(job models.ScheduledJob, f RcloneFileInfo) (*models.Snapshot, error) {
+	snapshotAt, ok := legacySnapshotTime(job.SourcePath, f.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: nama %s tidak cocok pola backup job %d", ErrInvalidClaim, f.Name, job.ID)
+	}
+	// Item yang pernah tercatat (mis. sudah diklaim job lain) tidak diadopsi
+	exists, err := s.SnapRepo.ExistsAt(job.RemoteName, job.DestinationPath, f.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s sudah tercatat sebagai snapshot", ErrInvalidClaim, f.Name)
+	}
 
-		deletedItems = append(deletedItems, itemToDelete.Name)
+	snap := &models.Snapshot{
+		JobID:           job.ID,
+		RemoteName:      job.RemoteName,
+		DestinationPath: job.DestinationPath,
+		Name:            f.Name,
+		SourcePath:      job.SourcePath,
+		IsDir:           f.IsDir,
+		Status:          "COMPLETE",
+		SnapshotAt:      snapshotAt,
+	}
+	if f.Size > 0 {
+		snap.SizeBytes = f.Size
+	}
+	if err := s.SnapRepo.Create(snap); err != nil {
+		return nil, fmt.Errorf("gagal mengadopsi backup lama %s: %w", f.Name, err)
+	}
+	fmt.Printf("[RETENTION] 📥 Job %d: backup lama %s diklaim sebagai snapshot %d (%s)\n",
+		job.ID, f.Name, snap.ID, snapshotAt.Format(time.RFC3339))
+	return snap, nil
+}
 
-		// Format output log
-		itemType := "file"
-		sizeStr := fmt.Sprintf("%.2f MB", float64(itemToDelete.Size)/(1024*1024))
-		if itemToDelete.IsDir {
-			itemType = "folder"
-			sizeStr = "folder"
+// ErrRetentionNotApplicable: Retensi hanya berlaku untuk job BACKUP mode copy (snapshot bertimestamp)
+var ErrRetentionNotApplicable = errors.New("retensi hanya berlaku untuk job BACKUP mode copy")
+
+// RetentionPreviewDTO: Output GET /api/v1/jobs/:id/retention/preview
+type RetentionPreviewDTO struct {
+	JobID           uint                `json:"job_id"`
+	JobName         string              `json:"job_name"`
+	RemoteName      string              `json:"remote_name"`
+	DestinationPath string              `json:"destination_path"`
+	Policy          RetentionPolicy     `json:"policy"` // Kebijakan efektif untuk run berikutnya
+	Keep            []RetentionDecision `json:"keep"`
+	Delete          []RetentionDecision `json:"delete"`
+	Foreign         []RcloneFileInfo    `json:"foreign"`
+	Legacy          []RcloneFileInfo    `json:"legacy"` // Foreign yang bisa diklaim (POST /jobs/:id/snapshots/claim)
+	Missing         []BackupSnapshot    `json:"missing"`
+	BytesReclaimed  int64               `json:"bytes_reclaimed"`
+	GeneratedAt     time.Time           `json:"generated_at"`
+}
+
+// PreviewRetention: Keputusan retensi saat ini tanpa menghapus apa pun
+func (s *backupServiceImpl) PreviewRetention(jobID uint) (*RetentionPreviewDTO, error) {
+	job, err := s.JobRepo.FindJobByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.OperationMode != "BACKUP" || job.RcloneMode != "copy" {
+		return nil, fmt.Errorf("job %d: %w", jobID, ErrRetentionNotApplicable)
+	}
+
+	plan, err := s.planRetention(*job)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &RetentionPreviewDTO{
+		JobID:           job.ID,
+		JobName:         job.JobName,
+		RemoteName:      job.RemoteName,
+		DestinationPath: job.DestinationPath,
+		Policy:          plan.Policy,
+		Keep:            []RetentionDecision{},
+		Delete:          []RetentionDecision{},
+		Foreign:         plan.Foreign,
+		Legacy:          plan.Legacy,
+		Missing:         plan.Missing,
+		GeneratedAt:     time.Now(),
+	}
+	if preview.Foreign == nil {
+		preview.Foreign = []RcloneFileInfo{}
+	}
+	if preview.Legacy == nil {
+		preview.Legacy = []RcloneFileInfo{}
+	}
+	if preview.Missing == nil {
+		preview.Missing = []BackupSnapshot{}
+	}
+	for _, d := range plan.Decisions {
+		if d.Keep {
+			preview.Keep = append(preview.Keep, d)
+			continue
 		}
+		preview.Delete = append(preview.Delete, d)
+		if d.Snapshot.Size > 0 {
+			preview.BytesReclaimed += d.Snapshot.Size
+		}
+	}
+	return preview, nil
+}
 
-		fmt.Printf("🗑️  [Round Robin] Deleted %s: %s (date: %s, size: %s)\n",
-			itemType,
-			itemToDelete.Name,
-			itemToDelete.ModTime.Format("2006-01-02 15:04:05"),
-			sizeStr,
-		)
+// CleanupOldBackups: Menerapkan kebijakan retensi job ke snapshot yang dibuat job itu sendiri.
+// Item lain di DestinationPath dilaporkan sebagai foreign dan tidak pernah dihapus.
+func (s *backupServiceImpl) CleanupOldBackups(job models.ScheduledJob) error {
+	fmt.Printf("[Round Robin] Checking backups in %s:%s...\n", job.RemoteName, job.DestinationPath)
+
+	plan, err := s.planRetention(job)
+	if err != nil {
+		return err
 	}
+	fmt.Printf("[Round Robin] Policy %+v: %d snapshot, %d foreign, %d missing\n",
+		plan.Policy, len(plan.Decisions), len(plan.Foreign), len(plan.Missing))
 
-	fmt.Printf("✅ [Round Robin] Cleanup complete. Deleted %d item(s). Space available for new backup.\n",
-		len(deletedItems))
+	for _, f := range plan.Foreign {
+		fmt.Printf("[Round Robin] Foreign item %s (bukan milik job %d), tidak disentuh\n", f.Name, job.ID)
+	}
+	for _, snap := range plan.Missing {
+		fmt.Printf("⚠️  [Round Robin] Snapshot %s (run %d) tidak ditemukan di remote\n", snap.Name, snap.RunID)
+		if err := s.SnapRepo.UpdateStatus(snap.SnapshotID, "MISSING"); err != nil {
+			fmt.Printf("⚠️  [Round Robin] Gagal update status snapshot %d: %v\n", snap.SnapshotID, err)
+		}
+	}
+
+	deleted := 0
+	for _, d := range plan.Decisions {
+		reasons := strings.Join(d.Reasons, ", ")
+		if d.Keep {
+			fmt.Printf("[Round Robin] Keep %s (%s)\n", d.Snapshot.Name, reasons)
+			continue
+		}
+		if plan.Policy.DryRun {
+			fmt.Printf("[Round Robin] DRY RUN: would delete %s (%s)\n", d.Snapshot.Name, reasons)
+			continue
+		}
+		if err := s.deleteSnapshot(job, d.Snapshot); err != nil {
+			fmt.Printf("⚠️  [Round Robin] Failed to delete %s: %v\n", d.Snapshot.Name, err)
+			continue
+		}
+		deleted++
+	}
+
+	fmt.Printf("✅ [Round Robin] Cleanup complete. Deleted %d item(s).\n", deleted)
+	return nil
+}
+
+// deleteSnapshot: Menghapus satu snapshot dari remote lalu menandainya PRUNED
+func (s *backupServiceImpl) deleteSnapshot(job models.ScheduledJob, snap BackupSnapshot) error {
+	fullPath := fmt.Sprintf("%s:%s/%s", job.RemoteName, job.DestinationPath, snap.Name)
+	deleteCmd := "delete"
+	if snap.IsDir {
+		deleteCmd = "purge"
+	}
+	if err := exec.Command("rclone", deleteCmd, fullPath).Run(); err != nil {
+		return err
+	}
+	if err := s.SnapRepo.MarkPruned(snap.SnapshotID, time.Now()); err != nil {
+		fmt.Printf("⚠️  [Round Robin] Gagal menandai snapshot %d PRUNED: %v\n", snap.SnapshotID, err)
+	}
+	if err := s.CatalogRepo.DeleteBySnapshotID(snap.SnapshotID); err != nil {
+		fmt.Printf("⚠️  [Round Robin] Gagal menghapus manifest snapshot %d: %v\n", snap.SnapshotID, err)
+	}
+
+	// Format output log
+	itemType := "file"
+	sizeStr := fmt.Sprintf("%.2f MB", float64(snap.Size)/(1024*1024))
+	if snap.IsDir {
+		itemType = "folder"
+		sizeStr = "folder"
+	}
+	fmt.Printf("🗑️  [Round Robin] Deleted %s: %s (date: %s, size: %s, run: %d)\n",
+		itemType,
+		snap.Name,
+		snap.Time.Format("2006-01-02 15:04:05"),
+		sizeStr,
+		snap.RunID,
+	)
 	return nil
 }
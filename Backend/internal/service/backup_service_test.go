@@ -0,0 +1,135 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"gbackup-new/backend/internal/models"
+)
+
+func uploadedFiles(copied []string, failed map[string]string) *transferFileTracker {
+	t := newTransferFileTracker()
+	for _, p := range copied {
+		t.copied[p] = true
+	}
+	for p, msg := range failed {
+		t.failed[p] = msg
+	}
+	return t
+}
+
+func remoteFiles(paths ...string) []rcloneManifestEntry {
+	files := make([]rcloneManifestEntry, 0, len(paths))
+	for _, p := range paths {
+		files = append(files, rcloneManifestEntry{Path: p})
+	}
+	return files
+}
+
+func TestVerifySnapshotUpload(t *testing.T) {
+	dir := models.Snapshot{Name: "data_20261012_010000", IsDir: true}
+	file := models.Snapshot{Name: "db_20261012_010000.sql", IsDir: false}
+
+	tests := []struct {
+		name     string
+		snapshot models.Snapshot
+		uploaded *transferFileTracker
+		remote   []rcloneManifestEntry
+		wantErr  string
+	}{
+		{name: "folder lengkap", snapshot: dir,
+			uploaded: uploadedFiles([]string{"a.txt", "sub/b.txt"}, nil),
+			remote:   remoteFiles("a.txt", "sub/b.txt")},
+		{name: "file ter-upload hilang di remote", snapshot: dir,
+			uploaded: uploadedFiles([]string{"a.txt", "sub/b.txt"}, nil),
+			remote:   remoteFiles("a.txt"), wantErr: "tidak ada di remote (mis. sub/b.txt)"},
+		{name: "error per file tanpa retry sukses", snapshot: dir,
+			uploaded: uploadedFiles([]string{"a.txt"}, map[string]string{"c.log": "file changed as we read it"}),
+			remote:   remoteFiles("a.txt"), wantErr: "gagal di-upload (mis. c.log"},
+		{name: "error per file yang sukses saat retry diabaikan", snapshot: dir,
+			uploaded: uploadedFiles([]string{"a.txt", "c.log"}, map[string]string{"c.log": "timeout"}),
+			remote:   remoteFiles("a.txt", "c.log")},
+		{name: "folder dengan direktori di listing", snapshot: dir,
+			uploaded: uploadedFiles([]string{"sub/b.txt"}, nil),
+			remote:   []rcloneManifestEntry{{Path: "sub", IsDir: true}, {Path: "sub/b.txt"}}},
+		{name: "snapshot file", snapshot: file,
+			uploaded: uploadedFiles([]string{"db_20261012_010000.sql"}, nil),
+			remote:   remoteFiles("db_20261012_010000.sql")},
+		{name: "snapshot file tidak ada di remote", snapshot: file,
+			uploaded: uploadedFiles([]string{"db_20261012_010000.sql"}, nil),
+			remote:   nil, wantErr: "berisi 0 file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySnapshotUpload(tt.snapshot, tt.uploaded, tt.remote)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want mengandung %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLegacyClaimants(t *testing.T) {
+	job := models.ScheduledJob{ID: 1, OperationMode: "BACKUP", RcloneMode: "copy",
+		RemoteName: "gdrive", SourcePath: "/srv/a/data", DestinationPath: "/backup"}
+	other := func(id uint, remote, source, dest, mode string) models.ScheduledJob {
+		return models.ScheduledJob{ID: id, OperationMode: "BACKUP", RcloneMode: mode,
+			RemoteName: remote, SourcePath: source, DestinationPath: dest}
+	}
+	name := "data_20250102_030405"
+
+	tests := []struct {
+		desc string
+		jobs []models.ScheduledJob
+		want []uint
+	}{
+		{desc: "hanya job sendiri", jobs: []models.ScheduledJob{job}, want: nil},
+		{
+			desc: "sumber bernama sama di tujuan yang sama",
+			jobs: []models.ScheduledJob{job, other(2, "gdrive", "/srv/b/data", "/backup", "copy")},
+			want: []uint{2},
+		},
+		{
+			desc: "tujuan sama dengan penulisan berbeda",
+			jobs: []models.ScheduledJob{job, other(2, "gdrive", "/srv/b/data", "/backup/", "copy")},
+			want: []uint{2},
+		},
+		{
+			desc: "remote lain",
+			jobs: []models.ScheduledJob{job, other(2, "s3", "/srv/b/data", "/backup", "copy")},
+			want: nil,
+		},
+		{
+			desc: "folder tujuan lain",
+			jobs: []models.ScheduledJob{job, other(2, "gdrive", "/srv/b/data", "/backup/b", "copy")},
+			want: nil,
+		},
+		{
+			desc: "nama sumber lain",
+			jobs: []models.ScheduledJob{job, other(2, "gdrive", "/srv/b/logs", "/backup", "copy")},
+			want: nil,
+		},
+		{
+			desc: "job sync tidak membuat snapshot",
+			jobs: []models.ScheduledJob{job, other(2, "gdrive", "/srv/b/data", "/backup", "sync")},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := legacyClaimants(tt.jobs, job, name)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("legacyClaimants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// blackoutDays: Kode hari yang diterima di kolom Days
+var blackoutDays = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// maxBlackoutChain: Batas jendela berurutan yang diikuti saat menghitung akhir penundaan
+const maxBlackoutChain = 16
+
+// ValidateBlackoutWindow: Validasi dan normalisasi blackout window sebelum disimpan
+func ValidateBlackoutWindow(w *models.BlackoutWindow) error {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return fmt.Errorf("name wajib diisi")
+	}
+
+	days, err := parseBlackoutDays(w.Days)
+	if err != nil {
+		return err
+	}
+	codes := make([]string, 0, len(days))
+	for _, code := range []string{"MON", "TUE", "WED", "THU", "FRI", "SAT", "SUN"} {
+		if days[blackoutDays[code]] {
+			codes = append(codes, code)
+		}
+	}
+	w.Days = strings.Join(codes, ",")
+
+	start, err := parseClock(w.StartTime)
+	if err != nil {
+		return fmt.Errorf("start_time: %w", err)
+	}
+	end, err := parseClock(w.EndTime)
+	if err != nil {
+		return fmt.Errorf("end_time: %w", err)
+	}
+	if start == end {
+		return fmt.Errorf("start_time dan end_time tidak boleh sama")
+	}
+
+	return ValidateTimeZone(w.TimeZone)
+}
+
+// parseBlackoutDays: "MON,TUE" -> set hari
+func parseBlackoutDays(raw string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool)
+	for _, part := range strings.Split(raw, ",") {
+		code := strings.ToUpper(strings.TrimSpace(part))
+		if code == "" {
+			continue
+		}
+		day, ok := blackoutDays[code]
+		if !ok {
+			return nil, fmt.Errorf("hari '%s' tidak valid. Pilihan: MON, TUE, WED, THU, FRI, SAT, SUN", part)
+		}
+		days[day] = true
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("days wajib berisi minimal satu hari")
+	}
+	return days, nil
+}
+
+// parseClock: "HH:MM" -> menit sejak tengah malam
+func parseClock(raw string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(raw))
+	if err != nil {
+		return 0, fmt.Errorf("format jam harus HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// blackoutEnd: Jika t berada di dalam jendela, kembalikan waktu jendela berakhir
+func blackoutEnd(w models.BlackoutWindow, t time.Time, fallback *time.Location) (time.Time, bool) {
+	if !w.IsActive {
+		return time.Time{}, false
+	}
+	days, err := parseBlackoutDays(w.Days)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, err1 := parseClock(w.StartTime)
+	end, err2 := parseClock(w.EndTime)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, false
+	}
+
+	loc := fallback
+	if w.TimeZone != "" {
+		if l, err := time.LoadLocation(w.TimeZone); err == nil {
+			loc = l
+		}
+	}
+
+	local := t.In(loc)
+	// Jendela yang dimulai hari ini atau kemarin (untuk jendela yang melewati tengah malam)
+	for _, offset := range []int{0, -1} {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, loc)
+		if !days[day.Weekday()] {
+			continue
+		}
+		// Jam dinding di zona jendela (bukan durasi sejak tengah malam, yang bergeser saat DST)
+		winStart := time.Date(day.Year(), day.Month(), day.Day(), start/60, start%60, 0, 0, loc)
+		winEnd := time.Date(day.Year(), day.Month(), day.Day(), end/60, end%60, 0, 0, loc)
+		if end <= start {
+			winEnd = time.Date(day.Year(), day.Month(), day.Day()+1, end/60, end%60, 0, 0, loc)
+		}
+		if !local.Before(winStart) && local.Before(winEnd) {
+			return winEnd, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// blackoutDeferral: Waktu paling awal job boleh berjalan jika t jatuh di salah satu jendela.
+// Jendela yang bersambung diikuti sampai tidak ada lagi yang aktif.
+func blackoutDeferral(windows []models.BlackoutWindow, t time.Time, loc *time.Location) (until time.Time, names []string, deferred bool) {
+	until = t
+	for i := 0; i < maxBlackoutChain; i++ {
+		moved := false
+		for _, w := range windows {
+			if end, ok := blackoutEnd(w, until, loc); ok {
+				until = end
+				names = append(names, w.Name)
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+	return until, names, until.After(t)
+}
+
+// ParseWindowIDs: Kolom blackout_window_ids ("1,2") -> slice ID
+func ParseWindowIDs(raw string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err == nil && id > 0 {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// FormatWindowIDs: Slice ID -> format kolom blackout_window_ids (tanpa duplikat)
+func FormatWindowIDs(ids []uint) string {
+	seen := make(map[uint]bool)
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		parts = append(parts, strconv.FormatUint(uint64(id), 10))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+func window(name, days, start, end, tz string) models.BlackoutWindow {
+	return models.BlackoutWindow{Name: name, Days: days, StartTime: start, EndTime: end, TimeZone: tz, IsActive: true}
+}
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("tzdata %s tidak tersedia: %v", name, err)
+	}
+	return loc
+}
+
+func TestBlackoutEnd(t *testing.T) {
+	jkt := mustLoad(t, "Asia/Jakarta")
+	ny := mustLoad(t, "America/New_York")
+
+	business := window("business", "MON,TUE,WED,THU,FRI", "08:00", "17:00", "")
+	overnight := window("overnight", "FRI", "22:00", "06:00", "")
+	inactive := business
+	inactive.IsActive = false
+
+	tests := []struct {
+		name     string
+		w        models.BlackoutWindow
+		t        time.Time
+		fallback *time.Location
+		wantEnd  time.Time
+		wantIn   bool
+	}{
+		{name: "di dalam jam kerja", w: business, t: time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC), fallback: time.UTC,
+			wantEnd: time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC), wantIn: true},
+		{name: "tepat di start termasuk", w: business, t: time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC), fallback: time.UTC,
+			wantEnd: time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC), wantIn: true},
+		{name: "tepat di end tidak termasuk", w: business, t: time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC), fallback: time.UTC},
+		{name: "hari di luar days", w: business, t: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC), fallback: time.UTC},
+		{name: "jendela nonaktif", w: inactive, t: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), fallback: time.UTC},
+		{name: "lewat tengah malam: malam hari mulai", w: overnight, t: time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC), fallback: time.UTC,
+			wantEnd: time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC), wantIn: true},
+		{name: "lewat tengah malam: pagi hari berikutnya (Sabtu tidak di days)", w: overnight, t: time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC), fallback: time.UTC,
+			wantEnd: time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC), wantIn: true},
+		{name: "lewat tengah malam: Sabtu malam bukan jendela", w: overnight, t: time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC), fallback: time.UTC},
+		{name: "lewat tengah malam: Jumat dini hari milik jendela Kamis (tidak ada)", w: overnight, t: time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC), fallback: time.UTC},
+		{name: "zona waktu jendela diutamakan", w: window("jkt", "WED", "08:00", "17:00", "Asia/Jakarta"),
+			t: time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC), fallback: time.UTC, // 09:00 WIB
+			wantEnd: time.Date(2026, 10, 14, 17, 0, 0, 0, jkt), wantIn: true},
+		{name: "fallback zona job jika jendela tanpa zona", w: business,
+			t: time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC), fallback: jkt,
+			wantEnd: time.Date(2026, 10, 14, 17, 0, 0, 0, jkt), wantIn: true},
+		{
+			// 2026-03-08 02:00 EST -> 03:00 EDT: jendela 01:00-04:00 berakhir jam 04:00 lokal, bukan 05:00
+			name: "DST maju", w: window("dst", "SUN", "01:00", "04:00", "America/New_York"),
+			t: time.Date(2026, 3, 8, 1, 30, 0, 0, ny), fallback: time.UTC,
+			wantEnd: time.Date(2026, 3, 8, 4, 0, 0, 0, ny), wantIn: true,
+		},
+		{
+			// 2026-11-01 02:00 EDT -> 01:00 EST: jendela 00:00-03:00 berakhir jam 03:00 lokal, bukan 02:00
+			name: "DST mundur", w: window("dst", "SUN", "00:00", "03:00", "America/New_York"),
+			t: time.Date(2026, 11, 1, 2, 30, 0, 0, ny), fallback: time.UTC,
+			wantEnd: time.Date(2026, 11, 1, 3, 0, 0, 0, ny), wantIn: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, in := blackoutEnd(tt.w, tt.t, tt.fallback)
+			if in != tt.wantIn {
+				t.Fatalf("in = %v, want %v", in, tt.wantIn)
+			}
+			if in && !end.Equal(tt.wantEnd) {
+				t.Errorf("end = %v, want %v", end, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestBlackoutDeferral(t *testing.T) {
+	morning := window("morning", "MON", "08:00", "12:00", "")
+	lunch := window("lunch", "MON", "12:00", "13:00", "")
+	night := window("night", "SUN", "22:00", "02:00", "")
+	monday := func(h, m int) time.Time { return time.Date(2026, 10, 12, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		windows   []models.BlackoutWindow
+		t         time.Time
+		wantUntil time.Time
+		wantNames []string
+		deferred  bool
+	}{
+		{name: "jendela bersambung diikuti", windows: []models.BlackoutWindow{morning, lunch}, t: monday(9, 0),
+			wantUntil: monday(13, 0), wantNames: []string{"morning", "lunch"}, deferred: true},
+		{name: "urutan jendela tidak berpengaruh", windows: []models.BlackoutWindow{lunch, morning}, t: monday(9, 0),
+			wantUntil: monday(13, 0), wantNames: []string{"morning", "lunch"}, deferred: true},
+		{name: "di luar jendela", windows: []models.BlackoutWindow{morning, lunch}, t: monday(14, 0),
+			wantUntil: monday(14, 0), deferred: false},
+		{name: "jendela tidak bersambung tidak dirangkai", windows: []models.BlackoutWindow{night, morning},
+			t: time.Date(2026, 10, 11, 23, 0, 0, 0, time.UTC), wantUntil: monday(2, 0), wantNames: []string{"night"}, deferred: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, names, deferred := blackoutDeferral(tt.windows, tt.t, time.UTC)
+			if deferred != tt.deferred {
+				t.Fatalf("deferred = %v, want %v", deferred, tt.deferred)
+			}
+			if !until.Equal(tt.wantUntil) {
+				t.Errorf("until = %v, want %v", until, tt.wantUntil)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
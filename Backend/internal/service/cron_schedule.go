@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrInvalidCron: Ekspresi schedule_cron tidak bisa di-parse
+var ErrInvalidCron = errors.New("ekspresi cron tidak valid")
+
+// cronParser: Format 5 field standar, detik opsional (6 field), descriptor (@daily, @every 30m)
+// dan prefix zona waktu CRON_TZ=/TZ=
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// ParseCronExpression: Parse schedule_cron menjadi cron.Schedule
+func ParseCronExpression(expr string) (cron.Schedule, error) {
+	sched, err := cronParser.Parse(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s': %v", ErrInvalidCron, expr, err)
+	}
+	return sched, nil
+}
+
+// ValidateCronExpression: Validasi schedule_cron sebelum disimpan ("" = job manual, selalu valid)
+func ValidateCronExpression(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		return nil
+	}
+	_, err := ParseCronExpression(expr)
+	return err
+}
+
+// ValidateTimeZone: Validasi nama zona waktu IANA ("" = zona waktu server)
+func ValidateTimeZone(tz string) error {
+	if tz == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("zona waktu '%s' tidak valid: %v", tz, err)
+	}
+	return nil
+}
+
+// jobLocation: Zona waktu job (fallback ke zona waktu server)
+func jobLocation(job models.ScheduledJob) *time.Location {
+	if job.TimeZone != "" {
+		if loc, err := time.LoadLocation(job.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// jobSchedule: Parse schedule_cron job dengan zona waktunya
+func jobSchedule(job models.ScheduledJob) (cron.Schedule, error) {
+	return parseScheduleInZone(job.ScheduleCron, job.TimeZone)
+}
+
+// parseScheduleInZone: Parse ekspresi cron pada zona waktu tz.
+// Prefix CRON_TZ=/TZ= di ekspresi tetap diutamakan.
+func parseScheduleInZone(expr, tz string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if tz != "" && !strings.HasPrefix(expr, "CRON_TZ=") && !strings.HasPrefix(expr, "TZ=") {
+		expr = fmt.Sprintf("CRON_TZ=%s %s", tz, expr)
+	}
+	return ParseCronExpression(expr)
+}
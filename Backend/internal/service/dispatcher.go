@@ -0,0 +1,649 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/repository"
+)
+
+// ErrJobAlreadyQueued: Job yang sama sudah ada di antrean
+var ErrJobAlreadyQueued = errors.New("job sudah ada di antrean")
+
+// dispatcherPollInterval: Interval cek ulang antrean (untuk entry yang DueAt-nya di masa depan)
+const dispatcherPollInterval = 5 * time.Second
+
+// DispatcherConfig: Batas konkurensi worker pool
+type DispatcherConfig struct {
+	MaxConcurrent int `json:"max_concurrent"` // Batas global job berjalan bersamaan
+	MaxPerRemote  int `json:"max_per_remote"` // Batas job berjalan bersamaan per remote
+}
+
+// LoadDispatcherConfig: Membaca MAX_CONCURRENT_JOBS dan MAX_JOBS_PER_REMOTE dari ENV
+func LoadDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		MaxConcurrent: envInt("MAX_CONCURRENT_JOBS", 2),
+		MaxPerRemote:  envInt("MAX_JOBS_PER_REMOTE", 1),
+	}
+}
+
+// envInt: Membaca ENV integer positif, fallback ke nilai default jika kosong/tidak valid
+func envInt(key string, fallback int) int {
+	val, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || val < 1 {
+		return fallback
+	}
+	return val
+}
+
+// QueueItemDTO: Struct untuk output JSON antrean (GET /api/v1/queue)
+type QueueItemDTO struct {
+	QueueID    uint      `json:"queue_id"`
+	JobID      uint      `json:"job_id"`
+	JobName    string    `json:"job_name"`
+	RemoteName string    `json:"remote_name"`
+	Trigger    string    `json:"trigger"`
+	Priority   int       `json:"priority"`
+	DueAt      time.Time `json:"due_at"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	Position   int       `json:"position"`
+	Reason     string    `json:"reason"`                // Alasan job masih menunggu
+	DeferredBy string    `json:"deferred_by,omitempty"` // Blackout window yang menunda entry ini
+}
+
+// RunningItemDTO: Job yang sedang memegang slot worker
+type RunningItemDTO struct {
+	JobID      uint      `json:"job_id"`
+	JobName    string    `json:"job_name"`
+	RemoteName string    `json:"remote_name"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// QueueStatusDTO: Snapshot lengkap dispatcher
+type QueueStatusDTO struct {
+	Limits  DispatcherConfig `json:"limits"`
+	Running []RunningItemDTO `json:"running"`
+	Queued  []QueueItemDTO   `json:"queued"`
+}
+
+// DroppedEntryListener: Callback untuk entry antrean yang dibuang tanpa dijalankan
+// (job sudah dihapus, snapshot rusak, atau run terjadwal milik job yang di-pause)
+type DroppedEntryListener func(entry models.QueueEntry, opts RunOptions, reason error)
+
+// JobDispatcher: Worker pool terbatas dengan antrean prioritas persisten
+type JobDispatcher interface {
+	SetRunner(runner func(job models.ScheduledJob, opts RunOptions))
+	Enqueue(job models.ScheduledJob, opts RunOptions) error
+	EnqueueAt(job models.ScheduledJob, opts RunOptions, dueAt time.Time) error
+	IsQueued(jobID uint) bool
+	CancelQueued(jobID uint, reason error) []models.QueueEntry
+	GetQueueStatus() QueueStatusDTO
+	LoadQueue() error
+	Start() error
+	Stop()
+	WaitIdle(ctx context.Context) error
+	OnEntryDropped(listener DroppedEntryListener)
+}
+
+type jobDispatcherImpl struct {
+	QueueRepo repository.QueueRepository
+	JobRepo   repository.JobRepository
+	Config    DispatcherConfig
+
+	mu             sync.Mutex
+	runner         func(job models.ScheduledJob, opts RunOptions)
+	queue          []models.QueueEntry
+	pending        map[uint]bool // Job yang entry-nya sedang disimpan ke DB (sudah dicek, belum masuk queue)
+	running        map[uint64]RunningItemDTO
+	runningJobs    map[uint]bool
+	runningRemotes map[string]int
+	nextSlot       uint64
+	wake           chan struct{}
+	loaded         bool
+	stopped        bool
+	stop           chan struct{}
+	workers        sync.WaitGroup
+
+	listenersMu sync.Mutex
+	listeners   []DroppedEntryListener
+}
+
+func NewJobDispatcher(qRepo repository.QueueRepository, jRepo repository.JobRepository, cfg DispatcherConfig) JobDispatcher {
+	return &jobDispatcherImpl{
+		QueueRepo:      qRepo,
+		JobRepo:        jRepo,
+		Config:         cfg,
+		pending:        make(map[uint]bool),
+		running:        make(map[uint64]RunningItemDTO),
+		runningJobs:    make(map[uint]bool),
+		runningRemotes: make(map[string]int),
+		wake:           make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+	}
+}
+
+// SetRunner: Fungsi yang mengeksekusi job (diisi oleh BackupService)
+func (d *jobDispatcherImpl) SetRunner(runner func(job models.ScheduledJob, opts RunOptions)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.runner = runner
+}
+
+// Enqueue: Memasukkan job ke antrean persisten. Status job menjadi PENDING (= antre).
+func (d *jobDispatcherImpl) Enqueue(job models.ScheduledJob, opts RunOptions) error {
+	return d.EnqueueAt(job, opts, time.Now())
+}
+
+// EnqueueAt: Sama seperti Enqueue, tapi entry baru boleh dijalankan mulai dueAt
+func (d *jobDispatcherImpl) EnqueueAt(job models.ScheduledJob, opts RunOptions, dueAt time.Time) error {
+	snapshot, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("gagal serialisasi job: %w", err)
+	}
+	optsJSON, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("gagal serialisasi opsi run: %w", err)
+	}
+
+	entry := models.QueueEntry{
+		JobName:     job.JobName,
+		RemoteName:  job.RemoteName,
+		Priority:    job.Priority,
+		DueAt:       dueAt,
+		JobSnapshot: string(snapshot),
+		Options:     string(optsJSON),
+	}
+	if job.ID != 0 {
+		jobID := job.ID
+		entry.JobID = &jobID
+	}
+
+	// Cek & reservasi job ID dalam satu critical section, agar dua enqueue bersamaan
+	// tidak sama-sama lolos cek sebelum entry pertama tersimpan
+	if job.ID != 0 {
+		d.mu.Lock()
+		if d.isQueuedLocked(job.ID) {
+			d.mu.Unlock()
+			return fmt.Errorf("job ID %d: %w", job.ID, ErrJobAlreadyQueued)
+		}
+		d.pending[job.ID] = true
+		d.mu.Unlock()
+	}
+
+	err = d.QueueRepo.Create(&entry)
+
+	d.mu.Lock()
+	delete(d.pending, job.ID)
+	if err != nil {
+		d.mu.Unlock()
+		return err
+	}
+	d.queue = append(d.queue, entry)
+	alreadyRunning := job.ID != 0 && d.runningJobs[job.ID]
+	d.mu.Unlock()
+
+	// Jangan timpa status RUNNING milik run yang masih berjalan
+	if job.ID != 0 && !alreadyRunning {
+		if err := d.JobRepo.UpdateStatus(job.ID, "PENDING"); err != nil {
+			fmt.Printf("⚠️ [DISPATCHER] Gagal set status PENDING Job %d: %v\n", job.ID, err)
+		}
+	}
+
+	fmt.Printf("[DISPATCHER] 📥 Job %d (%s) masuk antrean (priority %d, trigger %s)\n", job.ID, job.JobName, job.Priority, opts.Trigger)
+	d.notify()
+	return nil
+}
+
+// IsQueued: Cek apakah job sudah menunggu di antrean (termasuk yang sedang disimpan)
+func (d *jobDispatcherImpl) IsQueued(jobID uint) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.isQueuedLocked(jobID)
+}
+
+// CancelQueued: Mengeluarkan semua entry job yang masih menunggu dari antrean (memori & DB)
+// dan melaporkannya ke listener entry yang dibuang. Entry yang dikeluarkan dikembalikan.
+func (d *jobDispatcherImpl) CancelQueued(jobID uint, reason error) []models.QueueEntry {
+	if jobID == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	var removed []models.QueueEntry
+	remaining := d.queue[:0]
+	for _, entry := range d.queue {
+		if entry.JobID != nil && *entry.JobID == jobID {
+			removed = append(removed, entry)
+			continue
+		}
+		remaining = append(remaining, entry)
+	}
+	d.queue = remaining
+	d.mu.Unlock()
+
+	dropped := make([]droppedEntry, 0, len(removed))
+	for _, entry := range removed {
+		if err := d.QueueRepo.Delete(entry.ID); err != nil {
+			fmt.Printf("⚠️ [DISPATCHER] Gagal menghapus antrean %d: %v\n", entry.ID, err)
+		}
+		fmt.Printf("[DISPATCHER] Antrean %d (%s) dibatalkan: %v\n", entry.ID, entry.JobName, reason)
+		dropped = append(dropped, droppedEntry{entry: entry, opts: entryOptions(entry), reason: reason})
+	}
+	d.notifyDropped(dropped)
+	return removed
+}
+
+// isQueuedLocked: IsQueued dengan d.mu sudah dipegang
+func (d *jobDispatcherImpl) isQueuedLocked(jobID uint) bool {
+	if d.pending[jobID] {
+		return true
+	}
+	for _, entry := range d.queue {
+		if entry.JobID != nil && *entry.JobID == jobID {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadQueue: Memuat antrean tersimpan dari DB tanpa menjalankannya (agar IsQueued akurat
+// saat recovery startup, sebelum Start)
+func (d *jobDispatcherImpl) LoadQueue() error {
+	entries, err := d.QueueRepo.FindAll()
+	if err != nil {
+		return fmt.Errorf("gagal memuat antrean job: %w", err)
+	}
+
+	d.mu.Lock()
+	d.queue = entries
+	d.loaded = true
+	d.mu.Unlock()
+	return nil
+}
+
+// Start: Memuat antrean dari DB (jika belum) lalu menjalankan loop dispatcher di background
+func (d *jobDispatcherImpl) Start() error {
+	d.mu.Lock()
+	loaded := d.loaded
+	d.mu.Unlock()
+	if !loaded {
+		if err := d.LoadQueue(); err != nil {
+			return err
+		}
+	}
+
+	d.mu.Lock()
+	queued := len(d.queue)
+	d.mu.Unlock()
+
+	go func() {
+		fmt.Printf("🚀 Dispatcher aktif (global: %d, per remote: %d, antrean dimuat: %d)\n",
+			d.Config.MaxConcurrent, d.Config.MaxPerRemote, queued)
+
+		ticker := time.NewTicker(dispatcherPollInterval)
+		defer ticker.Stop()
+
+		for {
+			d.dispatchReady()
+			select {
+			case <-d.wake:
+			case <-ticker.C:
+			case <-d.stop:
+				fmt.Println("[DISPATCHER] ⏹️ Dispatcher berhenti, antrean tetap tersimpan di DB")
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop: Berhenti menjalankan entry baru. Entry antrean tetap di DB dan dimuat ulang saat Start berikutnya.
+func (d *jobDispatcherImpl) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	d.stopped = true
+	close(d.stop)
+}
+
+// WaitIdle: Menunggu semua worker yang sedang berjalan selesai, atau ctx habis
+func (d *jobDispatcherImpl) WaitIdle(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnEntryDropped: Mendaftarkan listener entry yang dibuang
+func (d *jobDispatcherImpl) OnEntryDropped(listener DroppedEntryListener) {
+	d.listenersMu.Lock()
+	defer d.listenersMu.Unlock()
+	d.listeners = append(d.listeners, listener)
+}
+
+// notifyDropped: Memanggil semua listener (di luar mu, listener boleh memanggil Enqueue)
+func (d *jobDispatcherImpl) notifyDropped(dropped []droppedEntry) {
+	if len(dropped) == 0 {
+		return
+	}
+	d.listenersMu.Lock()
+	listeners := append([]DroppedEntryListener(nil), d.listeners...)
+	d.listenersMu.Unlock()
+
+	for _, drop := range dropped {
+		for _, listener := range listeners {
+			listener(drop.entry, drop.opts, drop.reason)
+		}
+	}
+}
+
+// droppedEntry: Entry yang dibuang dispatchReady, dilaporkan setelah mu dilepas
+type droppedEntry struct {
+	entry  models.QueueEntry
+	opts   RunOptions
+	reason error
+}
+
+// notify: Membangunkan loop dispatcher tanpa blocking
+func (d *jobDispatcherImpl) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sortQueue: Urutkan antrean berdasarkan Priority (kecil = didahulukan), lalu DueAt. Wajib memegang mu.
+func (d *jobDispatcherImpl) sortQueue() {
+	sort.SliceStable(d.queue, func(i, j int) bool {
+		a, b := d.queue[i], d.queue[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if !a.DueAt.Equal(b.DueAt) {
+			return a.DueAt.Before(b.DueAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// blockReason: Alasan entry belum bisa dijalankan ("" = siap jalan). Wajib memegang mu.
+func (d *jobDispatcherImpl) blockReason(entry models.QueueEntry, now time.Time) string {
+	if entry.DueAt.After(now) {
+		return fmt.Sprintf("menunggu jadwal %s", entry.DueAt.Format("2006-01-02 15:04:05"))
+	}
+	if entry.JobID != nil && d.runningJobs[*entry.JobID] {
+		return "run sebelumnya dari job ini masih berjalan"
+	}
+	if len(d.running) >= d.Config.MaxConcurrent {
+		return fmt.Sprintf("batas global tercapai (%d/%d job berjalan)", len(d.running), d.Config.MaxConcurrent)
+	}
+	if d.runningRemotes[entry.RemoteName] >= d.Config.MaxPerRemote {
+		return fmt.Sprintf("batas remote %s tercapai (%d/%d job berjalan)",
+			entry.RemoteName, d.runningRemotes[entry.RemoteName], d.Config.MaxPerRemote)
+	}
+	return ""
+}
+
+// dispatchReady: Menjalankan semua entry yang siap selama slot masih tersedia.
+// Entry dipilih (dan slot-nya dipesan) di bawah mu; hapus antrean DB & muat job dilakukan di luar mu.
+func (d *jobDispatcherImpl) dispatchReady() {
+	var dropped []droppedEntry
+	for _, p := range d.pickReady() {
+		if err := d.QueueRepo.Delete(p.entry.ID); err != nil {
+			fmt.Printf("⚠️ [DISPATCHER] Gagal menghapus antrean %d: %v\n", p.entry.ID, err)
+			d.unreserve(p, true)
+			continue
+		}
+
+		opts := entryOptions(p.entry)
+		job, err := d.resolveJob(p.entry)
+		if err != nil {
+			fmt.Printf("⚠️ [DISPATCHER] Antrean %d (%s) dibuang: %v\n", p.entry.ID, p.entry.JobName, err)
+			d.unreserve(p, false)
+			dropped = append(dropped, droppedEntry{entry: p.entry, opts: opts, reason: err})
+			continue
+		}
+
+		// Run terjadwal yang masih menunggu (mis. ditunda blackout) tidak dijalankan jika job di-pause
+		if opts.Trigger == TriggerSchedule && job.ID != 0 && !job.IsActive {
+			fmt.Printf("[DISPATCHER] Antrean %d (%s) dibuang: job sedang di-pause\n", p.entry.ID, p.entry.JobName)
+			d.unreserve(p, false)
+			dropped = append(dropped, droppedEntry{entry: p.entry, opts: opts, reason: errors.New("job sedang di-pause")})
+			continue
+		}
+
+		d.launch(p.slot, job, opts)
+	}
+	d.notifyDropped(dropped)
+}
+
+// pickedEntry: Entry yang sudah dikeluarkan dari antrean memori dan memegang slot worker
+type pickedEntry struct {
+	entry models.QueueEntry
+	slot  uint64
+}
+
+// pickReady: Mengeluarkan entry yang siap dari antrean dan memesan slot-nya,
+// agar batas konkurensi tetap terjaga selama I/O DB di luar mu
+func (d *jobDispatcherImpl) pickReady() []pickedEntry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.runner == nil || d.stopped {
+		return nil
+	}
+
+	d.sortQueue()
+	now := time.Now()
+
+	var picked []pickedEntry
+	remaining := d.queue[:0]
+	for _, entry := range d.queue {
+		if d.blockReason(entry, now) != "" {
+			remaining = append(remaining, entry)
+			continue
+		}
+		picked = append(picked, pickedEntry{entry: entry, slot: d.reserveLocked(entry)})
+	}
+	d.queue = remaining
+	return picked
+}
+
+// reserveLocked: Memesan slot worker untuk entry. Wajib memegang mu.
+func (d *jobDispatcherImpl) reserveLocked(entry models.QueueEntry) uint64 {
+	d.nextSlot++
+	slot := d.nextSlot
+
+	item := RunningItemDTO{
+		JobName:    entry.JobName,
+		RemoteName: entry.RemoteName,
+		StartedAt:  time.Now(),
+	}
+	if entry.JobID != nil {
+		item.JobID = *entry.JobID
+	}
+	d.running[slot] = item
+	d.runningRemotes[item.RemoteName]++
+	if item.JobID != 0 {
+		d.runningJobs[item.JobID] = true
+	}
+	// Dihitung sejak dipesan agar WaitIdle tidak lolos sebelum entry sempat dijalankan
+	d.workers.Add(1)
+	return slot
+}
+
+// unreserve: Melepas slot entry yang batal dijalankan; requeue = kembalikan ke antrean memori
+func (d *jobDispatcherImpl) unreserve(p pickedEntry, requeue bool) {
+	d.mu.Lock()
+	d.releaseLocked(p.slot)
+	if requeue {
+		d.queue = append(d.queue, p.entry)
+	}
+	d.mu.Unlock()
+
+	d.workers.Done()
+}
+
+// resolveJob: Job tersimpan dimuat ulang dari DB (config terbaru), restore one-shot dari snapshot
+func (d *jobDispatcherImpl) resolveJob(entry models.QueueEntry) (models.ScheduledJob, error) {
+	if entry.JobID != nil {
+		job, err := d.JobRepo.FindJobByID(*entry.JobID)
+		if err != nil {
+			return models.ScheduledJob{}, err
+		}
+		return *job, nil
+	}
+
+	var job models.ScheduledJob
+	if err := json.Unmarshal([]byte(entry.JobSnapshot), &job); err != nil {
+		return models.ScheduledJob{}, fmt.Errorf("snapshot job rusak: %w", err)
+	}
+	return job, nil
+}
+
+// entryOptions: Decode RunOptions dari entry antrean (entry lama tanpa opsi dianggap MANUAL)
+func entryOptions(entry models.QueueEntry) RunOptions {
+	opts := RunOptions{Trigger: TriggerManual}
+	if entry.Options != "" {
+		if err := json.Unmarshal([]byte(entry.Options), &opts); err != nil {
+			fmt.Printf("⚠️ [DISPATCHER] Opsi antrean %d rusak: %v\n", entry.ID, err)
+		}
+	}
+	return opts
+}
+
+// launch: Menjalankan job di slot yang sudah dipesan. Remote/ID diperbarui jika config job
+// berubah sejak entry masuk antrean.
+func (d *jobDispatcherImpl) launch(slot uint64, job models.ScheduledJob, opts RunOptions) {
+	d.mu.Lock()
+	reserved := d.running[slot]
+	if reserved.RemoteName != job.RemoteName {
+		d.runningRemotes[job.RemoteName]++
+		d.decRemoteLocked(reserved.RemoteName)
+	}
+	if reserved.JobID != job.ID {
+		if reserved.JobID != 0 {
+			delete(d.runningJobs, reserved.JobID)
+		}
+		if job.ID != 0 {
+			d.runningJobs[job.ID] = true
+		}
+	}
+	d.running[slot] = RunningItemDTO{
+		JobID:      job.ID,
+		JobName:    job.JobName,
+		RemoteName: job.RemoteName,
+		StartedAt:  time.Now(),
+	}
+	running := len(d.running)
+	runner := d.runner
+	d.mu.Unlock()
+
+	fmt.Printf("[DISPATCHER] ▶️ Menjalankan Job %d (%s) [%d/%d slot]\n",
+		job.ID, job.JobName, running, d.Config.MaxConcurrent)
+
+	go func() {
+		defer d.workers.Done()
+		defer d.release(slot)
+		runner(job, opts)
+	}()
+}
+
+// release: Mengembalikan slot worker setelah job selesai
+func (d *jobDispatcherImpl) release(slot uint64) {
+	d.mu.Lock()
+	d.releaseLocked(slot)
+	d.mu.Unlock()
+
+	d.notify()
+}
+
+// releaseLocked: Menghapus slot beserta hitungan remote/job-nya. Wajib memegang mu.
+func (d *jobDispatcherImpl) releaseLocked(slot uint64) {
+	item, ok := d.running[slot]
+	if !ok {
+		return
+	}
+	delete(d.running, slot)
+	d.decRemoteLocked(item.RemoteName)
+	if item.JobID != 0 {
+		delete(d.runningJobs, item.JobID)
+	}
+}
+
+// decRemoteLocked: Mengurangi hitungan job berjalan di remote. Wajib memegang mu.
+func (d *jobDispatcherImpl) decRemoteLocked(remote string) {
+	d.runningRemotes[remote]--
+	if d.runningRemotes[remote] <= 0 {
+		delete(d.runningRemotes, remote)
+	}
+}
+
+// GetQueueStatus: Snapshot antrean beserta alasan menunggu tiap entry
+func (d *jobDispatcherImpl) GetQueueStatus() QueueStatusDTO {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sortQueue()
+	now := time.Now()
+
+	status := QueueStatusDTO{
+		Limits:  d.Config,
+		Running: []RunningItemDTO{},
+		Queued:  []QueueItemDTO{},
+	}
+
+	for _, run := range d.running {
+		status.Running = append(status.Running, run)
+	}
+	sort.Slice(status.Running, func(i, j int) bool {
+		return status.Running[i].StartedAt.Before(status.Running[j].StartedAt)
+	})
+
+	for i, entry := range d.queue {
+		opts := entryOptions(entry)
+		reason := d.blockReason(entry, now)
+		if reason == "" {
+			reason = "siap dijalankan pada siklus dispatcher berikutnya"
+		} else if opts.DeferredBy != "" && entry.DueAt.After(now) {
+			reason = fmt.Sprintf("ditunda blackout window %s hingga %s", opts.DeferredBy, entry.DueAt.Format("2006-01-02 15:04:05"))
+		}
+
+		item := QueueItemDTO{
+			QueueID:    entry.ID,
+			JobName:    entry.JobName,
+			RemoteName: entry.RemoteName,
+			Trigger:    opts.Trigger,
+			DeferredBy: opts.DeferredBy,
+			Priority:   entry.Priority,
+			DueAt:      entry.DueAt,
+			EnqueuedAt: entry.CreatedAt,
+			Position:   i + 1,
+			Reason:     reason,
+		}
+		if entry.JobID != nil {
+			item.JobID = *entry.JobID
+		}
+		status.Queued = append(status.Queued, item)
+	}
+	return status
+}
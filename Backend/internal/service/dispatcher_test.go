@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/repository"
+)
+
+// fakeQueueRepo: QueueRepository di memori; Create sengaja lambat agar enqueue bersamaan saling tumpang tindih
+type fakeQueueRepo struct {
+	mu       sync.Mutex
+	nextID   uint
+	created  int
+	deleted  []uint
+	failNext bool
+}
+
+func (r *fakeQueueRepo) Create(entry *models.QueueEntry) error {
+	time.Sleep(5 * time.Millisecond)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failNext {
+		r.failNext = false
+		return errors.New("db down")
+	}
+	r.nextID++
+	entry.ID = r.nextID
+	r.created++
+	return nil
+}
+
+func (r *fakeQueueRepo) Delete(entryID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, entryID)
+	return nil
+}
+
+func (r *fakeQueueRepo) FindAll() ([]models.QueueEntry, error) { return nil, nil }
+
+// fakeStatusJobRepo: Hanya UpdateStatus yang dipakai EnqueueAt
+type fakeStatusJobRepo struct {
+	repository.JobRepository
+}
+
+func (fakeStatusJobRepo) UpdateStatus(jobID uint, status string) error { return nil }
+
+func newTestDispatcher(qRepo *fakeQueueRepo) *jobDispatcherImpl {
+	return NewJobDispatcher(qRepo, fakeStatusJobRepo{}, DispatcherConfig{}).(*jobDispatcherImpl)
+}
+
+func TestEnqueueAtConcurrentSameJob(t *testing.T) {
+	qRepo := &fakeQueueRepo{}
+	d := newTestDispatcher(qRepo)
+	job := models.ScheduledJob{ID: 7, JobName: "docs"}
+
+	const n = 10
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- d.Enqueue(job, RunOptions{})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	ok := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case !errors.Is(err, ErrJobAlreadyQueued):
+			t.Fatalf("error tak terduga: %v", err)
+		}
+	}
+	if ok != 1 || qRepo.created != 1 || len(d.queue) != 1 {
+		t.Fatalf("sukses = %d, tersimpan = %d, antrean = %d, want 1/1/1", ok, qRepo.created, len(d.queue))
+	}
+}
+
+func TestEnqueueAtReleasesReservationOnCreateFailure(t *testing.T) {
+	qRepo := &fakeQueueRepo{failNext: true}
+	d := newTestDispatcher(qRepo)
+	job := models.ScheduledJob{ID: 7, JobName: "docs"}
+
+	if err := d.Enqueue(job, RunOptions{}); err == nil {
+		t.Fatal("enqueue pertama harus gagal")
+	}
+	if d.IsQueued(job.ID) {
+		t.Fatal("job masih tercatat di antrean setelah insert gagal")
+	}
+	if err := d.Enqueue(job, RunOptions{}); err != nil {
+		t.Fatalf("enqueue ulang: %v", err)
+	}
+	if !d.IsQueued(job.ID) {
+		t.Error("job harus ada di antrean")
+	}
+}
+
+func TestCancelQueued(t *testing.T) {
+	qRepo := &fakeQueueRepo{}
+	d := newTestDispatcher(qRepo)
+	var reasons []error
+	d.OnEntryDropped(func(entry models.QueueEntry, opts RunOptions, reason error) {
+		reasons = append(reasons, reason)
+	})
+
+	for _, job := range []models.ScheduledJob{{ID: 7, JobName: "docs"}, {ID: 8, JobName: "db"}} {
+		if err := d.Enqueue(job, RunOptions{}); err != nil {
+			t.Fatalf("enqueue Job %d: %v", job.ID, err)
+		}
+	}
+
+	removed := d.CancelQueued(7, ErrJobCancelled)
+	if len(removed) != 1 || *removed[0].JobID != 7 {
+		t.Fatalf("removed = %+v, want satu entry Job 7", removed)
+	}
+	if d.IsQueued(7) || !d.IsQueued(8) {
+		t.Errorf("IsQueued(7) = %v, IsQueued(8) = %v, want false/true", d.IsQueued(7), d.IsQueued(8))
+	}
+	if len(qRepo.deleted) != 1 || qRepo.deleted[0] != removed[0].ID {
+		t.Errorf("entry DB terhapus = %v, want [%d]", qRepo.deleted, removed[0].ID)
+	}
+	if len(reasons) != 1 || !errors.Is(reasons[0], ErrJobCancelled) {
+		t.Errorf("listener menerima %v, want ErrJobCancelled", reasons)
+	}
+
+	if removed := d.CancelQueued(7, ErrJobCancelled); len(removed) != 0 {
+		t.Errorf("pembatalan kedua mengeluarkan %d entry", len(removed))
+	}
+}
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -13,9 +17,19 @@ type RcloneResult struct {
 	ErrorMsg         string
 	Duration         time.Duration
 	TransferredBytes int64
+	TransferredFiles int64
 }
 
+// processKillGrace: Waktu tunggu setelah process group di-kill sebelum pipe output dipaksa tutup
+const processKillGrace = 5 * time.Second
+
 func ExecuteCliJob(commandArgs []string) RcloneResult {
+	return ExecuteCliJobContext(context.Background(), commandArgs)
+}
+
+// ExecuteCliJobContext: Sama seperti ExecuteCliJob, tapi proses (beserta seluruh child-nya)
+// akan di-kill ketika ctx dibatalkan. Output yang sudah terkumpul tetap dikembalikan.
+func ExecuteCliJobContext(ctx context.Context, commandArgs []string) RcloneResult {
 	// 1. Safety Check: Pastikan command tidak kosong agar tidak panic
 	if len(commandArgs) == 0 {
 		return RcloneResult{
@@ -29,7 +43,7 @@ func ExecuteCliJob(commandArgs []string) RcloneResult {
 	cmdName := commandArgs[0]
 	args := commandArgs[1:]
 
-	cmd := exec.Command(cmdName, args...)
+	cmd := newGroupCommand(ctx, cmdName, args...)
 
 	output, err := cmd.CombinedOutput()
 	duration := time.Since(startTime)
@@ -44,6 +58,12 @@ func ExecuteCliJob(commandArgs []string) RcloneResult {
 	// Parse bytes baik sukses maupun gagal (kadang rclone error tapi sempat transfer data)
 	result.TransferredBytes = parseTransferredBytes(outputStr)
 
+	if ctx.Err() != nil {
+		result.Success = false
+		result.ErrorMsg = fmt.Sprintf("Dihentikan: %v. Output: %s", context.Cause(ctx), result.Output)
+		return result
+	}
+
 	if err != nil {
 		result.Success = false
 		result.ErrorMsg = fmt.Sprintf("Exit Error: %v. Output: %s", err, result.Output)
@@ -53,3 +73,87 @@ func ExecuteCliJob(commandArgs []string) RcloneResult {
 	result.Success = true
 	return result
 }
+
+// ExecuteCliJobStream: Menjalankan command dan mengirim setiap baris output (stdout+stderr)
+// ke onLine selama proses berjalan. Nilai balik onLine adalah teks yang disimpan ke
+// RcloneResult.Output ("" = baris tidak disimpan).
+func ExecuteCliJobStream(ctx context.Context, commandArgs []string, onLine func(line string) string) RcloneResult {
+	if len(commandArgs) == 0 {
+		return RcloneResult{
+			Success:  false,
+			ErrorMsg: "Command arguments cannot be empty",
+		}
+	}
+
+	startTime := time.Now()
+
+	cmd := newGroupCommand(ctx, commandArgs[0], commandArgs[1:]...)
+
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	var output strings.Builder
+	scanDone := make(chan struct{})
+	go func() {
+		defer close(scanDone)
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if onLine != nil {
+				line = onLine(line)
+			}
+			if line != "" {
+				output.WriteString(line)
+				output.WriteString("\n")
+			}
+		}
+		// Kosongkan sisa pipe agar proses tidak blocking jika scanner berhenti karena error
+		io.Copy(io.Discard, pr)
+	}()
+
+	err := cmd.Start()
+	if err == nil {
+		err = cmd.Wait()
+	}
+	pw.Close()
+	<-scanDone
+
+	result := RcloneResult{
+		Duration: time.Since(startTime),
+		Output:   strings.TrimSpace(output.String()),
+	}
+	result.TransferredBytes = parseTransferredBytes(result.Output)
+
+	if ctx.Err() != nil {
+		result.Success = false
+		result.ErrorMsg = fmt.Sprintf("Dihentikan: %v. Output: %s", context.Cause(ctx), result.Output)
+		return result
+	}
+
+	if err != nil {
+		result.Success = false
+		result.ErrorMsg = fmt.Sprintf("Exit Error: %v. Output: %s", err, result.Output)
+		return result
+	}
+
+	result.Success = true
+	return result
+}
+
+// newGroupCommand: Membuat exec.Cmd di process group sendiri, sehingga saat ctx dibatalkan
+// seluruh group (bash + child seperti mysqldump, atau rclone) ikut di-kill.
+func newGroupCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		if cmd.Process == nil {
+			return nil
+		}
+		// PID negatif = kirim sinyal ke seluruh process group
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = processKillGrace
+	return cmd
+}
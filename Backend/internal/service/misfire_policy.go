@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Kebijakan untuk jadwal yang terlewat saat backend mati (kolom misfire_policy)
+const (
+	MisfirePolicyRunOnce      = "run_once"       // Jalankan sekali untuk jadwal terlewat terakhir
+	MisfirePolicySkip         = "skip"           // Lewati semua, tunggu jadwal berikutnya
+	MisfirePolicyRunAllMissed = "run_all_missed" // Jalankan setiap jadwal terlewat (dibatasi MaxMisfireCatchup)
+)
+
+// MaxMisfireCatchup: Batas jumlah run susulan untuk run_all_missed
+const MaxMisfireCatchup = 10
+
+// maxMisfireScan: Batas jumlah jadwal terlewat yang dihitung (cron per detik bisa menghasilkan ribuan)
+const maxMisfireScan = 1000
+
+// maxRecordedSkips: Batas baris SKIPPED yang dicatat per job saat startup
+const maxRecordedSkips = 50
+
+// IsValidMisfirePolicy: Cek nilai misfire_policy
+func IsValidMisfirePolicy(policy string) bool {
+	switch policy {
+	case MisfirePolicyRunOnce, MisfirePolicySkip, MisfirePolicyRunAllMissed:
+		return true
+	}
+	return false
+}
+
+// skippedOccurrence: Jadwal terlewat yang tidak dijalankan beserta alasannya
+type skippedOccurrence struct {
+	At     time.Time
+	Reason string
+}
+
+// missedOccurrences: Semua jadwal setelah since hingga now (maksimal maxMisfireScan).
+// truncated=true jika jumlah sebenarnya lebih banyak. Jam dinding yang terulang saat DST mundur
+// (mis. 01:30 EDT lalu 01:30 EST) dihitung sekali.
+func missedOccurrences(sched cron.Schedule, since, now time.Time) (missed []time.Time, truncated bool) {
+	for t := sched.Next(since); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		if n := len(missed); n > 0 && sameWallClock(missed[n-1], t) {
+			continue
+		}
+		if len(missed) >= maxMisfireScan {
+			return missed, true
+		}
+		missed = append(missed, t)
+	}
+	return missed, false
+}
+
+// sameWallClock: Tanggal & jam lokal sama (berbeda offset UTC karena DST)
+func sameWallClock(a, b time.Time) bool {
+	a = a.In(b.Location())
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay() &&
+		a.Hour() == b.Hour() && a.Minute() == b.Minute() && a.Second() == b.Second()
+}
+
+// planMisfires: Membagi jadwal terlewat menjadi yang dijalankan dan yang dilewati sesuai kebijakan job
+func planMisfires(job models.ScheduledJob, missed []time.Time, now time.Time) (toRun []time.Time, skipped []skippedOccurrence) {
+	var eligible []time.Time
+	for _, t := range missed {
+		if job.MaxLatenessSec > 0 && now.Sub(t) > time.Duration(job.MaxLatenessSec)*time.Second {
+			skipped = append(skipped, skippedOccurrence{
+				At:     t,
+				Reason: fmt.Sprintf("Jadwal terlewat lebih dari max_lateness (%d detik)", job.MaxLatenessSec),
+			})
+			continue
+		}
+		eligible = append(eligible, t)
+	}
+
+	switch job.MisfirePolicy {
+	case MisfirePolicySkip:
+		for _, t := range eligible {
+			skipped = append(skipped, skippedOccurrence{At: t, Reason: "Jadwal terlewat, misfire_policy=skip"})
+		}
+
+	case MisfirePolicyRunAllMissed:
+		// Yang paling lama dilewati jika melebihi batas, run susulan tetap yang terbaru
+		if over := len(eligible) - MaxMisfireCatchup; over > 0 {
+			for _, t := range eligible[:over] {
+				skipped = append(skipped, skippedOccurrence{
+					At:     t,
+					Reason: fmt.Sprintf("Jadwal terlewat melebihi batas run susulan (%d)", MaxMisfireCatchup),
+				})
+			}
+			eligible = eligible[over:]
+		}
+		toRun = eligible
+
+	default: // run_once
+		if len(eligible) > 0 {
+			last := len(eligible) - 1
+			for _, t := range eligible[:last] {
+				skipped = append(skipped, skippedOccurrence{At: t, Reason: "Jadwal terlewat digabung ke satu run susulan (misfire_policy=run_once)"})
+			}
+			toRun = eligible[last:]
+		}
+	}
+	return toRun, skipped
+}
+
+// RecordSkippedRun: Mencatat jadwal yang tidak dijalankan sebagai JobRun SKIPPED agar celah jadwal terlihat di riwayat
+func (s *backupServiceImpl) RecordSkippedRun(job models.ScheduledJob, scheduledFor time.Time, reason string) error {
+	jobID := job.ID
+	at := scheduledFor
+	run := &models.JobRun{
+		JobID:            &jobID,
+		JobName:          job.JobName,
+		OperationMode:    job.OperationMode,
+		RemoteName:       job.RemoteName,
+		TriggerSource:    TriggerSchedule,
+		Attempt:          1,
+		ScheduledFor:     &at,
+		Status:           "SKIPPED",
+		StartedAt:        scheduledFor,
+		FinishedAt:       &at,
+		PreScriptStatus:  "SKIPPED",
+		TransferStatus:   "SKIPPED",
+		PostScriptStatus: "SKIPPED",
+		ErrorMessage:     reason,
+	}
+	return s.RunRepo.Create(run)
+}
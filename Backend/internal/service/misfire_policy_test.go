@@ -0,0 +1,213 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// hoursAt: Jadwal per jam mulai start (UTC)
+func hoursAt(start time.Time, n int) []time.Time {
+	out := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, start.Add(time.Duration(i)*time.Hour))
+	}
+	return out
+}
+
+func skippedTimes(skipped []skippedOccurrence) []time.Time {
+	out := []time.Time{}
+	for _, s := range skipped {
+		out = append(out, s.At)
+	}
+	return out
+}
+
+func TestPlanMisfires(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(12 * time.Hour)
+	missed := hoursAt(start, 12) // 00:00 .. 11:00
+
+	tests := []struct {
+		name        string
+		job         models.ScheduledJob
+		missed      []time.Time
+		wantRun     []time.Time
+		wantSkipped []time.Time
+		reason      string // Potongan alasan skip pertama
+	}{
+		{
+			name:        "run_once: hanya jadwal terakhir",
+			job:         models.ScheduledJob{MisfirePolicy: MisfirePolicyRunOnce},
+			missed:      missed,
+			wantRun:     missed[11:],
+			wantSkipped: missed[:11],
+			reason:      "run_once",
+		},
+		{
+			name:        "kebijakan kosong = run_once",
+			job:         models.ScheduledJob{},
+			missed:      missed[:2],
+			wantRun:     missed[1:2],
+			wantSkipped: missed[:1],
+			reason:      "run_once",
+		},
+		{
+			name:        "skip: semua dilewati",
+			job:         models.ScheduledJob{MisfirePolicy: MisfirePolicySkip},
+			missed:      missed[:3],
+			wantRun:     []time.Time{},
+			wantSkipped: missed[:3],
+			reason:      "misfire_policy=skip",
+		},
+		{
+			name:        "run_all_missed: dibatasi MaxMisfireCatchup, yang terbaru dijalankan",
+			job:         models.ScheduledJob{MisfirePolicy: MisfirePolicyRunAllMissed},
+			missed:      missed,
+			wantRun:     missed[12-MaxMisfireCatchup:],
+			wantSkipped: missed[:12-MaxMisfireCatchup],
+			reason:      "batas run susulan",
+		},
+		{
+			name:        "run_all_missed di bawah batas",
+			job:         models.ScheduledJob{MisfirePolicy: MisfirePolicyRunAllMissed},
+			missed:      missed[:3],
+			wantRun:     missed[:3],
+			wantSkipped: []time.Time{},
+		},
+		{
+			name:        "max_lateness membuang jadwal lama sebelum kebijakan diterapkan",
+			job:         models.ScheduledJob{MisfirePolicy: MisfirePolicyRunAllMissed, MaxLatenessSec: 3 * 3600},
+			missed:      missed,
+			wantRun:     missed[9:], // 09:00, 10:00, 11:00 (tepat 3 jam masih boleh)
+			wantSkipped: missed[:9],
+			reason:      "max_lateness",
+		},
+		{
+			name:        "max_lateness + run_once: semua terlalu lama",
+			job:         models.ScheduledJob{MisfirePolicy: MisfirePolicyRunOnce, MaxLatenessSec: 60},
+			missed:      missed[:2],
+			wantRun:     []time.Time{},
+			wantSkipped: missed[:2],
+			reason:      "max_lateness",
+		},
+		{
+			name:        "tidak ada jadwal terlewat",
+			job:         models.ScheduledJob{MisfirePolicy: MisfirePolicyRunOnce},
+			missed:      nil,
+			wantRun:     []time.Time{},
+			wantSkipped: []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toRun, skipped := planMisfires(tt.job, tt.missed, now)
+			if toRun == nil {
+				toRun = []time.Time{}
+			}
+			if !reflect.DeepEqual(toRun, tt.wantRun) {
+				t.Errorf("toRun = %v, want %v", toRun, tt.wantRun)
+			}
+			if got := skippedTimes(skipped); !reflect.DeepEqual(got, tt.wantSkipped) {
+				t.Errorf("skipped = %v, want %v", got, tt.wantSkipped)
+			}
+			if tt.reason != "" && !strings.Contains(skipped[0].Reason, tt.reason) {
+				t.Errorf("reason = %q, want mengandung %q", skipped[0].Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestMissedOccurrences(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata tidak tersedia: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		expr          string
+		tz            string
+		since, now    time.Time
+		want          []time.Time
+		wantTruncated bool
+	}{
+		{
+			name:  "since eksklusif, now inklusif",
+			expr:  "0 * * * *",
+			since: time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC),
+			now:   time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC),
+			want:  []time.Time{time.Date(2026, 10, 1, 2, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "tidak ada jadwal di rentang",
+			expr:  "0 2 * * MON",
+			since: time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC), // Selasa
+			now:   time.Date(2026, 10, 11, 23, 0, 0, 0, time.UTC),
+			want:  nil,
+		},
+		{
+			// 2026-03-08: America/New_York maju ke EDT; jadwal tetap jam 09:00 lokal (offset UTC berubah)
+			name:  "DST maju di zona waktu job",
+			expr:  "0 9 * * *",
+			tz:    "America/New_York",
+			since: time.Date(2026, 3, 7, 0, 0, 0, 0, ny),
+			now:   time.Date(2026, 3, 9, 23, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 7, 14, 0, 0, 0, time.UTC), // EST (-5)
+				time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC), // EDT (-4)
+				time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// 2026-11-01: mundur ke EST, jam 01:30 terjadi dua kali tapi jadwal harian hanya sekali
+			name:  "DST mundur tidak menggandakan jadwal harian",
+			expr:  "30 1 * * *",
+			tz:    "America/New_York",
+			since: time.Date(2026, 10, 31, 12, 0, 0, 0, ny),
+			now:   time.Date(2026, 11, 2, 12, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+				time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC), // 01:30 EST
+			},
+		},
+		{
+			name:          "dibatasi maxMisfireScan",
+			expr:          "* * * * * *",
+			since:         time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			now:           time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC),
+			wantTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := parseScheduleInZone(tt.expr, tt.tz)
+			if err != nil {
+				t.Fatalf("parse %q: %v", tt.expr, err)
+			}
+			missed, truncated := missedOccurrences(sched, tt.since, tt.now)
+			if truncated != tt.wantTruncated {
+				t.Fatalf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if tt.wantTruncated {
+				if len(missed) != maxMisfireScan {
+					t.Errorf("len(missed) = %d, want %d", len(missed), maxMisfireScan)
+				}
+				return
+			}
+			if len(missed) != len(tt.want) {
+				t.Fatalf("missed = %v, want %v", missed, tt.want)
+			}
+			for i := range missed {
+				if !missed[i].Equal(tt.want[i]) {
+					t.Errorf("missed[%d] = %v, want %v", i, missed[i].UTC(), tt.want[i])
+				}
+			}
+		})
+	}
+}
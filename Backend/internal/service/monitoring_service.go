@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +23,7 @@ type MonitoringService interface {
 	DiscoverAndSaveRemote() error
 	RunRemoteChecks() error
 	StartMonitoringDaemon()
+	StopMonitoringDaemon()
 	SyncRemotesWithRclone() error
 	ExtractEmailFromConfig(remoteName string) (string, error)
 }
@@ -30,6 +32,9 @@ type monitoringServiceImpl struct {
 	MonitorRepo repository.MonitoringRepository
 	LogRepo     repository.LogRepository
 	JobRepo     repository.JobRepository
+
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 const (
@@ -42,6 +47,7 @@ func NewMonitoringService(mRepo repository.MonitoringRepository, lRepo repositor
 		MonitorRepo: mRepo,
 		LogRepo:     lRepo,
 		JobRepo:     jRepo,
+		stop:        make(chan struct{}),
 	}
 }
 
@@ -294,6 +300,10 @@ func (s *monitoringServiceImpl) StartMonitoringDaemon() {
 
 		for {
 			select {
+			case <-s.stop:
+				fmt.Println("[Daemon] Monitoring Daemon berhenti")
+				return
+
 			case <-statusTicker.C:
 				fmt.Println("[Daemon] Menjalankan pengecekan status remote...")
 				if err := s.RunRemoteChecks(); err != nil {
@@ -314,6 +324,11 @@ func (s *monitoringServiceImpl) StartMonitoringDaemon() {
 	}()
 }
 
+// StopMonitoringDaemon: Menghentikan loop pengecekan remote
+func (s *monitoringServiceImpl) StopMonitoringDaemon() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
 func (s *monitoringServiceImpl) RunRemoteChecks() error {
 	remotes, err := s.MonitorRepo.FindAllRemotes()
 	if err != nil {
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// Fase eksekusi yang dilaporkan lewat ProgressEvent
+const (
+	PhasePreScript  = "PRE_SCRIPT"
+	PhaseTransfer   = "TRANSFER"
+	PhasePostScript = "POST_SCRIPT"
+	PhaseDone       = "DONE"
+)
+
+// progressSubscriberBuffer: Event yang tidak sempat dibaca subscriber lambat akan di-drop (kecuali DONE)
+const progressSubscriberBuffer = 16
+
+// ProgressFile: File yang sedang ditransfer rclone
+type ProgressFile struct {
+	Name     string  `json:"name"`
+	Size     int64   `json:"size"`
+	Bytes    int64   `json:"bytes"`
+	Percent  float64 `json:"percent"`
+	SpeedBps float64 `json:"speed_bps"`
+}
+
+// ProgressEvent: Snapshot progress satu job pada satu waktu
+type ProgressEvent struct {
+	JobID          uint           `json:"job_id"`
+	RunID          uint           `json:"run_id"`
+	RestoreID      uint           `json:"restore_id,omitempty"` // Record restore (job restore one-shot ber-ID 0)
+	JobName        string         `json:"job_name"`
+	Phase          string         `json:"phase"`
+	Status         string         `json:"status,omitempty"` // Diisi saat Phase == DONE
+	Bytes          int64          `json:"bytes"`
+	TotalBytes     int64          `json:"total_bytes"`
+	Percent        float64        `json:"percent"`
+	SpeedBps       float64        `json:"speed_bps"`
+	EtaSec         *int64         `json:"eta_sec"`
+	Transfers      int64          `json:"transfers"`
+	TotalTransfers int64          `json:"total_transfers"`
+	Errors         int64          `json:"errors"`
+	LastError      string         `json:"last_error,omitempty"`
+	CurrentFiles   []ProgressFile `json:"current_files"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// progressKey: Stream progress per job, atau per record restore (semua job restore ber-ID 0)
+type progressKey struct {
+	jobID     uint
+	restoreID uint
+}
+
+// key: Stream tujuan event
+func (ev ProgressEvent) key() progressKey {
+	if ev.RestoreID != 0 {
+		return progressKey{restoreID: ev.RestoreID}
+	}
+	return progressKey{jobID: ev.JobID}
+}
+
+// newProgressEvent: Event awal satu fase; run restore dikirim ke stream record restore-nya
+func newProgressEvent(job models.ScheduledJob, run *models.JobRun, phase string) ProgressEvent {
+	ev := ProgressEvent{JobID: job.ID, RunID: run.ID, JobName: job.JobName, Phase: phase}
+	if run.RestoreRecordID != nil {
+		ev.RestoreID = *run.RestoreRecordID
+	}
+	return ev
+}
+
+// ProgressBus: Event bus in-process untuk progress job (dipakai oleh SSE handler)
+type ProgressBus struct {
+	mu     sync.Mutex
+	subs   map[progressKey]map[chan ProgressEvent]struct{}
+	latest map[progressKey]ProgressEvent
+}
+
+func NewProgressBus() *ProgressBus {
+	return &ProgressBus{
+		subs:   make(map[progressKey]map[chan ProgressEvent]struct{}),
+		latest: make(map[progressKey]ProgressEvent),
+	}
+}
+
+// Publish: Menyimpan event sebagai snapshot terbaru dan mengirimnya ke semua subscriber stream-nya.
+// Event DONE selalu terkirim; snapshot record restore dibuang setelah DONE (ID record tidak dipakai ulang).
+func (b *ProgressBus) Publish(ev ProgressEvent) {
+	ev.UpdatedAt = time.Now()
+	key := ev.key()
+	done := ev.Phase == PhaseDone
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if done && key.restoreID != 0 {
+		delete(b.latest, key)
+	} else {
+		b.latest[key] = ev
+	}
+	for ch := range b.subs[key] {
+		if done {
+			sendDropOldest(ch, ev)
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+			// Subscriber lambat, event ini dilewati (snapshot tetap tersedia)
+		}
+	}
+}
+
+// sendDropOldest: Mengirim event, membuang event terlama di buffer jika penuh. Wajib memegang b.mu
+// (hanya Publish yang mengirim ke channel subscriber, jadi ruang yang dikosongkan tidak direbut).
+func sendDropOldest(ch chan ProgressEvent, ev ProgressEvent) {
+	for {
+		select {
+		case ch <- ev:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// Subscribe: Berlangganan event satu job. Panggil fungsi unsubscribe saat selesai.
+func (b *ProgressBus) Subscribe(jobID uint) (<-chan ProgressEvent, func()) {
+	return b.subscribe(progressKey{jobID: jobID})
+}
+
+// SubscribeRestore: Berlangganan event satu record restore
+func (b *ProgressBus) SubscribeRestore(recordID uint) (<-chan ProgressEvent, func()) {
+	return b.subscribe(progressKey{restoreID: recordID})
+}
+
+func (b *ProgressBus) subscribe(key progressKey) (<-chan ProgressEvent, func()) {
+	ch := make(chan ProgressEvent, progressSubscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[key] == nil {
+		b.subs[key] = make(map[chan ProgressEvent]struct{})
+	}
+	b.subs[key][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs[key], ch)
+		if len(b.subs[key]) == 0 {
+			delete(b.subs, key)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// Snapshot: Event terakhir untuk job (untuk client yang polling)
+func (b *ProgressBus) Snapshot(jobID uint) (ProgressEvent, bool) {
+	return b.snapshot(progressKey{jobID: jobID})
+}
+
+// SnapshotRestore: Event terakhir untuk record restore
+func (b *ProgressBus) SnapshotRestore(recordID uint) (ProgressEvent, bool) {
+	return b.snapshot(progressKey{restoreID: recordID})
+}
+
+func (b *ProgressBus) snapshot(key progressKey) (ProgressEvent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ev, ok := b.latest[key]
+	return ev, ok
+}
+
+// rcloneJSONLog: Satu baris output rclone --use-json-log
+type rcloneJSONLog struct {
+	Level  string           `json:"level"`
+	Msg    string           `json:"msg"`
+	Object string           `json:"object"`
+	Stats  *rcloneJSONStats `json:"stats"`
+}
+
+type rcloneJSONStats struct {
+	Bytes          int64   `json:"bytes"`
+	TotalBytes     int64   `json:"totalBytes"`
+	Speed          float64 `json:"speed"`
+	Eta            *int64  `json:"eta"`
+	Errors         int64   `json:"errors"`
+	LastError      string  `json:"lastError"`
+	Transfers      int64   `json:"transfers"`
+	TotalTransfers int64   `json:"totalTransfers"`
+	Transferring   []struct {
+		Name       string  `json:"name"`
+		Size       int64   `json:"size"`
+		Bytes      int64   `json:"bytes"`
+		Percentage float64 `json:"percentage"`
+		Speed      float64 `json:"speed"`
+	} `json:"transferring"`
+}
+
+// parseRcloneJSONLine: Parse satu baris JSON log rclone. ok=false jika baris bukan JSON.
+func parseRcloneJSONLine(line string) (rcloneJSONLog, bool) {
+	var entry rcloneJSONLog
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return entry, false
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+// applyStats: Mengisi field progress dari blok "stats" rclone
+func (ev *ProgressEvent) applyStats(stats *rcloneJSONStats) {
+	ev.Bytes = stats.Bytes
+	ev.TotalBytes = stats.TotalBytes
+	ev.SpeedBps = stats.Speed
+	ev.EtaSec = stats.Eta
+	ev.Transfers = stats.Transfers
+	ev.TotalTransfers = stats.TotalTransfers
+	ev.Errors = stats.Errors
+	if stats.LastError != "" {
+		ev.LastError = stats.LastError
+	}
+
+	ev.Percent = 0
+	if stats.TotalBytes > 0 {
+		ev.Percent = float64(stats.Bytes) / float64(stats.TotalBytes) * 100
+	}
+
+	ev.CurrentFiles = make([]ProgressFile, 0, len(stats.Transferring))
+	for _, t := range stats.Transferring {
+		ev.CurrentFiles = append(ev.CurrentFiles, ProgressFile{
+			Name:     t.Name,
+			Size:     t.Size,
+			Bytes:    t.Bytes,
+			Percent:  t.Percentage,
+			SpeedBps: t.Speed,
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import "testing"
+
+func TestProgressBusRestoreStreams(t *testing.T) {
+	b := NewProgressBus()
+	eventsA, unsubA := b.SubscribeRestore(11)
+	defer unsubA()
+	jobEvents, unsubJob := b.Subscribe(0)
+	defer unsubJob()
+
+	b.Publish(ProgressEvent{JobID: 0, RestoreID: 11, Phase: PhaseTransfer, Bytes: 100})
+	b.Publish(ProgressEvent{JobID: 0, RestoreID: 12, Phase: PhaseTransfer, Bytes: 200})
+
+	if ev := <-eventsA; ev.RestoreID != 11 || ev.Bytes != 100 {
+		t.Errorf("stream restore #11 menerima %+v", ev)
+	}
+	select {
+	case ev := <-eventsA:
+		t.Errorf("stream restore #11 menerima event restore lain: %+v", ev)
+	case ev := <-jobEvents:
+		t.Errorf("stream job 0 menerima event restore: %+v", ev)
+	default:
+	}
+
+	if ev, ok := b.SnapshotRestore(12); !ok || ev.Bytes != 200 {
+		t.Errorf("SnapshotRestore(12) = %+v, %v", ev, ok)
+	}
+	if _, ok := b.Snapshot(0); ok {
+		t.Errorf("Snapshot(0) berisi event restore")
+	}
+}
+
+func TestProgressBusDoneAlwaysDelivered(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      ProgressEvent
+		keepLatest bool
+	}{
+		{name: "job terjadwal", event: ProgressEvent{JobID: 5}, keepLatest: true},
+		{name: "record restore", event: ProgressEvent{RestoreID: 21}, keepLatest: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewProgressBus()
+			key := tt.event.key()
+			events, unsubscribe := b.subscribe(key)
+			defer unsubscribe()
+
+			// Subscriber tidak membaca sama sekali: buffer penuh sebelum DONE
+			for i := 0; i < progressSubscriberBuffer+4; i++ {
+				ev := tt.event
+				ev.Phase = PhaseTransfer
+				ev.Bytes = int64(i)
+				b.Publish(ev)
+			}
+			done := tt.event
+			done.Phase = PhaseDone
+			done.Status = "SUCCESS"
+			b.Publish(done)
+
+			var last ProgressEvent
+			for len(events) > 0 {
+				last = <-events
+			}
+			if last.Phase != PhaseDone || last.Status != "SUCCESS" {
+				t.Fatalf("event terakhir = %+v, want DONE", last)
+			}
+			if _, ok := b.snapshot(key); ok != tt.keepLatest {
+				t.Errorf("snapshot tersimpan = %v, want %v", ok, tt.keepLatest)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// LoadRunningLease: Batas umur lock RUNNING sebelum dianggap yatim (ENV RUNNING_LEASE_MINUTES, default 6 jam)
+func LoadRunningLease() time.Duration {
+	return time.Duration(envInt("RUNNING_LEASE_MINUTES", 360)) * time.Minute
+}
+
+// RecoverInterruptedJobs: Merekonsiliasi job/run berstatus RUNNING yang tidak punya run hidup
+// di proses ini (misal backend crash di tengah backup). Hanya yang mulai lebih lama dari lease
+// yang disentuh; lease 0 dipakai saat startup (semua RUNNING pasti yatim).
+// Mengembalikan jumlah job yang ditandai INTERRUPTED.
+func (s *backupServiceImpl) RecoverInterruptedJobs(lease time.Duration) (int, error) {
+	cutoff := time.Now().Add(-lease)
+
+	// 1. Riwayat run yang tertinggal RUNNING
+	runs, err := s.RunRepo.FindStaleRunning(cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("gagal mengambil run RUNNING: %w", err)
+	}
+	for i := range runs {
+		run := &runs[i]
+		if s.Registry.IsRunLive(run.ID) || (run.JobID != nil && s.Registry.IsJobRunning(*run.JobID)) {
+			continue
+		}
+		now := time.Now()
+		run.Status = "INTERRUPTED"
+		run.FinishedAt = &now
+		run.DurationSec = int(now.Sub(run.StartedAt).Seconds())
+		run.ErrorMessage = "Run terputus: tidak ada proses hidup untuk run ini (backend crash/restart)"
+		if err := s.RunRepo.Save(run); err != nil {
+			fmt.Printf("⚠️ [RECOVERY] Gagal menandai run %d INTERRUPTED: %v\n", run.ID, err)
+		}
+	}
+
+	// 2. Lock RUNNING di tabel job
+	jobs, err := s.JobRepo.FindStaleRunningJobs(cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("gagal mengambil job RUNNING: %w", err)
+	}
+
+	recovered := 0
+	for _, job := range jobs {
+		if s.Registry.IsJobRunning(job.ID) {
+			continue
+		}
+		s.markJobInterrupted(job)
+		recovered++
+	}
+
+	if recovered > 0 {
+		fmt.Printf("✅ [RECOVERY] %d job RUNNING yatim ditandai INTERRUPTED\n", recovered)
+	}
+	return recovered, nil
+}
+
+// markJobInterrupted: Set status INTERRUPTED, tulis log, dan antrekan ulang jika diminta job
+func (s *backupServiceImpl) markJobInterrupted(job models.ScheduledJob) {
+	startedAt := "tidak diketahui"
+	if job.LastRun != nil {
+		startedAt = job.LastRun.Format("2006-01-02 15:04:05")
+	}
+	msg := fmt.Sprintf("Job terputus saat RUNNING (mulai %s) tanpa proses yang hidup, kemungkinan backend crash/restart.", startedAt)
+
+	if err := s.JobRepo.UpdateStatus(job.ID, "INTERRUPTED"); err != nil {
+		fmt.Printf("⚠️ [RECOVERY] Gagal update status Job %d: %v\n", job.ID, err)
+		return
+	}
+
+	LogMutex.Lock()
+	jobID := job.ID
+	err := s.LogRepo.CreateLog(&models.Log{
+		JobID:      &jobID,
+		JobName:    job.JobName,
+		SourcePath: job.SourcePath,
+		Status:     "INTERRUPTED",
+		Message:    msg,
+		Timestamp:  time.Now(),
+	})
+	LogMutex.Unlock()
+	if err != nil {
+		fmt.Printf("⚠️ [RECOVERY] Gagal menulis log Job %d: %v\n", job.ID, err)
+	}
+
+	fmt.Printf("⚠️ [RECOVERY] Job %d (%s): %s\n", job.ID, job.JobName, msg)
+
+	if job.RequeueOnInterrupt {
+		if err := s.Dispatcher.Enqueue(job, RunOptions{Trigger: TriggerRecovery}); err != nil {
+			fmt.Printf("⚠️ [RECOVERY] Gagal antre ulang Job %d: %v\n", job.ID, err)
+			return
+		}
+		fmt.Printf("[RECOVERY] 🔁 Job %d (%s) dimasukkan ulang ke antrean\n", job.ID, job.JobName)
+	}
+}
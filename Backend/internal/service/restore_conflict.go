@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Conflict policy restore: perlakuan terhadap file yang sudah ada di tujuan
+const (
+	ConflictOverwrite      = "overwrite"       // Timpa file yang berbeda (default)
+	ConflictSkipExisting   = "skip-existing"   // Jangan sentuh file yang sudah ada
+	ConflictKeepNewer      = "keep-newer"      // Timpa hanya jika file di tujuan lebih lama
+	ConflictRenameRestored = "rename-restored" // File yang bentrok dipulihkan dengan nama baru di sebelahnya
+)
+
+// FileRenamed: Status hasil restore untuk file yang dipulihkan dengan nama baru (rename-restored)
+const FileRenamed = "RENAMED"
+
+// defaultQuarantineDir: Folder induk safety snapshot jika RESTORE_QUARANTINE_DIR kosong
+const defaultQuarantineDir = "quarantine"
+
+// restoreStagingPrefix: Folder sementara di dalam tujuan untuk file rename-restored
+const restoreStagingPrefix = ".gbackup-restore-"
+
+// ValidateConflictPolicy: Policy kosong dianggap overwrite
+func ValidateConflictPolicy(policy string) (string, error) {
+	switch policy {
+	case "":
+		return ConflictOverwrite, nil
+	case ConflictOverwrite, ConflictSkipExisting, ConflictKeepNewer, ConflictRenameRestored:
+		return policy, nil
+	}
+	return "", fmt.Errorf("%w: conflict_policy '%s' tidak dikenal (overwrite, skip-existing, keep-newer, rename-restored)",
+		ErrInvalidRestore, policy)
+}
+
+// conflictPolicyArgs: Flag rclone untuk conflict policy.
+// rename-restored memakai --ignore-existing; file yang bentrok disalin terpisah setelahnya.
+func conflictPolicyArgs(policy string) []string {
+	switch policy {
+	case ConflictSkipExisting, ConflictRenameRestored:
+		return []string{"--ignore-existing"}
+	case ConflictKeepNewer:
+		return []string{"--update"}
+	}
+	return nil
+}
+
+// QuarantineRoot: Folder induk safety snapshot (ENV RESTORE_QUARANTINE_DIR), selalu absolut
+func QuarantineRoot() (string, error) {
+	root := strings.TrimSpace(os.Getenv("RESTORE_QUARANTINE_DIR"))
+	if root == "" {
+		root = defaultQuarantineDir
+	}
+	return filepath.Abs(root)
+}
+
+// quarantinePathFor: Folder safety snapshot bertimestamp untuk satu restore.
+// Tidak boleh berada di dalam tujuan restore (rclone --backup-dir tidak boleh overlap).
+func quarantinePathFor(jobName, target string, at time.Time) (string, error) {
+	root, err := QuarantineRoot()
+	if err != nil {
+		return "", fmt.Errorf("folder quarantine tidak valid: %w", err)
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, jobName)
+	quarantine := filepath.Join(root, fmt.Sprintf("%s_%s", name, at.Format(snapshotTimeLayout)))
+
+	target = filepath.Clean(target)
+	if quarantine == target || strings.HasPrefix(quarantine, target+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: folder quarantine %s berada di dalam tujuan restore %s, atur RESTORE_QUARANTINE_DIR",
+			ErrInvalidRestore, quarantine, target)
+	}
+	return quarantine, nil
+}
+
+// restoreLocalPath: Path lokal tujuan untuk satu file di snapshot
+func restoreLocalPath(target, rel string, singleFile bool) string {
+	if singleFile {
+		return target
+	}
+	return filepath.Join(target, filepath.FromSlash(rel))
+}
+
+// renamedRestorePath: nama.restored-YYYYMMDD_HHMMSS.ext di folder yang sama
+func renamedRestorePath(localPath string, at time.Time) string {
+	ext := filepath.Ext(localPath)
+	base := strings.TrimSuffix(localPath, ext)
+	return fmt.Sprintf("%s.restored-%s%s", base, at.Format(snapshotTimeLayout), ext)
+}
+
+// detectRestoreConflicts: File snapshot yang sudah ada di tujuan dengan isi berbeda (ukuran/modtime).
+// Dijalankan sebelum transfer agar file baru hasil restore tidak dianggap bentrok.
+func detectRestoreConflicts(ctx context.Context, remoteSrc, target string, o *RestoreOptions) ([]rcloneManifestEntry, error) {
+	files, err := listRestoreSource(ctx, remoteSrc, o)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []rcloneManifestEntry
+	for _, f := range files {
+		info, err := os.Stat(restoreLocalPath(target, f.Path, o.SingleFile))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		sameSize := info.Size() == f.Size
+		sameTime := info.ModTime().Truncate(time.Second).Equal(f.ModTime.Truncate(time.Second))
+		if sameSize && sameTime {
+			continue
+		}
+		conflicts = append(conflicts, f)
+	}
+	return conflicts, nil
+}
+
+// restoreRenamedConflicts: Menyalin file yang bentrok ke folder staging di dalam tujuan,
+// lalu memindahkannya ke sebelah file asli dengan akhiran .restored-<timestamp>
+func restoreRenamedConflicts(ctx context.Context, remoteSrc, target string, o *RestoreOptions, conflicts []rcloneManifestEntry, tracker *transferFileTracker) error {
+	if len(conflicts) == 0 {
+		return nil
+	}
+	now := time.Now()
+
+	stagingParent := target
+	if o.SingleFile {
+		stagingParent = filepath.Dir(target)
+	}
+	staging := filepath.Join(stagingParent, restoreStagingPrefix+now.Format(snapshotTimeLayout))
+	defer os.RemoveAll(staging)
+
+	var result RcloneResult
+	if o.SingleFile {
+		result = ExecuteCliJobContext(ctx, []string{"rclone", "copyto", remoteSrc, filepath.Join(staging, filepath.Base(target))})
+	} else {
+		listFile, err := os.CreateTemp("", "gbackup-restore-*.txt")
+		if err != nil {
+			return fmt.Errorf("gagal membuat daftar file bentrok: %w", err)
+		}
+		defer os.Remove(listFile.Name())
+		for _, f := range conflicts {
+			fmt.Fprintln(listFile, f.Path)
+		}
+		listFile.Close()
+		result = ExecuteCliJobContext(ctx, []string{"rclone", "copy", remoteSrc, staging, "--files-from-raw", listFile.Name()})
+	}
+	if !result.Success {
+		return fmt.Errorf("gagal menyalin file bentrok: %s", result.ErrorMsg)
+	}
+
+	var failed []string
+	for _, f := range conflicts {
+		staged := restoreLocalPath(staging, f.Path, false)
+		if o.SingleFile {
+			staged = filepath.Join(staging, filepath.Base(target))
+		}
+		renamed := renamedRestorePath(restoreLocalPath(target, f.Path, o.SingleFile), now)
+		if err := os.Rename(staged, renamed); err != nil {
+			failed = append(failed, f.Path)
+			if tracker != nil {
+				tracker.failed[f.Path] = err.Error()
+			}
+			continue
+		}
+		if tracker != nil {
+			tracker.renamed[f.Path] = renamed
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("gagal memindahkan %d file bentrok: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
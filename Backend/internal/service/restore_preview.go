@@ -0,0 +1,356 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/repository"
+)
+
+// Error preview restore yang dipetakan handler ke status HTTP
+var (
+	ErrPreviewNotFound      = errors.New("preview restore tidak ditemukan")
+	ErrPreviewNotExecutable = errors.New("preview restore tidak bisa dijalankan")
+)
+
+// restorePreviewTTL: Masa berlaku preview sebelum harus dibuat ulang (isi tujuan bisa berubah)
+const restorePreviewTTL = 24 * time.Hour
+
+// restorePreviewTimeout: Batas waktu perbandingan (rclone check membaca hash file lokal)
+const restorePreviewTimeout = 10 * time.Minute
+
+// maxPreviewFiles: Batas jumlah path per kategori yang disimpan (jumlah tetap dihitung penuh)
+const maxPreviewFiles = 10000
+
+// RestorePreviewFiles: Daftar file per kategori perbandingan snapshot vs tujuan
+type RestorePreviewFiles struct {
+	Added     []string `json:"added"`     // Ada di snapshot, belum ada di tujuan
+	Modified  []string `json:"modified"`  // Ada di keduanya dengan isi berbeda
+	Deleted   []string `json:"deleted"`   // Hanya ada di tujuan (restore copy tidak menghapusnya)
+	Identical []string `json:"identical"` // Sama persis, tidak akan disalin
+	Errors    []string `json:"errors"`    // Gagal dibandingkan
+}
+
+// restoreDiff: Hasil perbandingan lengkap sebelum daftar dipotong
+type restoreDiff struct {
+	files     RestorePreviewFiles
+	counts    [5]int // added, modified, deleted, identical, errors
+	truncated bool
+}
+
+// add: Menambah path ke kategori (0..4) dengan batas maxPreviewFiles
+func (d *restoreDiff) add(category int, p string) {
+	d.counts[category]++
+	lists := []*[]string{&d.files.Added, &d.files.Modified, &d.files.Deleted, &d.files.Identical, &d.files.Errors}
+	if len(*lists[category]) >= maxPreviewFiles {
+		d.truncated = true
+		return
+	}
+	*lists[category] = append(*lists[category], p)
+}
+
+// Kategori restoreDiff.add
+const (
+	diffAdded = iota
+	diffModified
+	diffDeleted
+	diffIdentical
+	diffError
+)
+
+// combinedCategory: Prefix baris rclone check --combined ke kategori preview
+var combinedCategory = map[byte]int{
+	'-': diffAdded,     // Hanya di sumber (snapshot)
+	'*': diffModified,  // Berbeda
+	'+': diffDeleted,   // Hanya di tujuan
+	'=': diffIdentical, // Identik
+	'!': diffError,     // Error saat membaca/membandingkan
+}
+
+// diffRestorePlan: Membandingkan isi snapshot (dengan filter pilihan) terhadap tujuan lokal
+func diffRestorePlan(ctx context.Context, plan *RestorePlan) (*restoreDiff, error) {
+	remoteSrc := fmt.Sprintf("%s:%s", plan.Snapshot.RemoteName, plan.RemotePath)
+	o := &RestoreOptions{SingleFile: !plan.Snapshot.IsDir, Paths: plan.Paths, Include: plan.Include}
+	diff := &restoreDiff{}
+
+	if o.SingleFile {
+		return diff, diffSingleFile(ctx, remoteSrc, plan.TargetPath, diff)
+	}
+
+	// Tujuan belum ada: semua file terpilih akan ditambahkan
+	if _, err := os.Stat(plan.TargetPath); os.IsNotExist(err) {
+		files, err := listRestoreSource(ctx, remoteSrc, o)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca isi snapshot: %w", err)
+		}
+		for _, f := range files {
+			diff.add(diffAdded, f.Path)
+		}
+		return diff, nil
+	}
+
+	// rclone check keluar dengan kode != 0 jika ada perbedaan, jadi output --combined tetap dibaca
+	args := append([]string{"check", remoteSrc, plan.TargetPath, "--combined", "-"}, restoreFilterArgs(o)...)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "rclone", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	runErr := cmd.Run()
+
+	parsed := 0
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 3 || line[1] != ' ' {
+			continue
+		}
+		category, ok := combinedCategory[line[0]]
+		if !ok {
+			continue
+		}
+		diff.add(category, line[2:])
+		parsed++
+	}
+	if runErr != nil && parsed == 0 {
+		return nil, fmt.Errorf("rclone check gagal: %v: %s", runErr, strings.TrimSpace(stderr.String()))
+	}
+	return diff, nil
+}
+
+// diffSingleFile: Snapshot berupa file dibandingkan dengan path file tujuan (ukuran lalu MD5)
+func diffSingleFile(ctx context.Context, remoteSrc, target string, diff *restoreDiff) error {
+	name := filepath.Base(target)
+	info, err := os.Stat(target)
+	if os.IsNotExist(err) {
+		diff.add(diffAdded, name)
+		return nil
+	}
+	if err != nil || info.IsDir() {
+		diff.add(diffError, name)
+		return nil
+	}
+
+	output, err := exec.CommandContext(ctx, "rclone", "lsjson", "--hash", remoteSrc).Output()
+	if err != nil {
+		return fmt.Errorf("gagal membaca snapshot di remote: %w", err)
+	}
+	var files []rcloneManifestEntry
+	if err := json.Unmarshal(output, &files); err != nil {
+		return fmt.Errorf("failed to parse rclone output: %w", err)
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("snapshot %s tidak ditemukan di remote", remoteSrc)
+	}
+
+	remote := files[0]
+	if remote.Size != info.Size() {
+		diff.add(diffModified, name)
+		return nil
+	}
+	remoteMD5 := remote.Hashes["md5"]
+	if remoteMD5 == "" {
+		// Remote tanpa MD5: ukuran sama dianggap identik (sama seperti rclone check --size-only)
+		diff.add(diffIdentical, name)
+		return nil
+	}
+	localMD5, err := fileMD5(target)
+	if err != nil {
+		diff.add(diffError, name)
+		return nil
+	}
+	if strings.EqualFold(localMD5, remoteMD5) {
+		diff.add(diffIdentical, name)
+	} else {
+		diff.add(diffModified, name)
+	}
+	return nil
+}
+
+// fileMD5: MD5 hex file lokal
+func fileMD5(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// encodeStringList: JSON array untuk kolom text (nil -> "[]")
+func encodeStringList(list []string) string {
+	if list == nil {
+		list = []string{}
+	}
+	encoded, _ := json.Marshal(list)
+	return string(encoded)
+}
+
+// decodeStringList: Kebalikan encodeStringList
+func decodeStringList(raw string) []string {
+	list := []string{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &list)
+	}
+	return list
+}
+
+// PreviewPaths: Paths & Include tersimpan di preview
+func PreviewPaths(preview *models.RestorePreview) ([]string, []string) {
+	return decodeStringList(preview.Paths), decodeStringList(preview.Include)
+}
+
+// LoadPreviewFiles: Decode kolom Files preview
+func LoadPreviewFiles(preview *models.RestorePreview) RestorePreviewFiles {
+	files := RestorePreviewFiles{}
+	if preview.Files != "" {
+		if err := json.Unmarshal([]byte(preview.Files), &files); err != nil {
+			fmt.Printf("⚠️ [RESTORE] Daftar file preview %d rusak: %v\n", preview.ID, err)
+		}
+	}
+	for _, list := range []*[]string{&files.Added, &files.Modified, &files.Deleted, &files.Identical, &files.Errors} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	return files
+}
+
+// PreviewRestore: Menyusun rencana restore, membandingkannya dengan tujuan, lalu menyimpannya sebagai preview
+func (s *restoreServiceImpl) PreviewRestore(ctx context.Context, req PointInTimeRestoreRequest) (*models.RestorePreview, error) {
+	plan, err := s.PlanPointInTimeRestore(req)
+	if err != nil {
+		return nil, err
+	}
+
+	diffCtx, cancel := context.WithTimeout(ctx, restorePreviewTimeout)
+	defer cancel()
+	diff, err := diffRestorePlan(diffCtx, plan)
+	if err != nil {
+		return nil, err
+	}
+	filesJSON, err := json.Marshal(diff.files)
+	if err != nil {
+		return nil, fmt.Errorf("gagal encode daftar file preview: %w", err)
+	}
+
+	now := time.Now()
+	preview := &models.RestorePreview{
+		JobID:          plan.Job.ID,
+		SnapshotID:     plan.Snapshot.ID,
+		RemotePath:     plan.RemotePath,
+		TargetPath:     plan.TargetPath,
+		InPlace:        plan.InPlace,
+		Paths:          encodeStringList(plan.Paths),
+		Include:        encodeStringList(plan.Include),
+		ConflictPolicy: plan.ConflictPolicy,
+		SafetySnapshot: plan.QuarantinePath != "",
+		AddedCount:     diff.counts[diffAdded],
+		ModifiedCount:  diff.counts[diffModified],
+		DeletedCount:   diff.counts[diffDeleted],
+		IdenticalCount: diff.counts[diffIdentical],
+		ErrorCount:     diff.counts[diffError],
+		Files:          string(filesJSON),
+		Truncated:      diff.truncated,
+		Status:         "READY",
+		ExpiresAt:      now.Add(restorePreviewTTL),
+	}
+	if err := s.PreviewRepo.Create(preview); err != nil {
+		return nil, err
+	}
+	fmt.Printf("[RESTORE] 🔍 Preview %d: snapshot %s → %s (+%d ~%d -%d =%d !%d)\n",
+		preview.ID, plan.Snapshot.Name, plan.TargetPath, preview.AddedCount, preview.ModifiedCount,
+		preview.DeletedCount, preview.IdenticalCount, preview.ErrorCount)
+	return preview, nil
+}
+
+// GetPreview: Mengambil preview tersimpan
+func (s *restoreServiceImpl) GetPreview(previewID uint) (*models.RestorePreview, error) {
+	preview, err := s.PreviewRepo.FindByID(previewID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPreviewNotFound, err)
+	}
+	return preview, nil
+}
+
+// ExecutePreview: Menjalankan persis rencana yang tersimpan di preview (snapshot, tujuan, pilihan & policy).
+// Preview hanya bisa dijalankan sekali dan selama belum kedaluwarsa.
+func (s *restoreServiceImpl) ExecutePreview(previewID uint, requester RestoreRequester) (*RestorePlan, error) {
+	preview, err := s.GetPreview(previewID)
+	if err != nil {
+		return nil, err
+	}
+	if preview.Status != "READY" {
+		return nil, fmt.Errorf("%w: preview %d sudah dijalankan", ErrPreviewNotExecutable, preview.ID)
+	}
+	if time.Now().After(preview.ExpiresAt) {
+		return nil, fmt.Errorf("%w: preview %d kedaluwarsa sejak %s, buat preview baru",
+			ErrPreviewNotExecutable, preview.ID, preview.ExpiresAt.Format(time.RFC3339))
+	}
+
+	job, err := s.JobRepo.FindJobByID(preview.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPreviewNotExecutable, err)
+	}
+	snap, err := s.SnapRepo.FindByID(preview.SnapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPreviewNotExecutable, err)
+	}
+	if snap.Status != "COMPLETE" {
+		return nil, fmt.Errorf("%w: snapshot %s berstatus %s", ErrPreviewNotExecutable, snap.Name, snap.Status)
+	}
+
+	paths, include := PreviewPaths(preview)
+	plan := &RestorePlan{
+		Job:            *job,
+		Snapshot:       *snap,
+		RemotePath:     path.Join(snap.DestinationPath, snap.Name),
+		TargetPath:     preview.TargetPath,
+		InPlace:        preview.InPlace,
+		ResolvedBy:     "preview",
+		Paths:          paths,
+		Include:        include,
+		ConflictPolicy: preview.ConflictPolicy,
+	}
+	if preview.SafetySnapshot {
+		if plan.QuarantinePath, err = quarantinePathFor(job.JobName, plan.TargetPath, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Klaim dulu (atomik) agar dua eksekusi bersamaan tidak sama-sama masuk antrean,
+	// lalu kembalikan ke READY jika restore gagal masuk antrean
+	if err := s.PreviewRepo.MarkExecuted(preview.ID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrPreviewAlreadyExecuted) {
+			return nil, fmt.Errorf("%w: preview %d sudah dijalankan", ErrPreviewNotExecutable, preview.ID)
+		}
+		return nil, err
+	}
+	record := newRestoreRecord(RestoreSourcePreview, requester)
+	previewRef := preview.ID
+	record.PreviewID = &previewRef
+	if err := s.enqueuePlan(plan, record); err != nil {
+		if resetErr := s.PreviewRepo.ResetExecuted(preview.ID); resetErr != nil {
+			fmt.Printf("⚠️ [RESTORE] Gagal mengembalikan preview %d ke READY: %v\n", preview.ID, resetErr)
+		}
+		return nil, err
+	}
+	return plan, nil
+}
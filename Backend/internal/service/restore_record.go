@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// ErrRestoreRecordNotFound: Record restore tidak ada
+var ErrRestoreRecordNotFound = errors.New("record restore tidak ditemukan")
+
+// Asal permintaan restore yang dicatat di RestoreRecord
+const (
+	RestoreSourcePath     = "PATH"     // POST /api/v1/jobs/restore (path remote mentah)
+	RestoreSourceSnapshot = "SNAPSHOT" // POST /api/v1/jobs/:id/restore
+	RestoreSourcePreview  = "PREVIEW"  // POST /api/v1/restore/preview/:id/execute
+)
+
+// Status record restore di luar status akhir run
+const (
+	RestoreStatusQueued      = "QUEUED"
+	RestoreStatusRunning     = "RUNNING"
+	RestoreStatusFailEnqueue = "FAIL_ENQUEUE"
+)
+
+// RestoreRequester: User yang meminta restore (dari token JWT)
+type RestoreRequester struct {
+	UserID   uint
+	Username string
+}
+
+// newRestoreRecord: Record QUEUED dengan data peminta
+func newRestoreRecord(source string, requester RestoreRequester) *models.RestoreRecord {
+	record := &models.RestoreRecord{
+		RequestedBy: requester.Username,
+		Source:      source,
+		Status:      RestoreStatusQueued,
+	}
+	if requester.UserID != 0 {
+		userID := requester.UserID
+		record.RequestedByID = &userID
+	}
+	return record
+}
+
+// RecordPaths: Paths & Include tersimpan di record restore
+func RecordPaths(record *models.RestoreRecord) ([]string, []string) {
+	return decodeStringList(record.Paths), decodeStringList(record.Include)
+}
+
+// enqueueRecorded: Menyimpan record, lalu memasukkan job restore ke antrean dengan RecordID.
+// Nama job diberi ID record agar log & riwayat run bisa ditelusuri balik.
+func (s *restoreServiceImpl) enqueueRecorded(record *models.RestoreRecord, restoreJob models.ScheduledJob, opts *RestoreOptions) error {
+	if err := s.RecordRepo.Create(record); err != nil {
+		return err
+	}
+
+	opts.RecordID = record.ID
+	restoreJob.JobName = fmt.Sprintf("Restore-#%d-%s", record.ID, restoreJob.JobName)
+	if err := s.Dispatcher.Enqueue(restoreJob, RunOptions{Trigger: TriggerAPI, Restore: opts}); err != nil {
+		now := time.Now()
+		record.Status = RestoreStatusFailEnqueue
+		record.ErrorMessage = err.Error()
+		record.FinishedAt = &now
+		if saveErr := s.RecordRepo.Save(record); saveErr != nil {
+			fmt.Printf("⚠️ [RESTORE] Gagal menandai record %d %s: %v\n", record.ID, RestoreStatusFailEnqueue, saveErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// handleRestoreCompleted: Menulis hasil akhir run restore ke record-nya
+func (s *restoreServiceImpl) handleRestoreCompleted(c JobCompletion) {
+	if c.Opts.Restore == nil || c.Opts.Restore.RecordID == 0 {
+		return
+	}
+	record, err := s.RecordRepo.FindByID(c.Opts.Restore.RecordID)
+	if err != nil {
+		fmt.Printf("⚠️ [RESTORE] Record %d untuk run %d: %v\n", c.Opts.Restore.RecordID, c.Run.ID, err)
+		return
+	}
+	applyRunOutcome(record, &c.Run)
+	if err := s.RecordRepo.Save(record); err != nil {
+		fmt.Printf("⚠️ [RESTORE] Gagal menyimpan hasil record %d: %v\n", record.ID, err)
+		return
+	}
+	fmt.Printf("📝 [RESTORE] Record %d selesai: %s (run %d)\n", record.ID, record.Status, c.Run.ID)
+}
+
+// applyRunOutcome: Menyalin status & hasil run ke record
+func applyRunOutcome(record *models.RestoreRecord, run *models.JobRun) {
+	if run.ID != 0 {
+		runID := run.ID
+		record.RunID = &runID
+	}
+	startedAt := run.StartedAt
+	record.StartedAt = &startedAt
+	record.Status = run.Status
+	record.FinishedAt = run.FinishedAt
+	record.TransferredBytes = run.TransferredBytes
+	record.TransferredFiles = run.TransferredFiles
+	record.ErrorMessage = run.ErrorMessage
+}
+
+// GetRestoreRecord: Record beserta run terbarunya (nil jika belum mulai).
+// Record QUEUED yang run-nya sudah berjalan dilaporkan RUNNING; run yang selesai tanpa sempat
+// dicatat listener (mis. INTERRUPTED oleh recovery saat startup) disalin ke record.
+func (s *restoreServiceImpl) GetRestoreRecord(recordID uint) (*models.RestoreRecord, *models.JobRun, error) {
+	record, err := s.RecordRepo.FindByID(recordID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrRestoreRecordNotFound, err)
+	}
+	run, err := s.resolveRecordRun(record)
+	if err != nil {
+		return nil, nil, err
+	}
+	return record, run, nil
+}
+
+// ListRestoreRecords: Record restore terbaru lebih dulu, dengan status yang sama seperti GetRestoreRecord
+func (s *restoreServiceImpl) ListRestoreRecords(limit int) ([]models.RestoreRecord, error) {
+	records, err := s.RecordRepo.FindRecent(limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		if _, err := s.resolveRecordRun(&records[i]); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// CancelRestore: Membatalkan run restore yang sedang berjalan (ErrRunNotFound jika belum/tidak berjalan)
+func (s *restoreServiceImpl) CancelRestore(recordID uint) error {
+	if _, err := s.RecordRepo.FindByID(recordID); err != nil {
+		return fmt.Errorf("%w: %v", ErrRestoreRecordNotFound, err)
+	}
+	return s.BackupSvc.CancelRestore(recordID)
+}
+
+// resolveRecordRun: Mencari run milik record dan menyelaraskan status record yang masih QUEUED
+func (s *restoreServiceImpl) resolveRecordRun(record *models.RestoreRecord) (*models.JobRun, error) {
+	if record.RunID != nil {
+		run, err := s.RunRepo.FindByID(*record.RunID)
+		if err != nil {
+			return nil, nil
+		}
+		return run, nil
+	}
+	if record.Status != RestoreStatusQueued {
+		return nil, nil
+	}
+
+	run, err := s.RunRepo.FindLatestByRestoreRecordID(record.ID)
+	if err != nil || run == nil {
+		return nil, err
+	}
+	if run.Status == "RUNNING" {
+		startedAt := run.StartedAt
+		record.Status = RestoreStatusRunning
+		record.StartedAt = &startedAt
+		return run, nil
+	}
+
+	applyRunOutcome(record, run)
+	if err := s.RecordRepo.Save(record); err != nil {
+		fmt.Printf("⚠️ [RESTORE] Gagal menyelaraskan record %d: %v\n", record.ID, err)
+	}
+	return run, nil
+}
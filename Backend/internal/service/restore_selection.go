@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// Status hasil restore per file
+const (
+	FileRestored    = "RESTORED"     // Disalin dari snapshot
+	FileUnchanged   = "UNCHANGED"    // Tidak disalin (sudah identik atau dilewati conflict policy)
+	FileFailed      = "FAILED"       // rclone melaporkan error untuk file ini
+	FileNotRestored = "NOT_RESTORED" // Transfer berhenti sebelum file ini diproses
+	FileNotFound    = "NOT_FOUND"    // Path yang dipilih tidak ada di snapshot
+	// FileRenamed (rename-restored) didefinisikan bersama conflict policy
+)
+
+// restoreListTimeout: Batas waktu listing file terpilih setelah transfer restore
+const restoreListTimeout = 5 * time.Minute
+
+// maxRestoreFileResults: Batas jumlah hasil per file yang disimpan di JobRun
+const maxRestoreFileResults = 5000
+
+// RestoreFileResult: Hasil restore satu file (atau satu path pilihan yang tidak ditemukan)
+type RestoreFileResult struct {
+	Path    string `json:"path"` // Relatif terhadap root snapshot
+	Size    int64  `json:"size"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// RestoreFileReport: Ringkasan & hasil per file restore selektif (kolom JobRun.RestoreResults)
+type RestoreFileReport struct {
+	Restored    int                 `json:"restored"`
+	Unchanged   int                 `json:"unchanged"`
+	Renamed     int                 `json:"renamed"`
+	Failed      int                 `json:"failed"`
+	NotRestored int                 `json:"not_restored"`
+	NotFound    int                 `json:"not_found"`
+	Truncated   bool                `json:"truncated"`
+	Files       []RestoreFileResult `json:"files"`
+}
+
+// IsSelective: true jika restore hanya untuk sebagian isi snapshot
+func (o *RestoreOptions) IsSelective() bool {
+	return o != nil && (len(o.Paths) > 0 || len(o.Include) > 0)
+}
+
+// tracksFiles: true jika hasil per file dicatat (restore selektif atau rename-restored)
+func (o *RestoreOptions) tracksFiles() bool {
+	return o.IsSelective() || (o != nil && o.ConflictPolicy == ConflictRenameRestored)
+}
+
+// NormalizeRestorePaths: Path pilihan menjadi relatif terhadap root snapshot.
+// Menerima path relatif, path remote lengkap (dari browser), atau path sumber asli (dari catalog).
+func NormalizeRestorePaths(snapshot models.Snapshot, paths []string) ([]string, error) {
+	prefixes := []string{path.Join(snapshot.DestinationPath, snapshot.Name)}
+	if snapshot.SourcePath != "" {
+		prefixes = append(prefixes, snapshot.SourcePath)
+	}
+
+	normalized := make([]string, 0, len(paths))
+	seen := make(map[string]bool)
+	for _, raw := range paths {
+		if hasParentSegment(raw) {
+			return nil, fmt.Errorf("%w: path '%s' tidak boleh mengandung '..'", ErrInvalidRestore, raw)
+		}
+		p := path.Clean("/" + strings.TrimSpace(raw))
+		for _, prefix := range prefixes {
+			prefix = path.Clean("/" + prefix)
+			if p == prefix || strings.HasPrefix(p, prefix+"/") {
+				p = strings.TrimPrefix(p, prefix)
+				break
+			}
+		}
+		p = strings.TrimPrefix(p, "/")
+		if p == "" || p == "." {
+			return nil, fmt.Errorf("%w: path '%s' menunjuk root snapshot, kosongkan paths untuk restore penuh", ErrInvalidRestore, raw)
+		}
+		if !seen[p] {
+			seen[p] = true
+			normalized = append(normalized, p)
+		}
+	}
+	return normalized, nil
+}
+
+// ValidateIncludePatterns: Pola --include tidak boleh kosong atau keluar dari root snapshot
+func ValidateIncludePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("%w: pola include tidak boleh kosong", ErrInvalidRestore)
+		}
+		if hasParentSegment(pattern) {
+			return fmt.Errorf("%w: pola include '%s' tidak boleh mengandung '..'", ErrInvalidRestore, pattern)
+		}
+	}
+	return nil
+}
+
+// hasParentSegment: true jika path berisi segmen ".."
+func hasParentSegment(p string) bool {
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeRcloneGlob: Meloloskan karakter glob rclone agar path dicocokkan apa adanya
+func escapeRcloneGlob(p string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`, "{", `\{`, "}", `\}`).Replace(p)
+}
+
+// restoreFilterArgs: Path pilihan dan pola include menjadi flag --include rclone.
+// Setiap path dicocokkan sebagai file maupun folder (beserta seluruh isinya).
+func restoreFilterArgs(o *RestoreOptions) []string {
+	var args []string
+	for _, p := range o.Paths {
+		anchored := "/" + escapeRcloneGlob(p)
+		args = append(args, "--include", anchored, "--include", anchored+"/**")
+	}
+	for _, pattern := range o.Include {
+		args = append(args, "--include", pattern)
+	}
+	return args
+}
+
+// transferFileTracker: Mengumpulkan hasil per file dari log JSON rclone (-v) selama restore selektif
+// atau backup snapshot (untuk verifikasi)
+type transferFileTracker struct {
+	copied  map[string]bool
+	failed  map[string]string
+	renamed map[string]string // Path di snapshot -> path lokal baru (rename-restored)
+}
+
+func newTransferFileTracker() *transferFileTracker {
+	return &transferFileTracker{
+		copied:  make(map[string]bool),
+		failed:  make(map[string]string),
+		renamed: make(map[string]string),
+	}
+}
+
+// observe: Mencatat baris log "Copied (...)" dan error per object
+func (t *transferFileTracker) observe(entry rcloneJSONLog) {
+	if entry.Object == "" {
+		return
+	}
+	switch {
+	case entry.Level == "error":
+		t.failed[entry.Object] = strings.TrimSpace(entry.Msg)
+	case strings.HasPrefix(entry.Msg, "Copied"):
+		t.copied[entry.Object] = true
+	}
+}
+
+// listRestoreSource: Daftar file di snapshot yang termasuk pilihan restore
+func listRestoreSource(ctx context.Context, remoteSrc string, o *RestoreOptions) ([]rcloneManifestEntry, error) {
+	lsArgs := append([]string{"lsjson", "-R", "--files-only", remoteSrc}, restoreFilterArgs(o)...)
+	output, err := exec.CommandContext(ctx, "rclone", lsArgs...).Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []rcloneManifestEntry
+	if err := json.Unmarshal(output, &files); err != nil {
+		return nil, fmt.Errorf("failed to parse rclone output: %w", err)
+	}
+	return files, nil
+}
+
+// collectRestoreResults: Mencocokkan daftar file terpilih di snapshot dengan hasil transfer.
+// File yang tidak disalin & tidak error dianggap UNCHANGED jika transfer sukses.
+func collectRestoreResults(ctx context.Context, remoteSrc string, o *RestoreOptions, tracker *transferFileTracker, transferOK bool) *RestoreFileReport {
+	report := &RestoreFileReport{Files: []RestoreFileResult{}}
+
+	listed, err := listRestoreSource(ctx, remoteSrc, o)
+	if err != nil {
+		fmt.Printf("⚠️ [RESTORE] Gagal membaca daftar file terpilih di %s: %v\n", remoteSrc, err)
+	}
+
+	results := make(map[string]RestoreFileResult)
+	for _, f := range listed {
+		res := RestoreFileResult{Path: f.Path, Size: f.Size, Status: FileUnchanged}
+		if !transferOK {
+			res.Status = FileNotRestored
+		}
+		results[f.Path] = res
+	}
+	// Log rclone tetap dipakai jika listing gagal (mis. run dibatalkan)
+	for p := range tracker.copied {
+		res := results[p]
+		res.Path, res.Status = p, FileRestored
+		results[p] = res
+	}
+	for p, renamed := range tracker.renamed {
+		res := results[p]
+		res.Path, res.Status, res.Message = p, FileRenamed, renamed
+		results[p] = res
+	}
+	for p, msg := range tracker.failed {
+		res := results[p]
+		res.Path, res.Status, res.Message = p, FileFailed, msg
+		results[p] = res
+	}
+
+	// Path pilihan yang tidak cocok dengan file mana pun (hanya bisa dipastikan jika listing berhasil)
+	if err == nil {
+		for _, p := range o.Paths {
+			found := false
+			for filePath := range results {
+				if filePath == p || strings.HasPrefix(filePath, p+"/") {
+					found = true
+					break
+				}
+			}
+			if !found {
+				results[p] = RestoreFileResult{Path: p, Status: FileNotFound}
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(results))
+	for p := range results {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		res := results[p]
+		switch res.Status {
+		case FileRestored:
+			report.Restored++
+		case FileUnchanged:
+			report.Unchanged++
+		case FileRenamed:
+			report.Renamed++
+		case FileFailed:
+			report.Failed++
+		case FileNotRestored:
+			report.NotRestored++
+		case FileNotFound:
+			report.NotFound++
+		}
+		if len(report.Files) >= maxRestoreFileResults {
+			report.Truncated = true
+			continue
+		}
+		report.Files = append(report.Files, res)
+	}
+	return report
+}
+
+// LoadRestoreReport: Decode kolom RestoreResults (nil jika run bukan restore selektif)
+func LoadRestoreReport(run *models.JobRun) *RestoreFileReport {
+	if run.RestoreResults == "" {
+		return nil
+	}
+	var report RestoreFileReport
+	if err := json.Unmarshal([]byte(run.RestoreResults), &report); err != nil {
+		fmt.Printf("⚠️ [RESTORE] Hasil restore run %d rusak: %v\n", run.ID, err)
+		return nil
+	}
+	return &report
+}
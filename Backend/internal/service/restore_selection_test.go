@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gbackup-new/backend/internal/models"
+)
+
+func TestNormalizeRestorePaths(t *testing.T) {
+	snapshot := models.Snapshot{
+		DestinationPath: "/backup/docs",
+		Name:            "docs_20250102_030405",
+		SourcePath:      "/home/user/docs",
+	}
+
+	tests := []struct {
+		name    string
+		paths   []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "path relatif", paths: []string{"a/b.txt", "c"}, want: []string{"a/b.txt", "c"}},
+		{name: "slash depan & spasi dibuang", paths: []string{" /a/b.txt "}, want: []string{"a/b.txt"}},
+		{name: "path remote lengkap dari browser", paths: []string{"/backup/docs/docs_20250102_030405/a/b.txt"}, want: []string{"a/b.txt"}},
+		{name: "path remote tanpa slash depan", paths: []string{"backup/docs/docs_20250102_030405/a"}, want: []string{"a"}},
+		{name: "path sumber asli dari catalog", paths: []string{"/home/user/docs/a/b.txt"}, want: []string{"a/b.txt"}},
+		{name: "prefix hanya cocok per segmen", paths: []string{"/home/user/docs2/a"}, want: []string{"home/user/docs2/a"}},
+		{name: "duplikat digabung", paths: []string{"a", "/a", "/home/user/docs/a"}, want: []string{"a"}},
+		{name: "segmen .. ditolak", paths: []string{"a/../../etc"}, wantErr: true},
+		{name: ".. di awal ditolak", paths: []string{"../a"}, wantErr: true},
+		{name: "root snapshot ditolak", paths: []string{"/backup/docs/docs_20250102_030405"}, wantErr: true},
+		{name: "root sumber ditolak", paths: []string{"/home/user/docs/"}, wantErr: true},
+		{name: "path kosong ditolak", paths: []string{" "}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRestorePaths(snapshot, tt.paths)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRestore) {
+					t.Fatalf("err = %v, want ErrInvalidRestore", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeRestorePaths(%q) = %q, want %q", tt.paths, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeRcloneGlob(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "a/b.txt", want: "a/b.txt"},
+		{in: "laporan*.pdf", want: `laporan\*.pdf`},
+		{in: "apa?.txt", want: `apa\?.txt`},
+		{in: "foto[1].jpg", want: `foto\[1\].jpg`},
+		{in: "{a,b}", want: `\{a,b\}`},
+		{in: `dir\file`, want: `dir\\file`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := escapeRcloneGlob(tt.in); got != tt.want {
+				t.Errorf("escapeRcloneGlob(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreFilterArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts RestoreOptions
+		want []string
+	}{
+		{name: "tanpa pilihan", opts: RestoreOptions{}, want: nil},
+		{
+			name: "path sebagai file maupun folder",
+			opts: RestoreOptions{Paths: []string{"a/b"}},
+			want: []string{"--include", "/a/b", "--include", "/a/b/**"},
+		},
+		{
+			name: "karakter glob di path diloloskan",
+			opts: RestoreOptions{Paths: []string{"foto[1]"}},
+			want: []string{"--include", `/foto\[1\]`, "--include", `/foto\[1\]/**`},
+		},
+		{
+			name: "pola include diteruskan apa adanya setelah path",
+			opts: RestoreOptions{Paths: []string{"c"}, Include: []string{"*.pdf"}},
+			want: []string{"--include", "/c", "--include", "/c/**", "--include", "*.pdf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restoreFilterArgs(&tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("restoreFilterArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
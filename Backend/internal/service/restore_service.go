@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/repository"
+)
+
+// Error restore yang dipetakan handler ke status HTTP
+var (
+	ErrInvalidRestore        = errors.New("permintaan restore tidak valid")
+	ErrSnapshotNotFound      = errors.New("snapshot tidak ditemukan")
+	ErrSnapshotNotRestorable = errors.New("snapshot tidak bisa di-restore")
+)
+
+// asOfLayouts: Format as_of yang diterima selain RFC3339 (zona waktu job)
+var asOfLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"}
+
+// PointInTimeRestoreRequest: Restore snapshot job berdasarkan ID snapshot atau waktu as-of
+type PointInTimeRestoreRequest struct {
+	JobID      uint
+	SnapshotID uint   // Diutamakan jika diisi
+	AsOf       string // mis. "2026-10-01 03:00" atau RFC3339
+	TargetPath string // Kosong = SourcePath asli saat snapshot dibuat
+	// Paths/Include: Restore selektif (hanya untuk snapshot folder), kosong = seluruh snapshot
+	Paths   []string
+	Include []string
+	// ConflictPolicy: Perlakuan file yang sudah ada di tujuan (kosong = overwrite)
+	ConflictPolicy string
+	// SafetySnapshot: File yang akan ditimpa dipindahkan dulu ke folder quarantine bertimestamp
+	SafetySnapshot bool
+	RequestedBy    RestoreRequester
+}
+
+// RestorePlan: Snapshot terpilih beserta perintah restore yang akan dijalankan
+type RestorePlan struct {
+	Job        models.ScheduledJob
+	Snapshot   models.Snapshot
+	RemotePath string   // Path snapshot di remote (tanpa nama remote)
+	TargetPath string   // Folder tujuan, atau path file untuk snapshot file
+	InPlace    bool     // true jika menimpa lokasi sumber asli
+	ResolvedBy string   // snapshot_id | as_of
+	Paths      []string // Relatif terhadap root snapshot
+	Include    []string
+
+	ConflictPolicy string
+	QuarantinePath string // Kosong = tanpa safety snapshot
+
+	Record *models.RestoreRecord // Terisi setelah rencana masuk antrean
+}
+
+// RestoreService mengelola restore dari snapshot yang tercatat di riwayat job
+type RestoreService interface {
+	PlanPointInTimeRestore(req PointInTimeRestoreRequest) (*RestorePlan, error)
+	StartPointInTimeRestore(req PointInTimeRestoreRequest) (*RestorePlan, error)
+	StartPathRestore(req PathRestoreRequest) (*models.RestoreRecord, error)
+	PreviewRestore(ctx context.Context, req PointInTimeRestoreRequest) (*models.RestorePreview, error)
+	GetPreview(previewID uint) (*models.RestorePreview, error)
+	ExecutePreview(previewID uint, requester RestoreRequester) (*RestorePlan, error)
+	GetRestoreRecord(recordID uint) (*models.RestoreRecord, *models.JobRun, error)
+	ListRestoreRecords(limit int) ([]models.RestoreRecord, error)
+	CancelRestore(recordID uint) error
+}
+
+// PathRestoreRequest: Restore mentah dari path remote (POST /api/v1/jobs/restore)
+type PathRestoreRequest struct {
+	RemoteName      string
+	SourcePath      string // Path di remote
+	DestinationPath string // Folder lokal tujuan
+	ConflictPolicy  string
+	SafetySnapshot  bool
+	RequestedBy     RestoreRequester
+}
+
+type restoreServiceImpl struct {
+	JobRepo     repository.JobRepository
+	SnapRepo    repository.SnapshotRepository
+	PreviewRepo repository.RestorePreviewRepository
+	RecordRepo  repository.RestoreRecordRepository
+	RunRepo     repository.JobRunRepository
+	BackupSvc   BackupService
+	Dispatcher  JobDispatcher
+}
+
+func NewRestoreService(
+	jRepo repository.JobRepository,
+	sRepo repository.SnapshotRepository,
+	pRepo repository.RestorePreviewRepository,
+	recRepo repository.RestoreRecordRepository,
+	rRepo repository.JobRunRepository,
+	bSvc BackupService,
+	dispatcher JobDispatcher,
+) RestoreService {
+	s := &restoreServiceImpl{
+		JobRepo:     jRepo,
+		SnapRepo:    sRepo,
+		PreviewRepo: pRepo,
+		RecordRepo:  recRepo,
+		RunRepo:     rRepo,
+		BackupSvc:   bSvc,
+		Dispatcher:  dispatcher,
+	}
+	// Hasil akhir run restore ditulis ke RestoreRecord
+	bSvc.OnJobCompleted(s.handleRestoreCompleted)
+	return s
+}
+
+// ParseAsOf: as_of dalam RFC3339 atau format lokal di zona waktu loc.
+// Tanggal saja (YYYY-MM-DD) berarti akhir hari tersebut.
+func ParseAsOf(raw string, loc *time.Location) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	for _, layout := range asOfLayouts {
+		t, err := time.ParseInLocation(layout, raw, loc)
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: as_of '%s' harus RFC3339 atau YYYY-MM-DD[ HH:MM[:SS]]", ErrInvalidRestore, raw)
+}
+
+// PlanPointInTimeRestore: Memilih snapshot dan menentukan tujuan restore tanpa menjalankannya
+func (s *restoreServiceImpl) PlanPointInTimeRestore(req PointInTimeRestoreRequest) (*RestorePlan, error) {
+	job, err := s.JobRepo.FindJobByID(req.JobID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RestorePlan{Job: *job}
+	switch {
+	case req.SnapshotID != 0:
+		snap, err := s.SnapRepo.FindByID(req.SnapshotID)
+		if err != nil || snap.JobID != job.ID {
+			return nil, fmt.Errorf("%w: snapshot %d bukan milik job %d", ErrSnapshotNotFound, req.SnapshotID, job.ID)
+		}
+		if snap.Status != "COMPLETE" {
+			return nil, fmt.Errorf("%w: snapshot %s berstatus %s", ErrSnapshotNotRestorable, snap.Name, snap.Status)
+		}
+		plan.Snapshot = *snap
+		plan.ResolvedBy = "snapshot_id"
+	case strings.TrimSpace(req.AsOf) != "":
+		asOf, err := ParseAsOf(req.AsOf, jobLocation(*job))
+		if err != nil {
+			return nil, err
+		}
+		snap, err := s.SnapRepo.FindLatestCompleteAsOf(job.ID, asOf)
+		if err != nil {
+			return nil, err
+		}
+		if snap == nil {
+			return nil, fmt.Errorf("%w: tidak ada snapshot lengkap job %d pada/sebelum %s",
+				ErrSnapshotNotFound, job.ID, asOf.Format(time.RFC3339))
+		}
+		plan.Snapshot = *snap
+		plan.ResolvedBy = "as_of"
+	default:
+		return nil, fmt.Errorf("%w: snapshot_id atau as_of wajib diisi", ErrInvalidRestore)
+	}
+
+	originalPath := plan.Snapshot.SourcePath
+	if originalPath == "" {
+		originalPath = job.SourcePath
+	}
+	plan.RemotePath = path.Join(plan.Snapshot.DestinationPath, plan.Snapshot.Name)
+
+	if len(req.Paths) > 0 || len(req.Include) > 0 {
+		if !plan.Snapshot.IsDir {
+			return nil, fmt.Errorf("%w: snapshot %s berupa file, paths/include hanya untuk snapshot folder",
+				ErrInvalidRestore, plan.Snapshot.Name)
+		}
+		if plan.Paths, err = NormalizeRestorePaths(plan.Snapshot, req.Paths); err != nil {
+			return nil, err
+		}
+		if err := ValidateIncludePatterns(req.Include); err != nil {
+			return nil, err
+		}
+		plan.Include = req.Include
+	}
+
+	if plan.ConflictPolicy, err = ValidateConflictPolicy(strings.TrimSpace(req.ConflictPolicy)); err != nil {
+		return nil, err
+	}
+
+	target := strings.TrimSpace(req.TargetPath)
+	if target != "" && !filepath.IsAbs(target) {
+		return nil, fmt.Errorf("%w: target_path harus path absolut", ErrInvalidRestore)
+	}
+	if target == "" || filepath.Clean(target) == filepath.Clean(originalPath) {
+		plan.TargetPath = originalPath
+		plan.InPlace = true
+	} else if plan.Snapshot.IsDir {
+		plan.TargetPath = filepath.Clean(target)
+	} else {
+		// Snapshot file ke lokasi alternatif: target adalah folder, nama file asli dipertahankan
+		plan.TargetPath = filepath.Join(target, filepath.Base(originalPath))
+	}
+
+	if req.SafetySnapshot {
+		if plan.QuarantinePath, err = quarantinePathFor(job.JobName, plan.TargetPath, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// StartPointInTimeRestore: Menyusun rencana restore lalu memasukkannya ke antrean dispatcher
+func (s *restoreServiceImpl) StartPointInTimeRestore(req PointInTimeRestoreRequest) (*RestorePlan, error) {
+	plan, err := s.PlanPointInTimeRestore(req)
+	if err != nil {
+		return nil, err
+	}
+	record := newRestoreRecord(RestoreSourceSnapshot, req.RequestedBy)
+	if err := s.enqueuePlan(plan, record); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// enqueuePlan: Mencatat rencana restore ke record lalu memasukkannya ke antrean dispatcher
+// sebagai job RESTORE one-shot
+func (s *restoreServiceImpl) enqueuePlan(plan *RestorePlan, record *models.RestoreRecord) error {
+	jobID, snapID := plan.Job.ID, plan.Snapshot.ID
+	record.JobID = &jobID
+	record.SnapshotID = &snapID
+	record.RemoteName = plan.Snapshot.RemoteName
+	record.SourcePath = plan.RemotePath
+	record.DestinationPath = plan.TargetPath
+	record.InPlace = plan.InPlace
+	record.Paths = encodeStringList(plan.Paths)
+	record.Include = encodeStringList(plan.Include)
+	record.ConflictPolicy = plan.ConflictPolicy
+	record.QuarantinePath = plan.QuarantinePath
+
+	restoreJob := models.ScheduledJob{
+		UserID:          plan.Job.UserID,
+		JobName:         fmt.Sprintf("%s-%s", plan.Job.JobName, plan.Snapshot.Name),
+		OperationMode:   "RESTORE",
+		RcloneMode:      "copy",
+		SourcePath:      plan.RemotePath,
+		RemoteName:      plan.Snapshot.RemoteName,
+		DestinationPath: plan.TargetPath,
+		StatusQueue:     "PENDING",
+	}
+	opts := &RestoreOptions{
+		SnapshotID: plan.Snapshot.ID,
+		SingleFile: !plan.Snapshot.IsDir,
+		Paths:      plan.Paths,
+		Include:    plan.Include,
+
+		ConflictPolicy: plan.ConflictPolicy,
+		QuarantinePath: plan.QuarantinePath,
+	}
+
+	if err := s.enqueueRecorded(record, restoreJob, opts); err != nil {
+		return err
+	}
+	plan.Record = record
+
+	fmt.Printf("[DISPATCHER] 🔄 RESTORE #%d Job %d: snapshot %s (%s) → %s [%s]\n",
+		record.ID, plan.Job.ID, plan.Snapshot.Name, plan.ResolvedBy, plan.TargetPath, plan.ConflictPolicy)
+	if plan.QuarantinePath != "" {
+		fmt.Printf("[DISPATCHER] 🛟 Safety snapshot: file yang ditimpa dipindahkan ke %s\n", plan.QuarantinePath)
+	}
+	return nil
+}
+
+// StartPathRestore: Restore path remote apa adanya ke folder lokal, dengan conflict policy & safety snapshot
+func (s *restoreServiceImpl) StartPathRestore(req PathRestoreRequest) (*models.RestoreRecord, error) {
+	policy, err := ValidateConflictPolicy(strings.TrimSpace(req.ConflictPolicy))
+	if err != nil {
+		return nil, err
+	}
+	if policy == ConflictRenameRestored && !filepath.IsAbs(req.DestinationPath) {
+		return nil, fmt.Errorf("%w: rename-restored membutuhkan destination_path absolut", ErrInvalidRestore)
+	}
+
+	opts := &RestoreOptions{ConflictPolicy: policy}
+	if req.SafetySnapshot {
+		if opts.QuarantinePath, err = quarantinePathFor("Restore-"+req.RemoteName, req.DestinationPath, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	userID := req.RequestedBy.UserID
+	if userID == 0 {
+		userID = uint(1)
+	}
+	restoreJob := models.ScheduledJob{
+		UserID:          userID,
+		JobName:         req.RemoteName,
+		OperationMode:   "RESTORE",
+		RcloneMode:      "copy",
+		SourcePath:      req.SourcePath,
+		RemoteName:      req.RemoteName,
+		DestinationPath: req.DestinationPath,
+		StatusQueue:     "PENDING",
+	}
+	record := newRestoreRecord(RestoreSourcePath, req.RequestedBy)
+	record.RemoteName = req.RemoteName
+	record.SourcePath = req.SourcePath
+	record.DestinationPath = req.DestinationPath
+	record.ConflictPolicy = policy
+	record.QuarantinePath = opts.QuarantinePath
+
+	if err := s.enqueueRecorded(record, restoreJob, opts); err != nil {
+		return nil, err
+	}
+	fmt.Printf("[DISPATCHER] 🔄 RESTORE #%d: %s:%s → %s (One-Shot) [%s]\n",
+		record.ID, req.RemoteName, req.SourcePath, req.DestinationPath, policy)
+	return record, nil
+}
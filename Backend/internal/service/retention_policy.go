@@ -0,0 +1,337 @@
+package service
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// MaxRetentionKeep: Batas atas setiap aturan keep_* (hourly/daily/... = jumlah periode)
+const MaxRetentionKeep = 1000
+
+// DefaultMinSuccessfulSnapshots: Batas bawah default jumlah snapshot sukses yang dipertahankan
+const DefaultMinSuccessfulSnapshots = 1
+
+// snapshotTimeLayout: Format timestamp yang ditambahkan Fase 1.5 ke nama snapshot (name_YYYYMMDD_HHMMSS)
+const snapshotTimeLayout = "20060102_150405"
+
+// snapshotTimePattern: Timestamp di akhir nama (sebelum ekstensi untuk snapshot berupa file)
+var snapshotTimePattern = regexp.MustCompile(`_(\d{8}_\d{6})(\.[^._]+)*$`)
+
+// keepWithinPattern: Durasi keep_within, mis. "30d", "1y6m", "2w", "12h"
+var keepWithinPattern = regexp.MustCompile(`(\d+)([ymwdh])`)
+
+// RetentionPolicy: Aturan retensi grandfather-father-son (0 / "" = aturan tidak dipakai).
+// Snapshot dipertahankan jika memenuhi minimal satu aturan.
+type RetentionPolicy struct {
+	KeepLast    int    `json:"keep_last"`
+	KeepHourly  int    `json:"keep_hourly"`
+	KeepDaily   int    `json:"keep_daily"`
+	KeepWeekly  int    `json:"keep_weekly"`
+	KeepMonthly int    `json:"keep_monthly"`
+	KeepYearly  int    `json:"keep_yearly"`
+	KeepWithin  string `json:"keep_within"` // Relatif terhadap snapshot terbaru
+	// MinSuccessful: Retensi tidak pernah menyisakan snapshot sukses kurang dari ini
+	MinSuccessful int  `json:"min_successful"`
+	DryRun        bool `json:"dry_run"` // Hanya log keputusan, tidak menghapus
+}
+
+// IsEmpty: true jika tidak ada aturan keep_* yang diisi (min_successful & dry_run bukan aturan keep)
+func (p RetentionPolicy) IsEmpty() bool {
+	return p.KeepLast == 0 && p.KeepHourly == 0 && p.KeepDaily == 0 && p.KeepWeekly == 0 &&
+		p.KeepMonthly == 0 && p.KeepYearly == 0 && p.KeepWithin == ""
+}
+
+// HasRetentionRules: true jika job punya minimal satu aturan GFS (bukan sekadar max_retention)
+func HasRetentionRules(job models.ScheduledJob) bool {
+	return !JobRetentionRules(job).IsEmpty()
+}
+
+// JobRetentionPolicy: Kebijakan retensi dari kolom job. Job lama tanpa aturan GFS memakai
+// max_retention sebagai keep_last.
+func JobRetentionPolicy(job models.ScheduledJob) RetentionPolicy {
+	policy := JobRetentionRules(job)
+	if policy.IsEmpty() {
+		policy.KeepLast = job.MaxRetention
+	}
+	return policy
+}
+
+// JobRetentionRules: Aturan GFS yang tersimpan di job apa adanya (tanpa fallback max_retention)
+func JobRetentionRules(job models.ScheduledJob) RetentionPolicy {
+	return RetentionPolicy{
+		KeepLast:    job.KeepLast,
+		KeepHourly:  job.KeepHourly,
+		KeepDaily:   job.KeepDaily,
+		KeepWeekly:  job.KeepWeekly,
+		KeepMonthly: job.KeepMonthly,
+		KeepYearly:  job.KeepYearly,
+		KeepWithin:  job.KeepWithin,
+		DryRun:      job.RetentionDryRun,
+
+		MinSuccessful: job.MinSuccessfulSnapshots,
+	}
+}
+
+// ApplyToJob: Menyalin aturan ke kolom job
+func (p RetentionPolicy) ApplyToJob(job *models.ScheduledJob) {
+	job.KeepLast = p.KeepLast
+	job.KeepHourly = p.KeepHourly
+	job.KeepDaily = p.KeepDaily
+	job.KeepWeekly = p.KeepWeekly
+	job.KeepMonthly = p.KeepMonthly
+	job.KeepYearly = p.KeepYearly
+	job.KeepWithin = p.KeepWithin
+	job.RetentionDryRun = p.DryRun
+	job.MinSuccessfulSnapshots = p.MinSuccessful
+}
+
+// ValidateRetentionPolicy: Setiap keep_* harus 0..MaxRetentionKeep dan keep_within harus bisa di-parse
+func ValidateRetentionPolicy(p RetentionPolicy) error {
+	fields := []string{"keep_last", "keep_hourly", "keep_daily", "keep_weekly", "keep_monthly", "keep_yearly"}
+	for i, val := range []int{p.KeepLast, p.KeepHourly, p.KeepDaily, p.KeepWeekly, p.KeepMonthly, p.KeepYearly} {
+		if val < 0 || val > MaxRetentionKeep {
+			return fmt.Errorf("retention.%s harus antara 0 dan %d", fields[i], MaxRetentionKeep)
+		}
+	}
+	if p.MinSuccessful < 1 || p.MinSuccessful > MaxRetentionKeep {
+		return fmt.Errorf("retention.min_successful harus antara 1 dan %d", MaxRetentionKeep)
+	}
+	if p.KeepWithin != "" {
+		if _, err := keepWithinCutoff(p.KeepWithin, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keepWithinCutoff: Batas waktu keep_within dihitung mundur dari ref
+func keepWithinCutoff(raw string, ref time.Time) (time.Time, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	matches := keepWithinPattern.FindAllStringSubmatch(raw, -1)
+
+	consumed := 0
+	for _, m := range matches {
+		consumed += len(m[0])
+	}
+	if len(matches) == 0 || consumed != len(raw) {
+		return time.Time{}, fmt.Errorf("retention.keep_within '%s' tidak valid (contoh: 30d, 1y6m, 2w, 12h)", raw)
+	}
+
+	var years, months, days, hours int
+	for _, m := range matches {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("retention.keep_within '%s' tidak valid: %w", raw, err)
+		}
+		switch m[2] {
+		case "y":
+			years += n
+		case "m":
+			months += n
+		case "w":
+			days += n * 7
+		case "d":
+			days += n
+		case "h":
+			hours += n
+		}
+	}
+	return ref.AddDate(-years, -months, -days).Add(-time.Duration(hours) * time.Hour), nil
+}
+
+// BackupSnapshot: Satu hasil backup bertimestamp di folder tujuan
+type BackupSnapshot struct {
+	SnapshotID uint      `json:"snapshot_id"`
+	RunID      uint      `json:"run_id"`
+	Name       string    `json:"name"`
+	Time       time.Time `json:"time"` // Dari nama (fallback SnapshotAt), bukan ModTime remote
+	Size       int64     `json:"size"`
+	IsDir      bool      `json:"is_dir"`
+	Pinned     bool      `json:"pinned"`
+}
+
+// IsPinActive: Pin berlaku jika snapshot di-pin dan belum kedaluwarsa
+func IsPinActive(snap models.Snapshot, now time.Time) bool {
+	return snap.Pinned && (snap.PinnedUntil == nil || snap.PinnedUntil.After(now))
+}
+
+// pinReason: Alasan keputusan untuk snapshot yang di-pin
+func pinReason(snap models.Snapshot) string {
+	reason := "kept as pinned"
+	if snap.PinReason != "" {
+		reason += ": " + snap.PinReason
+	}
+	if snap.PinnedUntil != nil {
+		reason += " (until " + snap.PinnedUntil.Format("2006-01-02 15:04") + ")"
+	}
+	return reason
+}
+
+// inUseReasons: Alasan keputusan untuk snapshot yang dipakai restore (restore berjalan lebih diutamakan)
+func inUseReasons(restoring, previewed []uint) map[uint]string {
+	reasons := make(map[uint]string, len(restoring)+len(previewed))
+	for _, id := range previewed {
+		reasons[id] = "kept: restore preview ready"
+	}
+	for _, id := range restoring {
+		reasons[id] = "kept: restore in progress"
+	}
+	return reasons
+}
+
+// buildRetentionDecisions: Snapshot di-pin atau yang dipakai restore selalu dipertahankan di luar hitungan aturan, snapshot sukses
+// dievaluasi ApplyRetentionPolicy, dan snapshot yang tidak sukses/terverifikasi selalu dihapus
+func buildRetentionDecisions(p RetentionPolicy, pinned []RetentionDecision, complete, incomplete []BackupSnapshot) []RetentionDecision {
+	decisions := append([]RetentionDecision(nil), pinned...)
+	decisions = append(decisions, ApplyRetentionPolicy(p, complete)...)
+	for _, item := range incomplete {
+		decisions = append(decisions, RetentionDecision{
+			Snapshot: item,
+			Reasons:  []string{fmt.Sprintf("incomplete or unverified (run %d), not counted", item.RunID)},
+		})
+	}
+	return decisions
+}
+
+// ParseSnapshotTime: Timestamp dari nama name_YYYYMMDD_HHMMSS[.ext] (zona waktu server, sama seperti saat dibuat)
+func ParseSnapshotTime(name string) (time.Time, bool) {
+	m := snapshotTimePattern.FindStringSubmatch(path.Base(name))
+	if m == nil {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(snapshotTimeLayout, m[1], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// legacySnapshotTime: Timestamp item yang dibuat job dengan sourcePath ini sebelum snapshot dicatat di DB.
+// Nama harus persis <nama sumber>_YYYYMMDD_HHMMSS (folder) atau <nama tanpa ext>_YYYYMMDD_HHMMSS<ext> (file).
+func legacySnapshotTime(sourcePath, name string) (time.Time, bool) {
+	t, ok := ParseSnapshotTime(name)
+	if !ok || name != path.Base(name) {
+		return time.Time{}, false
+	}
+	loc := snapshotTimePattern.FindStringSubmatchIndex(name)
+	prefix, suffix := name[:loc[0]], name[loc[3]:]
+
+	base := filepath.Base(sourcePath)
+	ext := filepath.Ext(base)
+	if (prefix == base && suffix == "") || (ext != "" && prefix == strings.TrimSuffix(base, ext) && suffix == ext) {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// RetentionDecision: Keputusan retensi untuk satu snapshot
+type RetentionDecision struct {
+	Snapshot BackupSnapshot `json:"snapshot"`
+	Keep     bool           `json:"keep"`
+	Reasons  []string       `json:"reasons"` // mis. "kept as monthly 2026-09"
+}
+
+// reasonNoRuleMatched: Alasan snapshot dihapus karena tidak memenuhi aturan mana pun
+const reasonNoRuleMatched = "no retention rule matched"
+
+// retentionBucket: Aturan periodik (hourly/daily/...) beserta kunci periodenya
+type retentionBucket struct {
+	name  string
+	limit int
+	key   func(t time.Time) string
+}
+
+// ApplyRetentionPolicy: Memutuskan snapshot mana yang dipertahankan. Hasil terurut terbaru lebih dulu.
+// Untuk setiap aturan periodik, snapshot terbaru di tiap periode dipertahankan hingga limit periode.
+func ApplyRetentionPolicy(p RetentionPolicy, snapshots []BackupSnapshot) []RetentionDecision {
+	decisions := make([]RetentionDecision, len(snapshots))
+	for i, snap := range snapshots {
+		decisions[i] = RetentionDecision{Snapshot: snap}
+	}
+	sort.SliceStable(decisions, func(i, j int) bool {
+		return decisions[i].Snapshot.Time.After(decisions[j].Snapshot.Time)
+	})
+	if len(decisions) == 0 {
+		return decisions
+	}
+
+	buckets := []retentionBucket{
+		{"hourly", p.KeepHourly, func(t time.Time) string { return t.Format("2006-01-02 15:00") }},
+		{"daily", p.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{"weekly", p.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{"monthly", p.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{"yearly", p.KeepYearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+
+	var withinCutoff time.Time
+	if p.KeepWithin != "" {
+		if cutoff, err := keepWithinCutoff(p.KeepWithin, decisions[0].Snapshot.Time); err == nil {
+			withinCutoff = cutoff
+		}
+	}
+
+	for i := range decisions {
+		d := &decisions[i]
+		if i < p.KeepLast {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("kept as last #%d", i+1))
+		}
+		if !withinCutoff.IsZero() && !d.Snapshot.Time.Before(withinCutoff) {
+			d.Reasons = append(d.Reasons, "kept as within "+p.KeepWithin)
+		}
+	}
+
+	for _, b := range buckets {
+		if b.limit <= 0 {
+			continue
+		}
+		kept, lastKey := 0, ""
+		for i := range decisions {
+			if kept >= b.limit {
+				break
+			}
+			key := b.key(decisions[i].Snapshot.Time)
+			if key == lastKey {
+				continue
+			}
+			lastKey = key
+			kept++
+			decisions[i].Reasons = append(decisions[i].Reasons, "kept as "+b.name+" "+key)
+		}
+	}
+
+	// Batas bawah: snapshot terbaru dipertahankan hingga jumlah yang disimpan mencapai min_successful
+	kept := 0
+	for i := range decisions {
+		if len(decisions[i].Reasons) > 0 {
+			kept++
+		}
+	}
+	for i := range decisions {
+		if kept >= p.MinSuccessful {
+			break
+		}
+		if len(decisions[i].Reasons) == 0 {
+			decisions[i].Reasons = append(decisions[i].Reasons, fmt.Sprintf("kept as minimum floor (%d)", p.MinSuccessful))
+			kept++
+		}
+	}
+
+	for i := range decisions {
+		decisions[i].Keep = len(decisions[i].Reasons) > 0
+		if !decisions[i].Keep {
+			decisions[i].Reasons = []string{reasonNoRuleMatched}
+		}
+	}
+	return decisions
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// snapshotsAt: Snapshot sukses dengan nama = waktu (RFC3339), untuk membaca hasil test
+func snapshotsAt(times ...time.Time) []BackupSnapshot {
+	snaps := make([]BackupSnapshot, 0, len(times))
+	for i, t := range times {
+		snaps = append(snaps, BackupSnapshot{
+			SnapshotID: uint(i + 1),
+			Name:       t.Format(time.RFC3339),
+			Time:       t,
+		})
+	}
+	return snaps
+}
+
+// keptNames: Nama snapshot yang dipertahankan, terurut seperti hasil keputusan
+func keptNames(decisions []RetentionDecision) []string {
+	names := []string{}
+	for _, d := range decisions {
+		if d.Keep {
+			names = append(names, d.Snapshot.Name)
+		}
+	}
+	return names
+}
+
+func at(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func snapName(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func TestApplyRetentionPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		times  []time.Time
+		want   []time.Time // Terbaru lebih dulu
+	}{
+		{
+			name:   "keep_last",
+			policy: RetentionPolicy{KeepLast: 2},
+			times:  []time.Time{at(2026, 10, 1, 0, 0), at(2026, 10, 3, 0, 0), at(2026, 10, 2, 0, 0), at(2026, 10, 4, 0, 0)},
+			want:   []time.Time{at(2026, 10, 4, 0, 0), at(2026, 10, 3, 0, 0)},
+		},
+		{
+			name:   "hourly: satu snapshot terbaru per jam",
+			policy: RetentionPolicy{KeepHourly: 2},
+			times:  []time.Time{at(2026, 10, 1, 10, 0), at(2026, 10, 1, 10, 30), at(2026, 10, 1, 11, 15), at(2026, 10, 1, 11, 5), at(2026, 10, 1, 12, 5)},
+			want:   []time.Time{at(2026, 10, 1, 12, 5), at(2026, 10, 1, 11, 15)},
+		},
+		{
+			name:   "daily: beberapa run per hari dihitung satu",
+			policy: RetentionPolicy{KeepDaily: 2},
+			times:  []time.Time{at(2026, 10, 1, 1, 0), at(2026, 10, 1, 13, 0), at(2026, 10, 2, 1, 0), at(2026, 10, 2, 13, 0), at(2026, 10, 3, 1, 0)},
+			want:   []time.Time{at(2026, 10, 3, 1, 0), at(2026, 10, 2, 13, 0)},
+		},
+		{
+			name:   "weekly: minggu ISO melewati pergantian tahun (2020-W53)",
+			policy: RetentionPolicy{KeepWeekly: 2},
+			times:  []time.Time{at(2020, 12, 28, 0, 0), at(2020, 12, 31, 0, 0), at(2021, 1, 3, 0, 0), at(2021, 1, 4, 0, 0)},
+			want:   []time.Time{at(2021, 1, 4, 0, 0), at(2021, 1, 3, 0, 0)},
+		},
+		{
+			name:   "weekly: 2024-12-30 sudah masuk 2025-W01",
+			policy: RetentionPolicy{KeepWeekly: 2},
+			times:  []time.Time{at(2024, 12, 29, 0, 0), at(2024, 12, 30, 0, 0), at(2025, 1, 2, 0, 0)},
+			want:   []time.Time{at(2025, 1, 2, 0, 0), at(2024, 12, 29, 0, 0)},
+		},
+		{
+			name:   "monthly",
+			policy: RetentionPolicy{KeepMonthly: 2},
+			times:  []time.Time{at(2026, 1, 5, 0, 0), at(2026, 1, 20, 0, 0), at(2026, 2, 10, 0, 0), at(2026, 2, 1, 0, 0), at(2026, 3, 1, 0, 0)},
+			want:   []time.Time{at(2026, 3, 1, 0, 0), at(2026, 2, 10, 0, 0)},
+		},
+		{
+			name:   "yearly: limit lebih besar dari jumlah tahun",
+			policy: RetentionPolicy{KeepYearly: 5},
+			times:  []time.Time{at(2024, 6, 1, 0, 0), at(2024, 12, 31, 23, 0), at(2025, 3, 1, 0, 0)},
+			want:   []time.Time{at(2025, 3, 1, 0, 0), at(2024, 12, 31, 23, 0)},
+		},
+		{
+			name:   "gabungan aturan: satu snapshot bisa memenuhi beberapa aturan",
+			policy: RetentionPolicy{KeepLast: 1, KeepDaily: 2, KeepMonthly: 2},
+			times:  []time.Time{at(2026, 9, 15, 0, 0), at(2026, 9, 30, 0, 0), at(2026, 10, 1, 0, 0), at(2026, 10, 2, 0, 0), at(2026, 10, 2, 12, 0)},
+			want:   []time.Time{at(2026, 10, 2, 12, 0), at(2026, 10, 1, 0, 0), at(2026, 9, 30, 0, 0)},
+		},
+		{
+			name:   "keep_within relatif terhadap snapshot terbaru (batas inklusif)",
+			policy: RetentionPolicy{KeepWithin: "2d"},
+			times:  []time.Time{at(2020, 1, 10, 12, 0), at(2020, 1, 8, 12, 0), at(2020, 1, 8, 11, 59), at(2020, 1, 1, 0, 0)},
+			want:   []time.Time{at(2020, 1, 10, 12, 0), at(2020, 1, 8, 12, 0)},
+		},
+		{
+			name:   "min_successful tanpa aturan keep",
+			policy: RetentionPolicy{MinSuccessful: 2},
+			times:  []time.Time{at(2026, 10, 1, 0, 0), at(2026, 10, 2, 0, 0), at(2026, 10, 3, 0, 0)},
+			want:   []time.Time{at(2026, 10, 3, 0, 0), at(2026, 10, 2, 0, 0)},
+		},
+		{
+			name:   "min_successful menambah di atas keep_last",
+			policy: RetentionPolicy{KeepLast: 1, MinSuccessful: 3},
+			times:  []time.Time{at(2026, 10, 1, 0, 0), at(2026, 10, 2, 0, 0), at(2026, 10, 3, 0, 0), at(2026, 10, 4, 0, 0)},
+			want:   []time.Time{at(2026, 10, 4, 0, 0), at(2026, 10, 3, 0, 0), at(2026, 10, 2, 0, 0)},
+		},
+		{
+			name:   "min_successful tidak berpengaruh jika aturan sudah cukup",
+			policy: RetentionPolicy{KeepLast: 3, MinSuccessful: 1},
+			times:  []time.Time{at(2026, 10, 1, 0, 0), at(2026, 10, 2, 0, 0), at(2026, 10, 3, 0, 0), at(2026, 10, 4, 0, 0)},
+			want:   []time.Time{at(2026, 10, 4, 0, 0), at(2026, 10, 3, 0, 0), at(2026, 10, 2, 0, 0)},
+		},
+		{
+			name:   "tanpa snapshot",
+			policy: RetentionPolicy{KeepLast: 3, MinSuccessful: 1},
+			times:  nil,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions := ApplyRetentionPolicy(tt.policy, snapshotsAt(tt.times...))
+			if len(decisions) != len(tt.times) {
+				t.Fatalf("jumlah keputusan = %d, want %d", len(decisions), len(tt.times))
+			}
+
+			want := []string{}
+			for _, w := range tt.want {
+				want = append(want, snapName(w))
+			}
+			if got := keptNames(decisions); !reflect.DeepEqual(got, want) {
+				t.Errorf("kept = %v, want %v", got, want)
+			}
+
+			for i, d := range decisions {
+				if i > 0 && d.Snapshot.Time.After(decisions[i-1].Snapshot.Time) {
+					t.Errorf("keputusan tidak terurut terbaru lebih dulu di index %d", i)
+				}
+				if !d.Keep && !reflect.DeepEqual(d.Reasons, []string{reasonNoRuleMatched}) {
+					t.Errorf("%s dihapus dengan alasan %v", d.Snapshot.Name, d.Reasons)
+				}
+				if d.Keep && len(d.Reasons) == 0 {
+					t.Errorf("%s dipertahankan tanpa alasan", d.Snapshot.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyRetentionPolicyReasons(t *testing.T) {
+	decisions := ApplyRetentionPolicy(
+		RetentionPolicy{KeepWeekly: 1, MinSuccessful: 2},
+		snapshotsAt(at(2021, 1, 3, 0, 0), at(2020, 12, 20, 0, 0)),
+	)
+	wantReasons := [][]string{
+		{"kept as weekly 2020-W53"},
+		{"kept as minimum floor (2)"},
+	}
+	for i, d := range decisions {
+		if !reflect.DeepEqual(d.Reasons, wantReasons[i]) {
+			t.Errorf("%s: reasons = %v, want %v", d.Snapshot.Name, d.Reasons, wantReasons[i])
+		}
+	}
+}
+
+func TestBuildRetentionDecisionsPinnedNotCounted(t *testing.T) {
+	pinnedAt := at(2026, 10, 5, 0, 0)
+	pinned := []RetentionDecision{{
+		Snapshot: BackupSnapshot{SnapshotID: 99, Name: snapName(pinnedAt), Time: pinnedAt, Pinned: true},
+		Keep:     true,
+		Reasons:  []string{"kept as pinned: audit"},
+	}}
+	complete := snapshotsAt(at(2026, 10, 1, 0, 0), at(2026, 10, 2, 0, 0), at(2026, 10, 3, 0, 0))
+	incomplete := []BackupSnapshot{{SnapshotID: 50, RunID: 7, Name: "partial", Time: at(2026, 10, 4, 0, 0)}}
+
+	decisions := buildRetentionDecisions(RetentionPolicy{KeepLast: 2, MinSuccessful: 2}, pinned, complete, incomplete)
+
+	want := []string{snapName(pinnedAt), snapName(at(2026, 10, 3, 0, 0)), snapName(at(2026, 10, 2, 0, 0))}
+	if got := keptNames(decisions); !reflect.DeepEqual(got, want) {
+		t.Fatalf("kept = %v, want %v (snapshot pin tidak boleh memakai slot keep_last)", got, want)
+	}
+
+	last := decisions[len(decisions)-1]
+	if last.Snapshot.Name != "partial" || last.Keep {
+		t.Errorf("snapshot INCOMPLETE harus dihapus, got %+v", last)
+	}
+	if !strings.Contains(last.Reasons[0], "run 7") {
+		t.Errorf("alasan INCOMPLETE = %v", last.Reasons)
+	}
+}
+
+func TestInUseReasons(t *testing.T) {
+	tests := []struct {
+		name      string
+		restoring []uint
+		previewed []uint
+		want      map[uint]string
+	}{
+		{name: "tidak ada restore", want: map[uint]string{}},
+		{
+			name:      "restore berjalan dan preview siap",
+			restoring: []uint{1},
+			previewed: []uint{2},
+			want:      map[uint]string{1: "kept: restore in progress", 2: "kept: restore preview ready"},
+		},
+		{
+			name:      "restore berjalan lebih diutamakan dari preview",
+			restoring: []uint{3},
+			previewed: []uint{3},
+			want:      map[uint]string{3: "kept: restore in progress"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inUseReasons(tt.restoring, tt.previewed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inUseReasons() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepWithinCutoff(t *testing.T) {
+	ref := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		raw     string
+		want    time.Time
+		wantErr bool
+	}{
+		{raw: "30d", want: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{raw: "2w", want: time.Date(2026, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{raw: "12h", want: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{raw: "1d12h", want: time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC)},
+		{raw: "1y6m", want: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)}, // 2024-09-31 dinormalisasi AddDate
+		{raw: "1m", want: time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)},    // 2026-02-31 dinormalisasi AddDate
+		{raw: " 2W ", want: time.Date(2026, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{raw: "", wantErr: true},
+		{raw: "10", wantErr: true},
+		{raw: "5x", wantErr: true},
+		{raw: "1d foo", wantErr: true},
+		{raw: "d1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := keepWithinCutoff(tt.raw, ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("keepWithinCutoff(%q) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("keepWithinCutoff(%q) error: %v", tt.raw, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("keepWithinCutoff(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacySnapshotTime(t *testing.T) {
+	want := time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)
+	tests := []struct {
+		source string
+		name   string
+		ok     bool
+	}{
+		{source: "/data/docs", name: "docs_20250102_030405", ok: true},
+		{source: "/data/db.sql", name: "db_20250102_030405.sql", ok: true},
+		{source: "/data/my.folder", name: "my.folder_20250102_030405", ok: true},
+		{source: "/data/docs", name: "other_20250102_030405", ok: false},
+		{source: "/data/docs", name: "mydocs_20250102_030405", ok: false},
+		{source: "/data/db.sql", name: "db_20250102_030405.bak", ok: false},
+		{source: "/data/docs", name: "docs", ok: false},
+		{source: "/data/docs", name: "docs_20251399_999999", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := legacySnapshotTime(tt.source, tt.name)
+			if ok != tt.ok {
+				t.Fatalf("legacySnapshotTime(%q, %q) ok = %v, want %v", tt.source, tt.name, ok, tt.ok)
+			}
+			if ok && !got.Equal(want) {
+				t.Errorf("time = %v, want %v", got, want)
+			}
+		})
+	}
+}
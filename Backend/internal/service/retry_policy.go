@@ -0,0 +1,89 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// RetryableStatuses: Status gagal yang boleh dipilih untuk retry otomatis (CANCELLED tidak termasuk)
+var RetryableStatuses = []string{
+	"FAIL_PRE_SCRIPT",
+	"FAIL_RCLONE",
+	"FAIL_POST_SCRIPT",
+	"FAIL_SOURCE_CHECK",
+	"NOT_ENOUGH_SPACE",
+	"TIMEOUT_PRE_SCRIPT",
+	"TIMEOUT_RCLONE",
+	"TIMEOUT_POST_SCRIPT",
+}
+
+// DefaultRetryOnStatuses: Default status yang di-retry (gangguan transfer/jaringan)
+const DefaultRetryOnStatuses = "FAIL_RCLONE"
+
+// maxRetryDelay: Batas atas jeda antar percobaan
+const maxRetryDelay = 24 * time.Hour
+
+// retryJitter: Jeda diacak ±10% agar job yang gagal bersamaan (mis. rate-limit remote yang sama)
+// tidak mencoba ulang di detik yang sama
+const retryJitter = 0.1
+
+// retryJitterSource: Sumber acak jitter dalam [0, 1) (diganti di test)
+var retryJitterSource = rand.Float64
+
+// IsValidRetryStatus: Cek apakah status boleh dipakai di retry_on
+func IsValidRetryStatus(status string) bool {
+	for _, s := range RetryableStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRetryStatuses: Memecah kolom retry_on_statuses ("A,B") menjadi slice
+func ParseRetryStatuses(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(strings.ToUpper(part))
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// shouldRetry: Job punya sisa percobaan dan status gagalnya termasuk retry_on
+func shouldRetry(job models.ScheduledJob, status string, attempt int) bool {
+	if job.RetryMaxAttempts <= 1 || attempt >= job.RetryMaxAttempts {
+		return false
+	}
+	for _, s := range ParseRetryStatuses(job.RetryOnStatuses) {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay: Jeda sebelum percobaan berikutnya = initial * multiplier^(attempt-1) ± jitter,
+// dibatasi maxRetryDelay
+func retryDelay(job models.ScheduledJob, attempt int) time.Duration {
+	multiplier := job.RetryMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	seconds := float64(job.RetryInitialDelaySec) * math.Pow(multiplier, float64(attempt-1))
+	seconds *= 1 + retryJitter*(2*retryJitterSource()-1)
+
+	if math.IsInf(seconds, 0) || math.IsNaN(seconds) || seconds > maxRetryDelay.Seconds() {
+		return maxRetryDelay
+	}
+	if seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
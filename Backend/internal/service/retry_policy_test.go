@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+)
+
+// withJitter: Mengganti sumber acak jitter selama satu test
+func withJitter(t *testing.T, r float64) {
+	t.Helper()
+	orig := retryJitterSource
+	retryJitterSource = func() float64 { return r }
+	t.Cleanup(func() { retryJitterSource = orig })
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		initialSec int
+		multiplier float64
+		attempt    int
+		jitter     float64
+		want       time.Duration
+	}{
+		{name: "attempt pertama = initial", initialSec: 60, multiplier: 2, attempt: 1, jitter: 0.5, want: time.Minute},
+		{name: "eksponensial", initialSec: 60, multiplier: 2, attempt: 4, jitter: 0.5, want: 8 * time.Minute},
+		{name: "multiplier < 1 dianggap 1", initialSec: 30, multiplier: 0.5, attempt: 5, jitter: 0.5, want: 30 * time.Second},
+		{name: "jitter batas bawah -10%", initialSec: 100, multiplier: 1, attempt: 1, jitter: 0, want: 90 * time.Second},
+		{name: "jitter mendekati batas atas +10%", initialSec: 100, multiplier: 1, attempt: 1, jitter: 0.999999, want: 109999980 * time.Microsecond},
+		{name: "cap 24 jam", initialSec: 3600, multiplier: 10, attempt: 4, jitter: 0.5, want: maxRetryDelay},
+		{name: "cap tetap berlaku dengan jitter atas", initialSec: 86400, multiplier: 1, attempt: 1, jitter: 0.999, want: maxRetryDelay},
+		{name: "overflow dibatasi cap", initialSec: 60, multiplier: 1000, attempt: 400, jitter: 0.5, want: maxRetryDelay},
+		{name: "initial 0", initialSec: 0, multiplier: 2, attempt: 3, jitter: 0.9, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withJitter(t, tt.jitter)
+			job := models.ScheduledJob{RetryInitialDelaySec: tt.initialSec, RetryMultiplier: tt.multiplier}
+			got := retryDelay(job, tt.attempt)
+			if diff := got - tt.want; diff < -time.Microsecond || diff > time.Microsecond {
+				t.Errorf("retryDelay = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelayJitterBounds(t *testing.T) {
+	job := models.ScheduledJob{RetryInitialDelaySec: 120, RetryMultiplier: 3}
+	for attempt := 1; attempt <= 5; attempt++ {
+		base := time.Duration(120*pow3(attempt-1)) * time.Second
+		low := time.Duration(float64(base) * (1 - retryJitter))
+		high := time.Duration(float64(base) * (1 + retryJitter))
+		for i := 0; i < 200; i++ {
+			got := retryDelay(job, attempt)
+			if got < low || got > high {
+				t.Fatalf("attempt %d: retryDelay = %v di luar [%v, %v]", attempt, got, low, high)
+			}
+		}
+	}
+}
+
+func pow3(n int) int {
+	out := 1
+	for i := 0; i < n; i++ {
+		out *= 3
+	}
+	return out
+}
+
+func TestShouldRetry(t *testing.T) {
+	job := models.ScheduledJob{RetryMaxAttempts: 3, RetryOnStatuses: "FAIL_RCLONE, timeout_rclone"}
+	tests := []struct {
+		name    string
+		job     models.ScheduledJob
+		status  string
+		attempt int
+		want    bool
+	}{
+		{name: "status retryable, sisa percobaan", job: job, status: "FAIL_RCLONE", attempt: 1, want: true},
+		{name: "retry_on case-insensitive", job: job, status: "TIMEOUT_RCLONE", attempt: 2, want: true},
+		{name: "percobaan habis", job: job, status: "FAIL_RCLONE", attempt: 3, want: false},
+		{name: "status tidak dipilih", job: job, status: "FAIL_PRE_SCRIPT", attempt: 1, want: false},
+		{name: "CANCELLED tidak di-retry", job: job, status: "CANCELLED", attempt: 1, want: false},
+		{name: "retry nonaktif (max 1)", job: models.ScheduledJob{RetryMaxAttempts: 1, RetryOnStatuses: "FAIL_RCLONE"}, status: "FAIL_RCLONE", attempt: 1, want: false},
+		{name: "retry_on kosong", job: models.ScheduledJob{RetryMaxAttempts: 5}, status: "FAIL_RCLONE", attempt: 1, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldRetry(tt.job, tt.status, tt.attempt); got != tt.want {
+				t.Errorf("shouldRetry(%s, attempt %d) = %v, want %v", tt.status, tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import "time"
+
+// Sumber pemicu sebuah run (kolom JobRun.TriggerSource)
+const (
+	TriggerSchedule = "SCHEDULE" // Dipicu oleh scheduler (cron)
+	TriggerManual   = "MANUAL"   // Dipicu user lewat tombol Run (POST /jobs/trigger/:id)
+	TriggerAPI      = "API"      // Job baru / restore yang langsung dijalankan lewat API
+	TriggerRetry    = "RETRY"    // Percobaan ulang otomatis setelah run gagal
+	TriggerRecovery = "RECOVERY" // Dijalankan ulang setelah run sebelumnya terputus (crash/restart)
+	TriggerWorkflow = "WORKFLOW" // Step workflow yang dipicu oleh hasil step sebelumnya
+)
+
+// RunOptions: Parameter satu eksekusi yang dibawa dari antrean dispatcher ke worker
+type RunOptions struct {
+	Trigger string `json:"trigger"`
+	Attempt int    `json:"attempt"` // Percobaan ke-N, 0/1 = percobaan pertama
+
+	// ScheduledFor: Waktu jadwal cron yang diwakili run ini (run terjadwal & susulan)
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	// PendingMissed: Jadwal terlewat berikutnya yang dijalankan setelah run ini selesai (run_all_missed)
+	PendingMissed []time.Time `json:"pending_missed,omitempty"`
+	// DeferredBy: Nama blackout window yang menunda run terjadwal ini
+	DeferredBy string `json:"deferred_by,omitempty"`
+	// WorkflowRunID: Eksekusi workflow yang menunggu hasil run ini (0 = bukan step workflow)
+	WorkflowRunID uint `json:"workflow_run_id,omitempty"`
+	// Restore: Parameter restore dari snapshot tercatat (nil = restore mentah dari source_path)
+	Restore *RestoreOptions `json:"restore,omitempty"`
+}
+
+// RestoreOptions: Parameter restore point-in-time yang dibawa ke worker
+type RestoreOptions struct {
+	RecordID   uint `json:"record_id,omitempty"` // RestoreRecord yang menerima hasil run
+	SnapshotID uint `json:"snapshot_id"`
+	SingleFile bool `json:"single_file"` // DestinationPath adalah path file, bukan folder
+	// Paths/Include: Restore selektif (relatif terhadap root snapshot), kosong = seluruh snapshot
+	Paths   []string `json:"paths,omitempty"`
+	Include []string `json:"include,omitempty"`
+	// ConflictPolicy: overwrite | skip-existing | keep-newer | rename-restored
+	ConflictPolicy string `json:"conflict_policy,omitempty"`
+	// QuarantinePath: Folder safety snapshot (rclone --backup-dir), kosong = tanpa safety snapshot
+	QuarantinePath string `json:"quarantine_path,omitempty"`
+}
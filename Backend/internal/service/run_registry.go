@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrJobCancelled: Cause context ketika run dibatalkan oleh user lewat API
+var ErrJobCancelled = errors.New("job dibatalkan oleh user")
+
+// ErrPhaseTimeout: Cause context ketika sebuah fase (pre-script/rclone/post-script) melewati batas waktunya
+var ErrPhaseTimeout = errors.New("batas waktu fase terlampaui")
+
+// ErrShutdown: Cause context ketika run dihentikan karena backend dimatikan (melewati masa tenggang)
+var ErrShutdown = errors.New("backend dimatikan sebelum run selesai")
+
+// ErrRunNotFound: Tidak ada run yang sedang berjalan untuk job tersebut
+var ErrRunNotFound = errors.New("tidak ada run yang sedang berjalan")
+
+// ActiveRun: Informasi satu eksekusi job yang sedang berjalan (in-flight)
+type ActiveRun struct {
+	Key       uint64    `json:"key"`
+	RunID     uint      `json:"run_id"`
+	JobID     uint      `json:"job_id"`
+	RestoreID uint      `json:"restore_id,omitempty"` // Record restore (job restore one-shot ber-ID 0)
+	JobName   string    `json:"job_name"`
+	StartedAt time.Time `json:"started_at"`
+
+	cancel context.CancelCauseFunc
+}
+
+// RunRegistry: Menyimpan context yang bisa dibatalkan untuk setiap run yang sedang berjalan
+type RunRegistry struct {
+	mu      sync.Mutex
+	nextKey uint64
+	runs    map[uint64]*ActiveRun
+}
+
+func NewRunRegistry() *RunRegistry {
+	return &RunRegistry{
+		runs: make(map[uint64]*ActiveRun),
+	}
+}
+
+// Start: Mendaftarkan run baru dan mengembalikan context-nya (restoreID 0 untuk run non-restore).
+// Wajib memanggil Finish(key) setelah run selesai.
+func (r *RunRegistry) Start(runID, jobID, restoreID uint, jobName string) (context.Context, uint64) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextKey++
+	r.runs[r.nextKey] = &ActiveRun{
+		Key:       r.nextKey,
+		RunID:     runID,
+		JobID:     jobID,
+		RestoreID: restoreID,
+		JobName:   jobName,
+		StartedAt: time.Now(),
+		cancel:    cancel,
+	}
+	return ctx, r.nextKey
+}
+
+// AttachRun: Mengisi ID JobRun setelah barisnya dibuat (run didaftarkan sebelum JobRun ada)
+func (r *RunRegistry) AttachRun(key uint64, runID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if run, ok := r.runs[key]; ok {
+		run.RunID = runID
+	}
+}
+
+// Finish: Menghapus run dari registry dan melepas resource context-nya
+func (r *RunRegistry) Finish(key uint64) {
+	r.mu.Lock()
+	run, ok := r.runs[key]
+	delete(r.runs, key)
+	r.mu.Unlock()
+
+	if ok {
+		run.cancel(nil)
+	}
+}
+
+// CancelJob: Membatalkan semua run yang sedang berjalan untuk jobID
+// (jobID 0 ditolak agar tidak ikut membatalkan semua restore one-shot)
+func (r *RunRegistry) CancelJob(jobID uint, cause error) error {
+	if jobID == 0 || r.cancelWhere(func(run *ActiveRun) bool { return run.JobID == jobID }, cause) == 0 {
+		return fmt.Errorf("job ID %d: %w", jobID, ErrRunNotFound)
+	}
+	return nil
+}
+
+// CancelRestore: Membatalkan run yang melayani record restore recordID
+func (r *RunRegistry) CancelRestore(recordID uint, cause error) error {
+	if recordID == 0 || r.cancelWhere(func(run *ActiveRun) bool { return run.RestoreID == recordID }, cause) == 0 {
+		return fmt.Errorf("restore #%d: %w", recordID, ErrRunNotFound)
+	}
+	return nil
+}
+
+// cancelWhere: Membatalkan run yang cocok, mengembalikan jumlahnya
+func (r *RunRegistry) cancelWhere(match func(run *ActiveRun) bool, cause error) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, run := range r.runs {
+		if match(run) {
+			run.cancel(cause)
+			n++
+		}
+	}
+	return n
+}
+
+// CancelAll: Membatalkan semua run yang sedang berjalan, mengembalikan jumlahnya
+func (r *RunRegistry) CancelAll(cause error) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, run := range r.runs {
+		run.cancel(cause)
+	}
+	return len(r.runs)
+}
+
+// IsJobRunning: Cek apakah job punya run aktif di proses ini
+func (r *RunRegistry) IsJobRunning(jobID uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, run := range r.runs {
+		if run.JobID == jobID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRunLive: Cek apakah JobRun dengan ID tersebut sedang berjalan di proses ini
+func (r *RunRegistry) IsRunLive(runID uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, run := range r.runs {
+		if run.RunID == runID {
+			return true
+		}
+	}
+	return false
+}
+
+// List: Snapshot semua run yang sedang berjalan
+func (r *RunRegistry) List() []ActiveRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]ActiveRun, 0, len(r.runs))
+	for _, run := range r.runs {
+		out = append(out, *run)
+	}
+	return out
+}
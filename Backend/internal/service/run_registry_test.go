@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunRegistryCancelRestore(t *testing.T) {
+	r := NewRunRegistry()
+	jobCtx, jobKey := r.Start(1, 7, 0, "backup")
+	restoreA, keyA := r.Start(2, 0, 11, "Restore-#11")
+	restoreB, keyB := r.Start(3, 0, 12, "Restore-#12")
+	defer r.Finish(jobKey)
+	defer r.Finish(keyA)
+	defer r.Finish(keyB)
+
+	if err := r.CancelJob(0, ErrJobCancelled); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("CancelJob(0) = %v, want ErrRunNotFound", err)
+	}
+	if err := r.CancelRestore(99, ErrJobCancelled); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("CancelRestore(99) = %v, want ErrRunNotFound", err)
+	}
+	if err := r.CancelRestore(11, ErrJobCancelled); err != nil {
+		t.Fatalf("CancelRestore(11) = %v", err)
+	}
+
+	if !errors.Is(context.Cause(restoreA), ErrJobCancelled) {
+		t.Errorf("restore #11 tidak dibatalkan: cause = %v", context.Cause(restoreA))
+	}
+	if restoreB.Err() != nil || jobCtx.Err() != nil {
+		t.Errorf("run lain ikut dibatalkan: restore #12 = %v, job 7 = %v", restoreB.Err(), jobCtx.Err())
+	}
+}
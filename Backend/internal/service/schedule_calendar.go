@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCalendarRange: Rentang from/to kalender tidak valid
+var ErrInvalidCalendarRange = errors.New("rentang kalender tidak valid")
+
+// MaxCalendarRange: Rentang maksimal satu permintaan kalender
+const MaxCalendarRange = 31 * 24 * time.Hour
+
+// maxCalendarOccurrences: Batas total jadwal yang diekspansi (cron per detik bisa menghasilkan jutaan)
+const maxCalendarOccurrences = 5000
+
+// calendarHistoryRuns: Jumlah run terakhir yang dipakai untuk estimasi durasi
+const calendarHistoryRuns = 20
+
+// defaultEstimatedDuration: Estimasi durasi untuk job yang belum punya riwayat run
+const defaultEstimatedDuration = 5 * time.Minute
+
+// Sumber estimasi durasi occurrence
+const (
+	DurationFromHistory = "history" // Median durasi run terakhir
+	DurationFromDefault = "default" // Belum ada riwayat run
+)
+
+// CalendarOccurrenceDTO: Satu jadwal konkret job di dalam rentang kalender
+type CalendarOccurrenceDTO struct {
+	JobID       uint      `json:"job_id"`
+	JobName     string    `json:"job_name"`
+	RemoteName  string    `json:"remote_name"`
+	ScheduledAt time.Time `json:"scheduled_at"` // Waktu cron (zona waktu job)
+	// StartAt: Perkiraan mulai (digeser jika jatuh di blackout window)
+	StartAt              time.Time `json:"start_at"`
+	EstimatedEnd         time.Time `json:"estimated_end"`
+	EstimatedDurationSec int       `json:"estimated_duration_sec"`
+	DurationSource       string    `json:"duration_source"`
+	DeferredBy           string    `json:"deferred_by,omitempty"`
+
+	// OverlapsWith: Job lain di remote yang sama yang perkiraan waktunya beririsan
+	OverlapsWith []uint `json:"overlaps_with,omitempty"`
+	// OverRemoteLimit/OverGlobalLimit: Saat mulai, slot MAX_JOBS_PER_REMOTE / MAX_CONCURRENT_JOBS
+	// sudah penuh sehingga run ini diperkirakan menunggu di antrean
+	OverRemoteLimit bool     `json:"over_remote_limit"`
+	OverGlobalLimit bool     `json:"over_global_limit"`
+	Flags           []string `json:"flags,omitempty"`
+}
+
+// ScheduleCalendarDTO: Output GET /api/v1/schedule/calendar
+type ScheduleCalendarDTO struct {
+	From        time.Time               `json:"from"`
+	To          time.Time               `json:"to"`
+	Limits      DispatcherConfig        `json:"limits"`
+	Occurrences []CalendarOccurrenceDTO `json:"occurrences"`
+	Conflicts   int                     `json:"conflicts"` // Jumlah occurrence yang punya flag
+	Truncated   bool                    `json:"truncated"` // true jika melebihi maxCalendarOccurrences
+}
+
+// GetScheduleCalendar: Ekspansi cron semua job aktif menjadi jadwal konkret dalam [from, to)
+func (s *schedulerServiceImpl) GetScheduleCalendar(from, to time.Time) (*ScheduleCalendarDTO, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to harus setelah from", ErrInvalidCalendarRange)
+	}
+	if to.Sub(from) > MaxCalendarRange {
+		return nil, fmt.Errorf("%w: rentang maksimal %d hari", ErrInvalidCalendarRange, int(MaxCalendarRange.Hours()/24))
+	}
+
+	jobs, err := s.JobRepo.FindAllActiveJobs()
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil job aktif dari DB: %w", err)
+	}
+
+	calendar := &ScheduleCalendarDTO{
+		From:        from,
+		To:          to,
+		Limits:      s.Dispatcher.GetQueueStatus().Limits,
+		Occurrences: []CalendarOccurrenceDTO{},
+	}
+
+	for _, job := range jobs {
+		sched, err := jobSchedule(job)
+		if err != nil {
+			continue
+		}
+
+		// Job yang di-pause hanya muncul setelah resume otomatis
+		start := from
+		if !job.IsActive {
+			if job.ResumeAt == nil || !job.ResumeAt.Before(to) {
+				continue
+			}
+			if job.ResumeAt.After(start) {
+				start = *job.ResumeAt
+			}
+		}
+
+		duration, source := s.estimateDuration(job.ID)
+		loc := jobLocation(job)
+		windows := s.jobBlackouts(job)
+
+		// Next() eksklusif, mundur 1 detik agar jadwal tepat di from ikut terhitung
+		for t := sched.Next(start.Add(-time.Second)); !t.IsZero() && t.Before(to); t = sched.Next(t) {
+			if len(calendar.Occurrences) >= maxCalendarOccurrences {
+				calendar.Truncated = true
+				break
+			}
+
+			occ := CalendarOccurrenceDTO{
+				JobID:                job.ID,
+				JobName:              job.JobName,
+				RemoteName:           job.RemoteName,
+				ScheduledAt:          t.In(loc),
+				StartAt:              t.In(loc),
+				EstimatedDurationSec: int(duration.Seconds()),
+				DurationSource:       source,
+			}
+			if until, names, deferred := blackoutDeferral(windows, t, loc); deferred {
+				occ.StartAt = until.In(loc)
+				occ.DeferredBy = strings.Join(names, ", ")
+			}
+			occ.EstimatedEnd = occ.StartAt.Add(duration)
+			calendar.Occurrences = append(calendar.Occurrences, occ)
+		}
+	}
+
+	sort.SliceStable(calendar.Occurrences, func(i, j int) bool {
+		a, b := calendar.Occurrences[i], calendar.Occurrences[j]
+		if !a.StartAt.Equal(b.StartAt) {
+			return a.StartAt.Before(b.StartAt)
+		}
+		return a.JobID < b.JobID
+	})
+
+	flagCalendarConflicts(calendar.Occurrences, calendar.Limits)
+	for _, occ := range calendar.Occurrences {
+		if len(occ.Flags) > 0 {
+			calendar.Conflicts++
+		}
+	}
+	return calendar, nil
+}
+
+// estimateDuration: Median durasi run terakhir (SUCCESS diutamakan), fallback ke default
+func (s *schedulerServiceImpl) estimateDuration(jobID uint) (time.Duration, string) {
+	runs, err := s.RunRepo.FindByJobID(jobID, calendarHistoryRuns)
+	if err != nil {
+		return defaultEstimatedDuration, DurationFromDefault
+	}
+
+	var success, finished []int
+	for _, run := range runs {
+		if run.FinishedAt == nil || run.Status == "SKIPPED" || run.Status == "RUNNING" {
+			continue
+		}
+		finished = append(finished, run.DurationSec)
+		if run.Status == "SUCCESS" {
+			success = append(success, run.DurationSec)
+		}
+	}
+
+	samples := success
+	if len(samples) == 0 {
+		samples = finished
+	}
+	if len(samples) == 0 {
+		return defaultEstimatedDuration, DurationFromDefault
+	}
+
+	sort.Ints(samples)
+	median := samples[len(samples)/2]
+	if median < 1 {
+		median = 1
+	}
+	return time.Duration(median) * time.Second, DurationFromHistory
+}
+
+// flagCalendarConflicts: Menandai irisan di remote yang sama dan occurrence yang melebihi batas konkurensi.
+// occs harus sudah terurut berdasarkan StartAt.
+func flagCalendarConflicts(occs []CalendarOccurrenceDTO, limits DispatcherConfig) {
+	for i := range occs {
+		cur := &occs[i]
+		overlapSeen := make(map[uint]bool)
+		activeGlobal, activeRemote := 0, 0
+
+		for j := range occs {
+			other := occs[j]
+			if !other.StartAt.Before(cur.EstimatedEnd) {
+				break
+			}
+			if !other.EstimatedEnd.After(cur.StartAt) {
+				continue
+			}
+
+			// Run yang sudah mulai (atau mulai bersamaan dengan urutan lebih awal) saat cur mulai
+			activeAtStart := j < i
+			if activeAtStart {
+				activeGlobal++
+			}
+			if other.RemoteName != cur.RemoteName || j == i {
+				continue
+			}
+			if activeAtStart {
+				activeRemote++
+			}
+			if other.JobID != cur.JobID && !overlapSeen[other.JobID] {
+				overlapSeen[other.JobID] = true
+				cur.OverlapsWith = append(cur.OverlapsWith, other.JobID)
+			}
+		}
+
+		if len(cur.OverlapsWith) > 0 {
+			cur.Flags = append(cur.Flags, "REMOTE_OVERLAP")
+		}
+		if activeRemote >= limits.MaxPerRemote {
+			cur.OverRemoteLimit = true
+			cur.Flags = append(cur.Flags, "OVER_REMOTE_LIMIT")
+		}
+		if activeGlobal >= limits.MaxConcurrent {
+			cur.OverGlobalLimit = true
+			cur.Flags = append(cur.Flags, "OVER_GLOBAL_LIMIT")
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// occ: Occurrence job di remote, mulai pada menit startMin (dari base) selama durMin menit
+func occ(jobID uint, remote string, startMin, durMin int) CalendarOccurrenceDTO {
+	base := time.Date(2026, 10, 12, 1, 0, 0, 0, time.UTC)
+	start := base.Add(time.Duration(startMin) * time.Minute)
+	return CalendarOccurrenceDTO{
+		JobID:        jobID,
+		RemoteName:   remote,
+		StartAt:      start,
+		EstimatedEnd: start.Add(time.Duration(durMin) * time.Minute),
+	}
+}
+
+func TestFlagCalendarConflicts(t *testing.T) {
+	type want struct {
+		overlaps []uint
+		flags    []string
+	}
+	wide := DispatcherConfig{MaxConcurrent: 10, MaxPerRemote: 10}
+
+	tests := []struct {
+		name   string
+		occs   []CalendarOccurrenceDTO
+		limits DispatcherConfig
+		want   []want
+	}{
+		{
+			name:   "remote sama beririsan",
+			occs:   []CalendarOccurrenceDTO{occ(1, "gdrive", 0, 60), occ(2, "gdrive", 30, 60)},
+			limits: wide,
+			want: []want{
+				{overlaps: []uint{2}, flags: []string{"REMOTE_OVERLAP"}},
+				{overlaps: []uint{1}, flags: []string{"REMOTE_OVERLAP"}},
+			},
+		},
+		{
+			name:   "remote berbeda tidak dianggap overlap",
+			occs:   []CalendarOccurrenceDTO{occ(1, "gdrive", 0, 60), occ(2, "s3", 30, 60)},
+			limits: wide,
+			want:   []want{{}, {}},
+		},
+		{
+			name:   "bersentuhan di batas tidak beririsan",
+			occs:   []CalendarOccurrenceDTO{occ(1, "gdrive", 0, 60), occ(2, "gdrive", 60, 60)},
+			limits: DispatcherConfig{MaxConcurrent: 1, MaxPerRemote: 1},
+			want:   []want{{}, {}},
+		},
+		{
+			name:   "job yang sama tidak tercatat sebagai overlap, tapi tetap memakai slot remote",
+			occs:   []CalendarOccurrenceDTO{occ(1, "gdrive", 0, 90), occ(1, "gdrive", 60, 90)},
+			limits: DispatcherConfig{MaxConcurrent: 10, MaxPerRemote: 1},
+			want:   []want{{}, {flags: []string{"OVER_REMOTE_LIMIT"}}},
+		},
+		{
+			name: "overlap dengan job yang sama dua kali dicatat sekali",
+			occs: []CalendarOccurrenceDTO{
+				occ(1, "gdrive", 0, 30), occ(1, "gdrive", 20, 30), occ(2, "gdrive", 25, 10),
+			},
+			limits: wide,
+			want: []want{
+				{overlaps: []uint{2}, flags: []string{"REMOTE_OVERLAP"}},
+				{overlaps: []uint{2}, flags: []string{"REMOTE_OVERLAP"}},
+				{overlaps: []uint{1}, flags: []string{"REMOTE_OVERLAP"}},
+			},
+		},
+		{
+			name:   "batas per remote penuh saat mulai",
+			occs:   []CalendarOccurrenceDTO{occ(1, "gdrive", 0, 60), occ(2, "gdrive", 10, 60), occ(3, "gdrive", 20, 60)},
+			limits: DispatcherConfig{MaxConcurrent: 10, MaxPerRemote: 2},
+			want: []want{
+				{overlaps: []uint{2, 3}, flags: []string{"REMOTE_OVERLAP"}},
+				{overlaps: []uint{1, 3}, flags: []string{"REMOTE_OVERLAP"}},
+				{overlaps: []uint{1, 2}, flags: []string{"REMOTE_OVERLAP", "OVER_REMOTE_LIMIT"}},
+			},
+		},
+		{
+			name:   "batas global penuh lintas remote",
+			occs:   []CalendarOccurrenceDTO{occ(1, "gdrive", 0, 60), occ(2, "s3", 10, 60), occ(3, "b2", 20, 60)},
+			limits: DispatcherConfig{MaxConcurrent: 2, MaxPerRemote: 5},
+			want:   []want{{}, {}, {flags: []string{"OVER_GLOBAL_LIMIT"}}},
+		},
+		{
+			name:   "run yang sudah selesai tidak dihitung",
+			occs:   []CalendarOccurrenceDTO{occ(1, "gdrive", 0, 10), occ(2, "s3", 5, 60), occ(3, "gdrive", 30, 60)},
+			limits: DispatcherConfig{MaxConcurrent: 2, MaxPerRemote: 1},
+			want:   []want{{}, {}, {}},
+		},
+		{
+			name:   "mulai bersamaan: urutan lebih awal dianggap sudah memegang slot",
+			occs:   []CalendarOccurrenceDTO{occ(1, "gdrive", 0, 60), occ(2, "gdrive", 0, 60)},
+			limits: DispatcherConfig{MaxConcurrent: 10, MaxPerRemote: 1},
+			want: []want{
+				{overlaps: []uint{2}, flags: []string{"REMOTE_OVERLAP"}},
+				{overlaps: []uint{1}, flags: []string{"REMOTE_OVERLAP", "OVER_REMOTE_LIMIT"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagCalendarConflicts(tt.occs, tt.limits)
+			for i, w := range tt.want {
+				got := tt.occs[i]
+				if !reflect.DeepEqual(got.OverlapsWith, w.overlaps) {
+					t.Errorf("occ %d: OverlapsWith = %v, want %v", i, got.OverlapsWith, w.overlaps)
+				}
+				if !reflect.DeepEqual(got.Flags, w.flags) {
+					t.Errorf("occ %d: Flags = %v, want %v", i, got.Flags, w.flags)
+				}
+				if got.OverRemoteLimit != slices.Contains(w.flags, "OVER_REMOTE_LIMIT") ||
+					got.OverGlobalLimit != slices.Contains(w.flags, "OVER_GLOBAL_LIMIT") {
+					t.Errorf("occ %d: OverRemoteLimit/OverGlobalLimit = %v/%v tidak sesuai flags %v",
+						i, got.OverRemoteLimit, got.OverGlobalLimit, w.flags)
+				}
+			}
+		})
+	}
+}
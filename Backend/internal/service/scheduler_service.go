@@ -1,11 +1,14 @@
 package service
 
 import (
+	"errors"
 	"fmt" // Diperlukan untuk string join
+	"strings"
+	"sync"
 	"time"
 
 	// Sesuaikan path module
-
+	"gbackup-new/backend/internal/models"
 	"gbackup-new/backend/internal/repository" // Sesuaikan path module
 
 	"github.com/robfig/cron/v3"
@@ -23,32 +26,267 @@ type JobMonitoringDTO struct {
 	Status       string `json:"status"`
 	NextRun      string `json:"next_run"`
 	FullScript   string `json:"full_script"`
+	TimeZone     string `json:"time_zone"`
+	// Job yang di-pause tidak dipicu cron hingga di-resume (manual atau otomatis pada ResumeAt)
+	Paused   bool   `json:"paused"`
+	PausedAt string `json:"paused_at,omitempty"`
+	ResumeAt string `json:"resume_at,omitempty"`
+	// Run terjadwal yang sedang ditunda blackout window (menunggu di antrean)
+	DeferredUntil string `json:"deferred_until,omitempty"`
+	DeferredBy    string `json:"deferred_by,omitempty"`
+	// Jika NextRun jatuh di blackout window, run akan digeser ke waktu ini
+	NextRunDeferredTo string `json:"next_run_deferred_to,omitempty"`
 }
 
 // Interface (Kontrak)
 type SchedulerService interface {
 	StartDaemon()
-	RunScheduledJobs() error
+	StopDaemon()
+	ReloadSchedules() error
 	CalculateNextRun(schedule string, lastRun time.Time) time.Time
 	GetScheduledJobsInfo() ([]JobMonitoringDTO, error)
 	GetGeneratedScript(jobID uint) (string, error) // Untuk Pratinjau Script
 	GetManualJob() ([]JobMonitoringDTO, error)
+	GetScheduleCalendar(from, to time.Time) (*ScheduleCalendarDTO, error)
 }
 
 // Implementasi Struct
 type schedulerServiceImpl struct {
-	JobRepo     repository.JobRepository
-	BackupSvc   BackupService // Dependency ke BackupService
-	intervalCek time.Duration
+	JobRepo      repository.JobRepository
+	RunRepo      repository.JobRunRepository // Riwayat run untuk estimasi durasi kalender
+	BlackoutRepo repository.BlackoutRepository
+	BackupSvc    BackupService // Dependency ke BackupService
+	WorkflowSvc  WorkflowService
+	Dispatcher   JobDispatcher
+	intervalCek  time.Duration // Interval pengecekan lease RUNNING
+	lease        time.Duration // Umur maksimal lock RUNNING tanpa run hidup
+	stop         chan struct{}
+	stopOnce     sync.Once
+
+	engine    *cron.Cron
+	mu        sync.Mutex
+	entries   map[uint]cron.EntryID // Job ID -> entry di engine
+	wfEntries map[uint]cron.EntryID // Workflow ID -> entry di engine
 }
 
 // Constructor (Dependency Injection)
-func NewSchedulerService(jRepo repository.JobRepository, bSvc BackupService) SchedulerService {
-	return &schedulerServiceImpl{
-		JobRepo:     jRepo,
-		BackupSvc:   bSvc,
-		intervalCek: 1 * time.Minute, // Daemon mengecek setiap 1 menit
+func NewSchedulerService(jRepo repository.JobRepository, rRepo repository.JobRunRepository, blRepo repository.BlackoutRepository, bSvc BackupService, wfSvc WorkflowService, dispatcher JobDispatcher) SchedulerService {
+	s := &schedulerServiceImpl{
+		JobRepo:      jRepo,
+		RunRepo:      rRepo,
+		BlackoutRepo: blRepo,
+		BackupSvc:    bSvc,
+		WorkflowSvc:  wfSvc,
+		Dispatcher:   dispatcher,
+		intervalCek:  1 * time.Minute, // Daemon mengecek setiap 1 menit
+		lease:        LoadRunningLease(),
+		stop:         make(chan struct{}),
+		engine:       cron.New(cron.WithParser(cronParser), cron.WithChain(cron.Recover(cron.DefaultLogger))),
+		entries:      make(map[uint]cron.EntryID),
+		wfEntries:    make(map[uint]cron.EntryID),
+	}
+	// Scheduler menjadwalkan retry ketika run gagal
+	bSvc.OnJobCompleted(s.handleRunCompleted)
+	// Entry cron didaftarkan ulang setiap kali job dibuat/diubah/dihapus
+	bSvc.OnJobChanged(s.handleJobChanged)
+	wfSvc.OnWorkflowChanged(s.handleWorkflowChanged)
+	return s
+}
+
+// ----------------------------------------------------
+// RETRY OTOMATIS
+// ----------------------------------------------------
+
+// handleRunCompleted: Menjadwalkan retry jika run gagal dengan status yang retryable,
+// lalu meneruskan hasil akhir step workflow ke WorkflowService (memicu job downstream)
+func (s *schedulerServiceImpl) handleRunCompleted(c JobCompletion) {
+	if c.Job.ID == 0 {
+		return
+	}
+
+	rescheduled := s.rescheduleRun(c)
+
+	// Step workflow baru dilanjutkan setelah hasil akhir (bukan saat retry masih dijadwalkan).
+	// Run di luar workflow juga diteruskan: step yang menunggu job yang sama bisa masuk antrean.
+	if !rescheduled {
+		s.WorkflowSvc.HandleStepCompleted(c)
+	}
+
+	// EnqueueAt tidak menulis PENDING selama slot run ini belum dilepas dispatcher, sehingga
+	// status akhir run akan tertinggal padahal job sudah diantre ulang (retry, susulan, manual)
+	if s.Dispatcher.IsQueued(c.Job.ID) {
+		if err := s.JobRepo.UpdateStatus(c.Job.ID, "PENDING"); err != nil {
+			fmt.Printf("⚠️ [SCHEDULER] Gagal set status PENDING Job %d: %v\n", c.Job.ID, err)
+		}
+	}
+}
+
+// rescheduleRun: Retry / antre ulang run yang gagal atau terputus. true = run yang sama akan dicoba lagi.
+func (s *schedulerServiceImpl) rescheduleRun(c JobCompletion) bool {
+	job, run := c.Job, c.Run
+	if run.Status == "SUCCESS" {
+		s.enqueueNextMissed(job, c.Opts.PendingMissed)
+		return false
+	}
+
+	// Run yang terputus karena shutdown dijalankan ulang pada startup berikutnya (antrean persisten)
+	if run.Status == "INTERRUPTED" {
+		if !job.RequeueOnInterrupt {
+			return false
+		}
+		opts := RunOptions{Trigger: TriggerRecovery, WorkflowRunID: c.Opts.WorkflowRunID}
+		if err := s.Dispatcher.Enqueue(job, opts); err != nil {
+			fmt.Printf("⚠️ [SCHEDULER] Gagal antre ulang Job %d yang terputus: %v\n", job.ID, err)
+			return false
+		}
+		return true
+	}
+
+	if !shouldRetry(job, run.Status, run.Attempt) {
+		if job.RetryMaxAttempts > 1 && run.Attempt >= job.RetryMaxAttempts {
+			fmt.Printf("[SCHEDULER] Job %d (%s): retry habis (%d/%d), status akhir %s\n",
+				job.ID, job.JobName, run.Attempt, job.RetryMaxAttempts, run.Status)
+		}
+		s.enqueueNextMissed(job, c.Opts.PendingMissed)
+		return false
+	}
+
+	// Retry membawa sisa jadwal susulan & workflow agar tetap dilanjutkan setelah percobaan selesai
+	opts := RunOptions{
+		Trigger:       TriggerRetry,
+		Attempt:       run.Attempt + 1,
+		ScheduledFor:  c.Opts.ScheduledFor,
+		PendingMissed: c.Opts.PendingMissed,
+		WorkflowRunID: c.Opts.WorkflowRunID,
+	}
+	if err := s.scheduleRetry(job, opts, retryDelay(job, run.Attempt)); err != nil {
+		fmt.Printf("⚠️ [SCHEDULER] Gagal menjadwalkan retry Job %d: %v\n", job.ID, err)
+		return false
+	}
+	return true
+}
+
+// scheduleRetry: Memasukkan percobaan berikutnya ke antrean, jatuh tempo setelah delay
+func (s *schedulerServiceImpl) scheduleRetry(job models.ScheduledJob, opts RunOptions, delay time.Duration) error {
+	dueAt := time.Now().Add(delay)
+	fmt.Printf("[SCHEDULER] 🔁 Job %d (%s): retry attempt %d/%d dijadwalkan %s (jeda %s)\n",
+		job.ID, job.JobName, opts.Attempt, job.RetryMaxAttempts, dueAt.Format("2006-01-02 15:04:05"), delay)
+
+	return s.Dispatcher.EnqueueAt(job, opts, dueAt)
+}
+
+// ----------------------------------------------------
+// JADWAL TERLEWAT (MISFIRE)
+// ----------------------------------------------------
+
+// catchUpMissedRuns: Menangani jadwal yang terlewat selama backend mati sesuai misfire_policy tiap job
+func (s *schedulerServiceImpl) catchUpMissedRuns() error {
+	jobs, err := s.JobRepo.FindAllActiveJobs()
+	if err != nil {
+		return fmt.Errorf("gagal mengambil job aktif dari DB: %w", err)
+	}
+
+	now := time.Now()
+	for _, job := range jobs {
+		// Job yang di-pause tidak disusul; basis misfire digeser saat resume
+		if job.ScheduleCron == "" || !job.IsActive {
+			continue
+		}
+		sched, err := jobSchedule(job)
+		if err != nil {
+			continue
+		}
+
+		// Basis: jadwal terakhir yang ditangani, fallback ke run terakhir / waktu job dibuat
+		since := job.CreatedAt
+		if job.LastScheduledAt != nil {
+			since = *job.LastScheduledAt
+		} else if job.LastRun != nil {
+			since = *job.LastRun
+		}
+
+		missed, truncated := missedOccurrences(sched, since, now)
+		if len(missed) == 0 {
+			continue
+		}
+		toRun, skipped := planMisfires(job, missed, now)
+
+		fmt.Printf("[SCHEDULER] ⏰ Job %d (%s): %d jadwal terlewat (policy %s) → %d dijalankan, %d dilewati\n",
+			job.ID, job.JobName, len(missed), job.MisfirePolicy, len(toRun), len(skipped))
+		if truncated {
+			fmt.Printf("[SCHEDULER] Job %d: hanya %d jadwal terlewat pertama yang dihitung\n", job.ID, len(missed))
+		}
+		s.recordSkipped(job, skipped)
+
+		if err := s.JobRepo.UpdateLastScheduledAt(job.ID, missed[len(missed)-1]); err != nil {
+			fmt.Printf("⚠️ [SCHEDULER] Gagal update last_scheduled_at Job %d: %v\n", job.ID, err)
+		}
+		s.enqueueNextMissed(job, toRun)
+	}
+	return nil
+}
+
+// recordSkipped: Mencatat jadwal yang dilewati ke riwayat run (dibatasi maxRecordedSkips, yang terbaru)
+func (s *schedulerServiceImpl) recordSkipped(job models.ScheduledJob, skipped []skippedOccurrence) {
+	if over := len(skipped) - maxRecordedSkips; over > 0 {
+		fmt.Printf("[SCHEDULER] Job %d: %d jadwal terlewat terlama tidak dicatat ke riwayat\n", job.ID, over)
+		skipped = skipped[over:]
+	}
+	for _, occ := range skipped {
+		if err := s.BackupSvc.RecordSkippedRun(job, occ.At, occ.Reason); err != nil {
+			fmt.Printf("⚠️ [SCHEDULER] Gagal mencatat jadwal terlewat Job %d: %v\n", job.ID, err)
+		}
+	}
+}
+
+// enqueueNextMissed: Memasukkan run susulan pertama ke antrean, sisanya dibawa di RunOptions
+// dan dijalankan berurutan setelah run sebelumnya selesai
+func (s *schedulerServiceImpl) enqueueNextMissed(job models.ScheduledJob, pending []time.Time) {
+	if len(pending) == 0 {
+		return
+	}
+	scheduledFor := pending[0]
+	opts := RunOptions{Trigger: TriggerSchedule, ScheduledFor: &scheduledFor, PendingMissed: pending[1:]}
+
+	if err := s.enqueueScheduled(job, opts); err != nil {
+		fmt.Printf("⚠️ [SCHEDULER] Gagal antre run susulan Job %d (jadwal %s): %v\n",
+			job.ID, scheduledFor.Format("2006-01-02 15:04:05"), err)
+		return
+	}
+	fmt.Printf("[SCHEDULER] ⏩ Job %d (%s): run susulan untuk jadwal %s (%d tersisa)\n",
+		job.ID, job.JobName, scheduledFor.Format("2006-01-02 15:04:05"), len(pending)-1)
+}
+
+// ----------------------------------------------------
+// BLACKOUT WINDOW
+// ----------------------------------------------------
+
+// jobBlackouts: Blackout window yang berlaku untuk job
+func (s *schedulerServiceImpl) jobBlackouts(job models.ScheduledJob) []models.BlackoutWindow {
+	ids := ParseWindowIDs(job.BlackoutWindowIDs)
+	if len(ids) == 0 {
+		return nil
+	}
+	windows, err := s.BlackoutRepo.FindByIDs(ids)
+	if err != nil {
+		fmt.Printf("⚠️ [SCHEDULER] Gagal mengambil blackout window Job %d: %v\n", job.ID, err)
+		return nil
+	}
+	return windows
+}
+
+// enqueueScheduled: Memasukkan run terjadwal ke antrean; jika sekarang berada di blackout window,
+// entry baru jatuh tempo saat jendela berakhir
+func (s *schedulerServiceImpl) enqueueScheduled(job models.ScheduledJob, opts RunOptions) error {
+	until, names, deferred := blackoutDeferral(s.jobBlackouts(job), time.Now(), jobLocation(job))
+	if !deferred {
+		return s.Dispatcher.Enqueue(job, opts)
 	}
+
+	opts.DeferredBy = strings.Join(names, ", ")
+	fmt.Printf("[SCHEDULER] 🌙 Job %d (%s) ditunda blackout window %s hingga %s\n",
+		job.ID, job.JobName, opts.DeferredBy, until.Format("2006-01-02 15:04:05"))
+	return s.Dispatcher.EnqueueAt(job, opts, until)
 }
 
 // ----------------------------------------------------
@@ -60,12 +298,8 @@ func (s *schedulerServiceImpl) CalculateNextRun(schedule string, lastRun time.Ti
 	if schedule == "" {
 		return time.Time{} // Return waktu nol jika cron kosong
 	}
-	parser := cron.NewParser(
-		cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow,
-	)
-	sched, err := parser.Parse(schedule)
+	sched, err := ParseCronExpression(schedule)
 	if err != nil {
-		fmt.Printf("❌ ERROR: Gagal parsing cron expression '%s': %v\n", schedule, err)
 		return time.Time{}
 	}
 
@@ -76,78 +310,243 @@ func (s *schedulerServiceImpl) CalculateNextRun(schedule string, lastRun time.Ti
 	return sched.Next(baseTime)
 }
 
-// RunScheduledJobs: Me-Run Job yang terjadwal
-func (s *schedulerServiceImpl) RunScheduledJobs() error {
+// ReloadSchedules: Mendaftarkan ulang entry cron untuk semua job terjadwal dari DB
+func (s *schedulerServiceImpl) ReloadSchedules() error {
 	jobs, err := s.JobRepo.FindAllActiveJobs() // Mengambil Job (CRON != NULL)
 	if err != nil {
 		return fmt.Errorf("gagal mengambil job aktif dari DB: %w", err)
 	}
 
-	now := time.Now()
+	workflows, err := s.WorkflowSvc.ListWorkflows()
+	if err != nil {
+		return fmt.Errorf("gagal mengambil workflow dari DB: %w", err)
+	}
 
-	for _, job := range jobs {
+	s.mu.Lock()
+	for jobID, entryID := range s.entries {
+		s.engine.Remove(entryID)
+		delete(s.entries, jobID)
+	}
+	for wfID, entryID := range s.wfEntries {
+		s.engine.Remove(entryID)
+		delete(s.wfEntries, wfID)
+	}
+	s.mu.Unlock()
 
-		if job.ScheduleCron == "" {
-			// Job Manual hanya dipicu oleh TriggerManualJob (user/API).
-			// Abaikan sepenuhnya oleh loop scheduler otomatis.
-			fmt.Printf("[SCHEDULER] Job %d (%s) dilewati karena Job Manual (schedule kosong).\n", job.ID, job.JobName)
-			continue
-		}
+	for i := range jobs {
+		s.registerJob(&jobs[i])
+	}
+	for i := range workflows {
+		s.registerWorkflow(&workflows[i])
+	}
+	return nil
+}
 
-		// Tentukan Waktu Basis (Base Time)
-		var baseTime time.Time
-		if job.LastRun != nil {
-			baseTime = *job.LastRun
-		} else {
-			baseTime = job.CreatedAt // WAJIB: Gunakan CreatedAt jika Job baru
-		}
+// handleWorkflowChanged: Sinkronisasi entry cron workflow (wf nil = dihapus)
+func (s *schedulerServiceImpl) handleWorkflowChanged(workflowID uint, wf *models.Workflow) {
+	if wf == nil {
+		s.unregisterWorkflow(workflowID)
+		return
+	}
+	s.registerWorkflow(wf)
+}
 
-		nextRun := s.CalculateNextRun(job.ScheduleCron, baseTime)
+// registerWorkflow: Mengganti entry cron milik workflow (workflow tanpa jadwal hanya dipicu manual)
+func (s *schedulerServiceImpl) registerWorkflow(wf *models.Workflow) {
+	s.unregisterWorkflow(wf.ID)
 
-		// Cek apakah waktu NextRun sudah tiba atau terlewat
-		if nextRun.Before(now) || nextRun.Equal(now) {
+	if wf.ScheduleCron == "" {
+		return
+	}
 
-			// Cek apakah Job sudah RUNNING (Locking)
-			if job.StatusQueue == "RUNNING" {
-				fmt.Printf("[SCHEDULER] Job %d (%s) dilewati karena sudah berjalan.\n", job.ID, job.JobName)
-				continue
-			}
+	sched, err := parseScheduleInZone(wf.ScheduleCron, wf.TimeZone)
+	if err != nil {
+		fmt.Printf("❌ [SCHEDULER] Workflow %d (%s) tidak dijadwalkan: %v\n", wf.ID, wf.Name, err)
+		return
+	}
 
-			lockTime := time.Now()
-			// Update Status menjadi RUNNING (Locking)
-			// (Catatan: StatusQueue akan diubah menjadi COMPLETED/FAILED oleh handleJobCompletion)
-			err := s.JobRepo.UpdateLastRunStatus(job.ID, lockTime, "RUNNING")
+	wfID := wf.ID
+	s.mu.Lock()
+	s.wfEntries[wfID] = s.engine.Schedule(sched, cron.FuncJob(func() { s.fireWorkflow(wfID) }))
+	s.mu.Unlock()
+}
 
-			if err != nil {
-				fmt.Printf("Job %d (%s) Gagal di-lock: %v\n", job.ID, job.JobName, err)
-				continue
-			}
+func (s *schedulerServiceImpl) unregisterWorkflow(workflowID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entryID, ok := s.wfEntries[workflowID]; ok {
+		s.engine.Remove(entryID)
+		delete(s.wfEntries, workflowID)
+	}
+}
+
+// fireWorkflow: Dipanggil engine cron saat jadwal workflow tiba
+func (s *schedulerServiceImpl) fireWorkflow(workflowID uint) {
+	if _, err := s.WorkflowSvc.StartWorkflow(workflowID, TriggerSchedule); err != nil {
+		fmt.Printf("[SCHEDULER] Workflow %d tidak dijalankan: %v\n", workflowID, err)
+	}
+}
+
+// handleJobChanged: Sinkronisasi entry cron ketika job dibuat/diubah/dihapus (job nil = dihapus)
+func (s *schedulerServiceImpl) handleJobChanged(jobID uint, job *models.ScheduledJob) {
+	if job == nil {
+		s.unregisterJob(jobID)
+		return
+	}
+	s.registerJob(job)
+}
+
+// registerJob: Mengganti entry cron milik job (job manual/di-pause/ekspresi rusak hanya dihapus entry-nya)
+func (s *schedulerServiceImpl) registerJob(job *models.ScheduledJob) {
+	s.unregisterJob(job.ID)
+
+	if job.ScheduleCron == "" || !job.IsActive {
+		return
+	}
+
+	sched, err := jobSchedule(*job)
+	if err != nil {
+		// Hanya terjadi untuk data lama; job baru sudah divalidasi saat dibuat
+		fmt.Printf("❌ [SCHEDULER] Job %d (%s) tidak dijadwalkan: %v\n", job.ID, job.JobName, err)
+		return
+	}
+
+	jobID := job.ID
+	s.mu.Lock()
+	s.entries[jobID] = s.engine.Schedule(sched, cron.FuncJob(func() { s.fireJob(jobID) }))
+	s.mu.Unlock()
+}
+
+// unregisterJob: Menghapus entry cron milik job
+func (s *schedulerServiceImpl) unregisterJob(jobID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entryID, ok := s.entries[jobID]; ok {
+		s.engine.Remove(entryID)
+		delete(s.entries, jobID)
+	}
+}
 
-			fmt.Printf("[SCHEDULER] Dispatching Job %d (%s)\n", job.ID, job.JobName)
+// nextRunFromEngine: Jadwal berikutnya menurut entry cron yang terdaftar
+func (s *schedulerServiceImpl) nextRunFromEngine(jobID uint) (time.Time, bool) {
+	s.mu.Lock()
+	entryID, ok := s.entries[jobID]
+	s.mu.Unlock()
+	if !ok {
+		return time.Time{}, false
+	}
 
-			// Panggil BackupService (yang akan meluncurkan Goroutine Eksekusi 3 Fase)
-			s.BackupSvc.TriggerManualJob(job.ID)
+	entry := s.engine.Entry(entryID)
+	if !entry.Valid() || entry.Next.IsZero() {
+		return time.Time{}, false
+	}
+	return entry.Next, true
+}
+
+// fireJob: Dipanggil engine cron saat jadwal job tiba
+func (s *schedulerServiceImpl) fireJob(jobID uint) {
+	job, err := s.JobRepo.FindJobByID(jobID)
+	if err != nil {
+		fmt.Printf("[SCHEDULER] Job %d tidak ditemukan, entry cron dihapus: %v\n", jobID, err)
+		s.unregisterJob(jobID)
+		return
+	}
+	// Entry seharusnya sudah dihapus saat pause; cek ulang untuk berjaga-jaga
+	if !job.IsActive {
+		s.unregisterJob(jobID)
+		return
+	}
+
+	scheduledFor := time.Now().Truncate(time.Second)
+	if err := s.JobRepo.UpdateLastScheduledAt(job.ID, scheduledFor); err != nil {
+		fmt.Printf("⚠️ [SCHEDULER] Gagal update last_scheduled_at Job %d: %v\n", job.ID, err)
+	}
+
+	// Cek apakah Job sudah RUNNING (Locking)
+	if job.StatusQueue == "RUNNING" {
+		fmt.Printf("[SCHEDULER] Job %d (%s) dilewati karena sudah berjalan.\n", job.ID, job.JobName)
+		if err := s.BackupSvc.RecordSkippedRun(*job, scheduledFor, "Run sebelumnya masih berjalan"); err != nil {
+			fmt.Printf("⚠️ [SCHEDULER] Gagal mencatat jadwal terlewat Job %d: %v\n", job.ID, err)
+		}
+		return
+	}
+
+	fmt.Printf("[SCHEDULER] Dispatching Job %d (%s)\n", job.ID, job.JobName)
+
+	// Job masuk antrean dispatcher (ditunda jika sedang di blackout window). EnqueueAt menulis
+	// status PENDING hanya jika entry benar-benar masuk antrean; RUNNING ditulis worker saat dijalankan.
+	if err := s.enqueueScheduled(*job, RunOptions{Trigger: TriggerSchedule, ScheduledFor: &scheduledFor}); err != nil {
+		fmt.Printf("[SCHEDULER] Job %d (%s) gagal masuk antrean: %v\n", job.ID, job.JobName, err)
+		if errors.Is(err, ErrJobAlreadyQueued) {
+			if err := s.BackupSvc.RecordSkippedRun(*job, scheduledFor, "Run sebelumnya masih menunggu di antrean"); err != nil {
+				fmt.Printf("⚠️ [SCHEDULER] Gagal mencatat jadwal terlewat Job %d: %v\n", job.ID, err)
+			}
 		}
 	}
-	return nil
 }
 
-// StartDaemon: Meluncurkan Goroutine tak terbatas
+// StartDaemon: Mendaftarkan semua job ke engine cron dan menjalankan pengecekan lease berkala
 func (s *schedulerServiceImpl) StartDaemon() {
+	if err := s.ReloadSchedules(); err != nil {
+		fmt.Printf("⚠️ Daemon Error: %v\n", err)
+	}
+	// Jadwal yang terlewat selama backend mati ditangani sebelum engine mulai berdetak
+	if err := s.catchUpMissedRuns(); err != nil {
+		fmt.Printf("⚠️ Daemon Error (misfire): %v\n", err)
+	}
+	s.engine.Start()
+
+	s.mu.Lock()
+	registered := len(s.entries)
+	s.mu.Unlock()
+	fmt.Printf("🚀 Scheduler Daemon Aktif (cron engine, %d job terjadwal), pengecekan lease tiap %s\n", registered, s.intervalCek)
+
 	go func() {
-		fmt.Printf("🚀 Scheduler Daemon Aktif, Pengecekan Job tiap %s\n", s.intervalCek)
-		// Loop tak terbatas
+		ticker := time.NewTicker(s.intervalCek)
+		defer ticker.Stop()
+
 		for {
-			err := s.RunScheduledJobs()
-			if err != nil {
-				fmt.Printf("⚠️ Daemon Error: %v\n", err)
+			select {
+			case <-ticker.C:
+				// Deteksi lock RUNNING basi (lebih tua dari lease, tanpa run hidup)
+				if _, err := s.BackupSvc.RecoverInterruptedJobs(s.lease); err != nil {
+					fmt.Printf("⚠️ Daemon Error (recovery): %v\n", err)
+				}
+				s.resumeDueJobs()
+			case <-s.stop:
+				return
 			}
-			// Tunggu sebelum pengecekan berikutnya
-			time.Sleep(s.intervalCek)
 		}
 	}()
 }
 
+// resumeDueJobs: Resume otomatis job yang resume_at-nya sudah lewat
+func (s *schedulerServiceImpl) resumeDueJobs() {
+	jobs, err := s.JobRepo.FindJobsDueForResume(time.Now())
+	if err != nil {
+		fmt.Printf("⚠️ Daemon Error (resume): %v\n", err)
+		return
+	}
+	for _, job := range jobs {
+		if err := s.BackupSvc.ResumeJob(job.ID); err != nil {
+			fmt.Printf("⚠️ [SCHEDULER] Gagal resume otomatis Job %d: %v\n", job.ID, err)
+			continue
+		}
+		fmt.Printf("[SCHEDULER] ▶️ Job %d (%s) di-resume otomatis\n", job.ID, job.JobName)
+	}
+}
+
+// StopDaemon: Menghentikan engine cron (tidak ada job terjadwal baru yang dipicu)
+func (s *schedulerServiceImpl) StopDaemon() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.engine.Stop().Done()
+		fmt.Println("⏹️ Scheduler Daemon berhenti")
+	})
+}
+
 // ----------------------------------------------------
 // LOGIKA JOB MONITORING (DATA READ)
 // ----------------------------------------------------
@@ -159,6 +558,14 @@ func (s *schedulerServiceImpl) GetScheduledJobsInfo() ([]JobMonitoringDTO, error
 		return nil, fmt.Errorf("gagal mengambil job aktif dari DB: %w", err)
 	}
 
+	// Run terjadwal yang sedang ditunda blackout window
+	deferredEntries := make(map[uint]QueueItemDTO)
+	for _, item := range s.Dispatcher.GetQueueStatus().Queued {
+		if item.DeferredBy != "" {
+			deferredEntries[item.JobID] = item
+		}
+	}
+
 	var output []JobMonitoringDTO
 
 	for _, job := range jobs {
@@ -173,7 +580,25 @@ func (s *schedulerServiceImpl) GetScheduledJobsInfo() ([]JobMonitoringDTO, error
 			baseTime = job.CreatedAt
 		}
 
-		nextRunTime := s.CalculateNextRun(job.ScheduleCron, baseTime)
+		loc := jobLocation(job)
+		nextRunTime, ok := s.nextRunFromEngine(job.ID)
+		if !ok {
+			if sched, err := jobSchedule(job); err == nil {
+				if baseTime.IsZero() {
+					baseTime = time.Now()
+				}
+				nextRunTime = sched.Next(baseTime)
+			}
+		}
+		// Job yang di-pause baru jalan lagi pada jadwal pertama setelah resume otomatis
+		if !job.IsActive {
+			nextRunTime = time.Time{}
+			if job.ResumeAt != nil {
+				if sched, err := jobSchedule(job); err == nil {
+					nextRunTime = sched.Next(*job.ResumeAt)
+				}
+			}
+		}
 		mode := "Auto"
 
 		fullScript, err := s.GetGeneratedScript(job.ID)
@@ -184,7 +609,7 @@ func (s *schedulerServiceImpl) GetScheduledJobsInfo() ([]JobMonitoringDTO, error
 		// Menggabungkan Tipe dan Path
 		jobTypeFormatted := fmt.Sprintf("%s: %s", job.RcloneMode, job.SourcePath)
 
-		output = append(output, JobMonitoringDTO{
+		dto := JobMonitoringDTO{
 			ID:           job.ID,
 			JobName:      job.JobName,
 			Type:         jobTypeFormatted,
@@ -195,7 +620,31 @@ func (s *schedulerServiceImpl) GetScheduledJobsInfo() ([]JobMonitoringDTO, error
 			Status:       job.StatusQueue,
 			NextRun:      nextRunTime.Format("2006-01-02 15:04:05"),
 			FullScript:   fullScript,
-		})
+			TimeZone:     loc.String(),
+			Paused:       !job.IsActive,
+		}
+
+		if !nextRunTime.IsZero() {
+			dto.NextRun = nextRunTime.In(loc).Format("2006-01-02 15:04:05")
+		} else if !job.IsActive {
+			dto.NextRun = "N/A"
+		}
+		if job.PausedAt != nil {
+			dto.PausedAt = job.PausedAt.In(loc).Format("2006-01-02 15:04:05")
+		}
+		if job.ResumeAt != nil {
+			dto.ResumeAt = job.ResumeAt.In(loc).Format("2006-01-02 15:04:05")
+		}
+		if item, ok := deferredEntries[job.ID]; ok {
+			dto.DeferredUntil = item.DueAt.In(loc).Format("2006-01-02 15:04:05")
+			dto.DeferredBy = item.DeferredBy
+		}
+		if !nextRunTime.IsZero() {
+			if until, _, deferred := blackoutDeferral(s.jobBlackouts(job), nextRunTime, loc); deferred {
+				dto.NextRunDeferredTo = until.In(loc).Format("2006-01-02 15:04:05")
+			}
+		}
+		output = append(output, dto)
 	}
 	return output, nil
 }
@@ -261,6 +710,7 @@ func (s *schedulerServiceImpl) GetManualJob() ([]JobMonitoringDTO, error) {
 			Status:       job.StatusQueue,
 			NextRun:      "N/A",
 			FullScript:   "N/A",
+			Paused:       !job.IsActive,
 		})
 	}
 	return output, nil
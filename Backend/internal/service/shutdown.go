@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// shutdownCancelWait: Waktu tunggu run yang dibatalkan untuk mencatat status INTERRUPTED
+// (process group di-kill, lalu handleJobCompletion menulis log & JobRun)
+const shutdownCancelWait = processKillGrace + 10*time.Second
+
+// LoadShutdownGrace: Masa tenggang job berjalan saat backend dimatikan (ENV SHUTDOWN_GRACE_SECONDS, default 120)
+func LoadShutdownGrace() time.Duration {
+	return time.Duration(envInt("SHUTDOWN_GRACE_SECONDS", 120)) * time.Second
+}
+
+// Shutdown: Menghentikan dispatcher, menunggu run berjalan hingga grace habis,
+// lalu membatalkan sisanya dengan status INTERRUPTED. Kembali setelah semua worker selesai mencatat hasil.
+func (s *backupServiceImpl) Shutdown(grace time.Duration) {
+	s.Dispatcher.Stop()
+
+	if active := len(s.Registry.List()); active > 0 {
+		fmt.Printf("[SHUTDOWN] ⏳ Menunggu %d job berjalan (maks %s)...\n", active, grace)
+	}
+
+	graceCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if err := s.Dispatcher.WaitIdle(graceCtx); err == nil {
+		return
+	}
+
+	n := s.Registry.CancelAll(ErrShutdown)
+	fmt.Printf("[SHUTDOWN] ⛔ Masa tenggang habis, %d job dihentikan sebagai INTERRUPTED\n", n)
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), shutdownCancelWait)
+	defer cancelWait()
+	if err := s.Dispatcher.WaitIdle(waitCtx); err != nil {
+		// Sisa run tanpa catatan akan ditangani RecoverInterruptedJobs saat startup berikutnya
+		fmt.Printf("⚠️ [SHUTDOWN] Sebagian job belum selesai dicatat: %v\n", err)
+	}
+}
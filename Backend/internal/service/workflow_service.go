@@ -0,0 +1,635 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gbackup-new/backend/internal/models"
+	"gbackup-new/backend/internal/repository"
+)
+
+// ErrInvalidWorkflow: Definisi workflow tidak valid (step/edge/DAG)
+var ErrInvalidWorkflow = errors.New("workflow tidak valid")
+
+// ErrWorkflowRunning: Workflow masih punya eksekusi yang belum selesai
+var ErrWorkflowRunning = errors.New("workflow masih berjalan")
+
+// Kondisi edge workflow
+const (
+	EdgeOnSuccess = "ON_SUCCESS"
+	EdgeOnFailure = "ON_FAILURE"
+)
+
+// Status step selama workflow berjalan (status akhir step = status JobRun, atau SKIPPED)
+const (
+	StepPending = "PENDING" // Menunggu step sebelumnya
+	StepQueued  = "QUEUED"  // Sudah masuk antrean dispatcher / sedang berjalan
+	StepSkipped = "SKIPPED" // Kondisi edge tidak terpenuhi
+)
+
+// stepWaitingQueue: Pesan step PENDING yang job-nya masih antre di luar run ini (dicoba lagi saat ada run selesai)
+const stepWaitingQueue = "Menunggu entry antrean job yang sama"
+
+// defaultWorkflowRunHistoryLimit: Jumlah eksekusi workflow yang dikembalikan jika limit tidak dikirim
+const defaultWorkflowRunHistoryLimit = 50
+
+// WorkflowStepState: Status satu step di dalam satu eksekusi workflow
+type WorkflowStepState struct {
+	Status string `json:"status"`
+	RunID  uint   `json:"run_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WorkflowChangeListener: Callback setelah workflow dibuat/diubah (wf terbaru) atau dihapus (wf nil)
+type WorkflowChangeListener func(workflowID uint, wf *models.Workflow)
+
+// WorkflowService: Definisi workflow (DAG job) dan eksekusinya
+type WorkflowService interface {
+	CreateWorkflow(wf *models.Workflow) error
+	UpdateWorkflow(wf *models.Workflow) error
+	DeleteWorkflow(workflowID uint) error
+	GetWorkflow(workflowID uint) (*models.Workflow, error)
+	ListWorkflows() ([]models.Workflow, error)
+	StartWorkflow(workflowID uint, trigger string) (*models.WorkflowRun, error)
+	GetWorkflowRuns(workflowID uint, limit int) ([]models.WorkflowRun, error)
+	GetWorkflowRun(runID uint) (*models.WorkflowRun, []models.JobRun, error)
+	HandleStepCompleted(c JobCompletion)
+	ReconcileRuns() error
+	OnWorkflowChanged(listener WorkflowChangeListener)
+}
+
+type workflowServiceImpl struct {
+	WorkflowRepo repository.WorkflowRepository
+	JobRepo      repository.JobRepository
+	RunRepo      repository.JobRunRepository
+	Dispatcher   JobDispatcher
+
+	// mu: Serialisasi update StepStates (beberapa step bisa selesai bersamaan)
+	mu sync.Mutex
+
+	listenersMu sync.Mutex
+	listeners   []WorkflowChangeListener
+}
+
+func NewWorkflowService(wfRepo repository.WorkflowRepository, jRepo repository.JobRepository, rRepo repository.JobRunRepository, dispatcher JobDispatcher) WorkflowService {
+	s := &workflowServiceImpl{
+		WorkflowRepo: wfRepo,
+		JobRepo:      jRepo,
+		RunRepo:      rRepo,
+		Dispatcher:   dispatcher,
+	}
+	// Step yang entry antreannya dibuang dispatcher tidak akan pernah selesai sendiri
+	dispatcher.OnEntryDropped(s.handleEntryDropped)
+	return s
+}
+
+// ----------------------------------------------------
+// DEFINISI WORKFLOW
+// ----------------------------------------------------
+
+// validateWorkflow: Normalisasi & validasi step, edge, jadwal, dan memastikan graf tidak bersiklus
+func (s *workflowServiceImpl) validateWorkflow(wf *models.Workflow) error {
+	wf.Name = strings.TrimSpace(wf.Name)
+	if wf.Name == "" {
+		return fmt.Errorf("%w: name wajib diisi", ErrInvalidWorkflow)
+	}
+	if err := ValidateCronExpression(wf.ScheduleCron); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWorkflow, err)
+	}
+	if err := ValidateTimeZone(wf.TimeZone); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWorkflow, err)
+	}
+	if len(wf.Steps) == 0 {
+		return fmt.Errorf("%w: minimal satu job", ErrInvalidWorkflow)
+	}
+
+	nodes := make(map[uint]bool)
+	for _, step := range wf.Steps {
+		if nodes[step.JobID] {
+			return fmt.Errorf("%w: job %d muncul lebih dari sekali", ErrInvalidWorkflow, step.JobID)
+		}
+		if _, err := s.JobRepo.FindJobByID(step.JobID); err != nil {
+			return fmt.Errorf("%w: job %d tidak ditemukan", ErrInvalidWorkflow, step.JobID)
+		}
+		nodes[step.JobID] = true
+	}
+
+	type edgeKey struct{ from, to uint }
+	seen := make(map[edgeKey]bool)
+	for i := range wf.Edges {
+		edge := &wf.Edges[i]
+		edge.Condition = strings.ToUpper(strings.TrimSpace(edge.Condition))
+		if edge.Condition == "" {
+			edge.Condition = EdgeOnSuccess
+		}
+		if edge.Condition != EdgeOnSuccess && edge.Condition != EdgeOnFailure {
+			return fmt.Errorf("%w: condition '%s' tidak valid (on_success/on_failure)", ErrInvalidWorkflow, edge.Condition)
+		}
+		if !nodes[edge.FromJobID] || !nodes[edge.ToJobID] {
+			return fmt.Errorf("%w: edge %d → %d merujuk job di luar workflow", ErrInvalidWorkflow, edge.FromJobID, edge.ToJobID)
+		}
+		if edge.FromJobID == edge.ToJobID {
+			return fmt.Errorf("%w: edge %d → %d menunjuk dirinya sendiri", ErrInvalidWorkflow, edge.FromJobID, edge.ToJobID)
+		}
+		key := edgeKey{edge.FromJobID, edge.ToJobID}
+		if seen[key] {
+			return fmt.Errorf("%w: edge %d → %d duplikat", ErrInvalidWorkflow, edge.FromJobID, edge.ToJobID)
+		}
+		seen[key] = true
+	}
+
+	return checkAcyclic(nodes, wf.Edges)
+}
+
+// checkAcyclic: Kahn — semua node harus bisa diurutkan secara topologis
+func checkAcyclic(nodes map[uint]bool, edges []models.WorkflowEdge) error {
+	inDegree := make(map[uint]int)
+	for _, edge := range edges {
+		inDegree[edge.ToJobID]++
+	}
+	var ready []uint
+	for id := range nodes {
+		if inDegree[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	visited := 0
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		visited++
+		for _, edge := range edges {
+			if edge.FromJobID == id {
+				inDegree[edge.ToJobID]--
+				if inDegree[edge.ToJobID] == 0 {
+					ready = append(ready, edge.ToJobID)
+				}
+			}
+		}
+	}
+	if visited != len(nodes) {
+		return fmt.Errorf("%w: edge membentuk siklus", ErrInvalidWorkflow)
+	}
+	return nil
+}
+
+func (s *workflowServiceImpl) CreateWorkflow(wf *models.Workflow) error {
+	if err := s.validateWorkflow(wf); err != nil {
+		return err
+	}
+	if err := s.WorkflowRepo.Create(wf); err != nil {
+		return err
+	}
+	fmt.Printf("[WORKFLOW] Workflow %d (%s) dibuat: %d job, %d edge\n", wf.ID, wf.Name, len(wf.Steps), len(wf.Edges))
+	s.notifyWorkflowChanged(wf.ID, wf)
+	return nil
+}
+
+func (s *workflowServiceImpl) UpdateWorkflow(wf *models.Workflow) error {
+	if err := s.validateWorkflow(wf); err != nil {
+		return err
+	}
+	if err := s.WorkflowRepo.Update(wf); err != nil {
+		return err
+	}
+	fmt.Printf("[WORKFLOW] Workflow %d (%s) diperbarui\n", wf.ID, wf.Name)
+	s.notifyWorkflowChanged(wf.ID, wf)
+	return nil
+}
+
+func (s *workflowServiceImpl) DeleteWorkflow(workflowID uint) error {
+	if err := s.WorkflowRepo.Delete(workflowID); err != nil {
+		return err
+	}
+	fmt.Printf("[AUDIT] Workflow %d dihapus\n", workflowID)
+	s.notifyWorkflowChanged(workflowID, nil)
+	return nil
+}
+
+func (s *workflowServiceImpl) GetWorkflow(workflowID uint) (*models.Workflow, error) {
+	return s.WorkflowRepo.FindByID(workflowID)
+}
+
+func (s *workflowServiceImpl) ListWorkflows() ([]models.Workflow, error) {
+	return s.WorkflowRepo.FindAll()
+}
+
+// OnWorkflowChanged: Mendaftarkan listener perubahan workflow (mis. scheduler untuk entry cron)
+func (s *workflowServiceImpl) OnWorkflowChanged(listener WorkflowChangeListener) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *workflowServiceImpl) notifyWorkflowChanged(workflowID uint, wf *models.Workflow) {
+	s.listenersMu.Lock()
+	listeners := append([]WorkflowChangeListener(nil), s.listeners...)
+	s.listenersMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(workflowID, wf)
+	}
+}
+
+// ----------------------------------------------------
+// EKSEKUSI WORKFLOW
+// ----------------------------------------------------
+
+// StartWorkflow: Membuat WorkflowRun dan memasukkan job root (tanpa edge masuk) ke antrean
+func (s *workflowServiceImpl) StartWorkflow(workflowID uint, trigger string) (*models.WorkflowRun, error) {
+	wf, err := s.WorkflowRepo.FindByID(workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	running, err := s.WorkflowRepo.FindRunningRuns()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range running {
+		if r.WorkflowID == workflowID {
+			return nil, fmt.Errorf("workflow %d (run %d): %w", workflowID, r.ID, ErrWorkflowRunning)
+		}
+	}
+
+	states := make(map[uint]*WorkflowStepState)
+	for _, step := range wf.Steps {
+		states[step.JobID] = &WorkflowStepState{Status: StepPending}
+	}
+
+	run := &models.WorkflowRun{
+		WorkflowID:    wf.ID,
+		WorkflowName:  wf.Name,
+		TriggerSource: trigger,
+		Status:        "RUNNING",
+		StartedAt:     time.Now(),
+	}
+	if err := s.saveStates(run, states); err != nil {
+		return nil, err
+	}
+	if err := s.WorkflowRepo.CreateRun(run); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("[WORKFLOW] ▶️ Workflow %d (%s) mulai, run %d (trigger %s)\n", wf.ID, wf.Name, run.ID, trigger)
+
+	// Root (tanpa edge masuk) langsung dijalankan oleh advance
+	s.advance(run, wf, states)
+
+	if err := s.saveStates(run, states); err != nil {
+		return nil, err
+	}
+	if err := s.WorkflowRepo.SaveRun(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// HandleStepCompleted: Dipanggil scheduler setelah run selesai (dan tidak akan di-retry).
+// Run di luar workflow pun diteruskan ke sini agar step yang menunggu job yang sama dicoba lagi.
+func (s *workflowServiceImpl) HandleStepCompleted(c JobCompletion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.Opts.WorkflowRunID != 0 {
+		s.completeStep(c)
+	}
+	s.retryWaitingSteps(c.Job.ID, c.Opts.WorkflowRunID)
+}
+
+// completeStep: Mencatat hasil run step lalu melanjutkan workflow. Wajib memegang mu.
+func (s *workflowServiceImpl) completeStep(c JobCompletion) {
+	run, err := s.WorkflowRepo.FindRunByID(c.Opts.WorkflowRunID)
+	if err != nil {
+		fmt.Printf("⚠️ [WORKFLOW] %v\n", err)
+		return
+	}
+	if run.Status != "RUNNING" {
+		return
+	}
+
+	states := LoadStepStates(run)
+	state, ok := states[c.Job.ID]
+	if !ok {
+		return
+	}
+	state.Status = c.Run.Status
+	state.RunID = c.Run.ID
+	fmt.Printf("[WORKFLOW] Run %d: step Job %d (%s) selesai dengan status %s\n", run.ID, c.Job.ID, c.Job.JobName, c.Run.Status)
+
+	s.advanceAndSave(run, states)
+}
+
+// handleEntryDropped: Entry step yang dibuang dispatcher (mis. job dihapus) dicatat FAILED agar
+// workflow tidak tertahan RUNNING selamanya
+func (s *workflowServiceImpl) handleEntryDropped(entry models.QueueEntry, opts RunOptions, reason error) {
+	if entry.JobID == nil {
+		return
+	}
+	jobID := *entry.JobID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if opts.WorkflowRunID != 0 {
+		s.failDroppedStep(opts.WorkflowRunID, jobID, reason)
+	}
+	s.retryWaitingSteps(jobID, opts.WorkflowRunID)
+}
+
+// failDroppedStep: Menandai step QUEUED milik entry yang dibuang sebagai FAILED. Wajib memegang mu.
+func (s *workflowServiceImpl) failDroppedStep(runID, jobID uint, reason error) {
+	run, err := s.WorkflowRepo.FindRunByID(runID)
+	if err != nil {
+		fmt.Printf("⚠️ [WORKFLOW] %v\n", err)
+		return
+	}
+	if run.Status != "RUNNING" {
+		return
+	}
+
+	states := LoadStepStates(run)
+	state, ok := states[jobID]
+	if !ok || state.Status != StepQueued {
+		return
+	}
+	state.Status = "FAILED"
+	state.Error = fmt.Sprintf("Entry antrean dibuang: %v", reason)
+	fmt.Printf("⚠️ [WORKFLOW] Run %d: step Job %d dibuang dari antrean: %v\n", run.ID, jobID, reason)
+
+	s.advanceAndSave(run, states)
+}
+
+// retryWaitingSteps: Mencoba lagi step yang tertahan karena job-nya sudah antre (kecuali run skipRunID,
+// yang sudah dilanjutkan pemanggil). Wajib memegang mu.
+func (s *workflowServiceImpl) retryWaitingSteps(jobID, skipRunID uint) {
+	runs, err := s.WorkflowRepo.FindRunningRuns()
+	if err != nil {
+		fmt.Printf("⚠️ [WORKFLOW] Gagal mengambil workflow run: %v\n", err)
+		return
+	}
+	for i := range runs {
+		run := &runs[i]
+		if run.ID == skipRunID {
+			continue
+		}
+		states := LoadStepStates(run)
+		if state, ok := states[jobID]; !ok || state.Status != StepPending || state.Error != stepWaitingQueue {
+			continue
+		}
+		s.advanceAndSave(run, states)
+	}
+}
+
+// ReconcileRuns: Menyelesaikan step yang hasilnya tercatat saat backend mati (dipanggil saat startup,
+// setelah RecoverInterruptedJobs), lalu melanjutkan workflow
+func (s *workflowServiceImpl) ReconcileRuns() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs, err := s.WorkflowRepo.FindRunningRuns()
+	if err != nil {
+		return fmt.Errorf("gagal mengambil workflow run: %w", err)
+	}
+
+	for i := range runs {
+		run := &runs[i]
+		states := LoadStepStates(run)
+
+		stepRuns, err := s.RunRepo.FindByWorkflowRunID(run.ID)
+		if err != nil {
+			fmt.Printf("⚠️ [WORKFLOW] Gagal mengambil run step workflow run %d: %v\n", run.ID, err)
+			continue
+		}
+		latest := make(map[uint]models.JobRun)
+		for _, jr := range stepRuns {
+			if jr.JobID != nil {
+				latest[*jr.JobID] = jr // Urut started_at ASC, yang terakhir menang
+			}
+		}
+
+		for jobID, state := range states {
+			if state.Status != StepQueued || s.Dispatcher.IsQueued(jobID) {
+				continue
+			}
+			jr, ok := latest[jobID]
+			switch {
+			case ok && jr.Status != "RUNNING":
+				state.Status = jr.Status
+				state.RunID = jr.ID
+			case !ok:
+				state.Status = "FAILED"
+				state.Error = "Step tidak ditemukan di antrean maupun riwayat run"
+			}
+		}
+
+		s.advanceAndSave(run, states)
+	}
+	return nil
+}
+
+// GetWorkflowRuns: Riwayat eksekusi workflow (limit <= 0 = default)
+func (s *workflowServiceImpl) GetWorkflowRuns(workflowID uint, limit int) ([]models.WorkflowRun, error) {
+	if limit <= 0 {
+		limit = defaultWorkflowRunHistoryLimit
+	}
+	return s.WorkflowRepo.FindRunsByWorkflowID(workflowID, limit)
+}
+
+// GetWorkflowRun: Satu eksekusi workflow beserta JobRun tiap step
+func (s *workflowServiceImpl) GetWorkflowRun(runID uint) (*models.WorkflowRun, []models.JobRun, error) {
+	run, err := s.WorkflowRepo.FindRunByID(runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	stepRuns, err := s.RunRepo.FindByWorkflowRunID(runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, stepRuns, nil
+}
+
+// advanceAndSave: Melanjutkan workflow lalu menyimpan state. Wajib memegang mu.
+func (s *workflowServiceImpl) advanceAndSave(run *models.WorkflowRun, states map[uint]*WorkflowStepState) {
+	wf, err := s.WorkflowRepo.FindByID(run.WorkflowID)
+	if err != nil {
+		// Workflow dihapus saat berjalan: tidak ada step lanjutan
+		wf = &models.Workflow{ID: run.WorkflowID}
+		for jobID, state := range states {
+			if state.Status == StepPending {
+				states[jobID] = &WorkflowStepState{Status: StepSkipped, Error: "Workflow dihapus"}
+			}
+		}
+	}
+
+	s.advance(run, wf, states)
+
+	if err := s.saveStates(run, states); err != nil {
+		fmt.Printf("⚠️ [WORKFLOW] %v\n", err)
+		return
+	}
+	if err := s.WorkflowRepo.SaveRun(run); err != nil {
+		fmt.Printf("⚠️ [WORKFLOW] %v\n", err)
+	}
+}
+
+// advance: Menjalankan/melewati step PENDING yang semua step sebelumnya sudah selesai,
+// lalu menutup run jika semua step final. Wajib memegang mu.
+func (s *workflowServiceImpl) advance(run *models.WorkflowRun, wf *models.Workflow, states map[uint]*WorkflowStepState) {
+	for changed := true; changed; {
+		changed = false
+		for _, jobID := range sortedStepIDs(states) {
+			state := states[jobID]
+			if state.Status != StepPending {
+				continue
+			}
+
+			incoming := incomingEdges(wf, jobID)
+			resolved, satisfied := true, true
+			for _, edge := range incoming {
+				parent, ok := states[edge.FromJobID]
+				if !ok || !isStepFinal(parent.Status) {
+					resolved = false
+					break
+				}
+				if !edgeSatisfied(edge, parent.Status) {
+					satisfied = false
+				}
+			}
+			if !resolved {
+				continue
+			}
+
+			if !satisfied {
+				state.Status = StepSkipped
+				changed = true
+				continue
+			}
+			if s.dispatchStep(run, jobID, states) {
+				changed = true
+			}
+		}
+	}
+
+	for _, state := range states {
+		if !isStepFinal(state.Status) {
+			return
+		}
+	}
+	s.finishRun(run, states)
+}
+
+// dispatchStep: Memasukkan job step ke antrean dengan WorkflowRunID. Wajib memegang mu.
+// false = step tetap PENDING karena job-nya sudah antre di luar run ini; dicoba lagi saat ada run selesai.
+func (s *workflowServiceImpl) dispatchStep(run *models.WorkflowRun, jobID uint, states map[uint]*WorkflowStepState) bool {
+	state := states[jobID]
+
+	job, err := s.JobRepo.FindJobByID(jobID)
+	if err != nil {
+		state.Status = "FAILED"
+		state.Error = fmt.Sprintf("Job tidak ditemukan: %v", err)
+		return true
+	}
+	if err := s.Dispatcher.Enqueue(*job, RunOptions{Trigger: TriggerWorkflow, WorkflowRunID: run.ID}); err != nil {
+		if errors.Is(err, ErrJobAlreadyQueued) {
+			if state.Error != stepWaitingQueue {
+				fmt.Printf("[WORKFLOW] Run %d: Job %d sudah ada di antrean, step menunggu\n", run.ID, jobID)
+			}
+			state.Error = stepWaitingQueue
+			return false
+		}
+		state.Status = "FAILED"
+		state.Error = fmt.Sprintf("Gagal masuk antrean: %v", err)
+		fmt.Printf("⚠️ [WORKFLOW] Run %d: Job %d gagal masuk antrean: %v\n", run.ID, jobID, err)
+		return true
+	}
+	state.Status = StepQueued
+	state.Error = ""
+	fmt.Printf("[WORKFLOW] Run %d: Job %d (%s) masuk antrean\n", run.ID, job.ID, job.JobName)
+	return true
+}
+
+// finishRun: Status agregat — SUCCESS jika semua step yang dijalankan sukses, selain itu FAILED
+func (s *workflowServiceImpl) finishRun(run *models.WorkflowRun, states map[uint]*WorkflowStepState) {
+	succeeded, failed, skipped := 0, 0, 0
+	for _, state := range states {
+		switch state.Status {
+		case "SUCCESS":
+			succeeded++
+		case StepSkipped:
+			skipped++
+		default:
+			failed++
+		}
+	}
+
+	now := time.Now()
+	run.Status = "SUCCESS"
+	if failed > 0 {
+		run.Status = "FAILED"
+	}
+	run.FinishedAt = &now
+	run.DurationSec = int(now.Sub(run.StartedAt).Seconds())
+	run.Message = fmt.Sprintf("%d sukses, %d gagal, %d dilewati", succeeded, failed, skipped)
+
+	fmt.Printf("[WORKFLOW] ⏹️ Run %d (%s) selesai: %s (%s)\n", run.ID, run.WorkflowName, run.Status, run.Message)
+}
+
+func (s *workflowServiceImpl) saveStates(run *models.WorkflowRun, states map[uint]*WorkflowStepState) error {
+	raw, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("gagal serialisasi state workflow: %w", err)
+	}
+	run.StepStates = string(raw)
+	return nil
+}
+
+// LoadStepStates: Decode kolom StepStates (job ID -> state)
+func LoadStepStates(run *models.WorkflowRun) map[uint]*WorkflowStepState {
+	states := make(map[uint]*WorkflowStepState)
+	if run.StepStates != "" {
+		if err := json.Unmarshal([]byte(run.StepStates), &states); err != nil {
+			fmt.Printf("⚠️ [WORKFLOW] State workflow run %d rusak: %v\n", run.ID, err)
+		}
+	}
+	return states
+}
+
+func incomingEdges(wf *models.Workflow, jobID uint) []models.WorkflowEdge {
+	var edges []models.WorkflowEdge
+	for _, edge := range wf.Edges {
+		if edge.ToJobID == jobID {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// edgeSatisfied: ON_SUCCESS butuh parent SUCCESS; ON_FAILURE butuh parent gagal (bukan dilewati)
+func edgeSatisfied(edge models.WorkflowEdge, parentStatus string) bool {
+	if edge.Condition == EdgeOnFailure {
+		return parentStatus != "SUCCESS" && parentStatus != StepSkipped
+	}
+	return parentStatus == "SUCCESS"
+}
+
+func isStepFinal(status string) bool {
+	return status != StepPending && status != StepQueued
+}
+
+func sortedStepIDs(states map[uint]*WorkflowStepState) []uint {
+	ids := make([]uint, 0, len(states))
+	for id := range states {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"gbackup-new/backend/internal/models"
+)
+
+func nodeSet(ids ...uint) map[uint]bool {
+	nodes := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		nodes[id] = true
+	}
+	return nodes
+}
+
+func edge(from, to uint) models.WorkflowEdge {
+	return models.WorkflowEdge{FromJobID: from, ToJobID: to, Condition: EdgeOnSuccess}
+}
+
+func TestCheckAcyclic(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   map[uint]bool
+		edges   []models.WorkflowEdge
+		wantErr bool
+	}{
+		{name: "satu node tanpa edge", nodes: nodeSet(1)},
+		{name: "rantai", nodes: nodeSet(1, 2, 3), edges: []models.WorkflowEdge{edge(1, 2), edge(2, 3)}},
+		{name: "diamond", nodes: nodeSet(1, 2, 3, 4), edges: []models.WorkflowEdge{edge(1, 2), edge(1, 3), edge(2, 4), edge(3, 4)}},
+		{name: "diamond dengan edge pintas", nodes: nodeSet(1, 2, 3, 4), edges: []models.WorkflowEdge{edge(1, 2), edge(1, 3), edge(2, 4), edge(3, 4), edge(1, 4)}},
+		{name: "komponen terpisah", nodes: nodeSet(1, 2, 3, 4), edges: []models.WorkflowEdge{edge(1, 2), edge(3, 4)}},
+		{name: "urutan edge terbalik", nodes: nodeSet(1, 2, 3), edges: []models.WorkflowEdge{edge(2, 3), edge(1, 2)}},
+		{name: "siklus dua node", nodes: nodeSet(1, 2), edges: []models.WorkflowEdge{edge(1, 2), edge(2, 1)}, wantErr: true},
+		{name: "siklus tiga node", nodes: nodeSet(1, 2, 3), edges: []models.WorkflowEdge{edge(1, 2), edge(2, 3), edge(3, 1)}, wantErr: true},
+		{name: "siklus di bawah diamond", nodes: nodeSet(1, 2, 3, 4, 5), edges: []models.WorkflowEdge{edge(1, 2), edge(1, 3), edge(2, 4), edge(3, 4), edge(4, 5), edge(5, 4)}, wantErr: true},
+		{name: "siklus terpisah dari komponen valid", nodes: nodeSet(1, 2, 3, 4), edges: []models.WorkflowEdge{edge(1, 2), edge(3, 4), edge(4, 3)}, wantErr: true},
+		{name: "edge ke dirinya sendiri", nodes: nodeSet(1), edges: []models.WorkflowEdge{edge(1, 1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAcyclic(tt.nodes, tt.edges)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidWorkflow) {
+					t.Fatalf("err = %v, want ErrInvalidWorkflow", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestEdgeSatisfied(t *testing.T) {
+	tests := []struct {
+		condition string
+		parent    string
+		want      bool
+	}{
+		{EdgeOnSuccess, "SUCCESS", true},
+		{EdgeOnSuccess, "FAILED", false},
+		{EdgeOnSuccess, StepSkipped, false},
+		{EdgeOnFailure, "FAILED", true},
+		{EdgeOnFailure, "TIMEOUT_RCLONE", true},
+		{EdgeOnFailure, "SUCCESS", false},
+		{EdgeOnFailure, StepSkipped, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition+"/"+tt.parent, func(t *testing.T) {
+			e := models.WorkflowEdge{Condition: tt.condition}
+			if got := edgeSatisfied(e, tt.parent); got != tt.want {
+				t.Errorf("edgeSatisfied = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package database
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"time"
 
 	"gbackup-new/backend/internal/models" // Sesuaikan dengan module baru Anda
 
@@ -23,13 +25,23 @@ func Connect() *gorm.DB {
 	dbPort := os.Getenv("DB_PORT")
 	dbName := os.Getenv("DB_NAME")
 
+	// Zona waktu untuk parsing DATETIME (default: zona waktu server)
+	dbLoc := os.Getenv("DB_TIMEZONE")
+	if dbLoc == "" {
+		dbLoc = "Local"
+	}
+	if _, err := time.LoadLocation(dbLoc); err != nil {
+		log.Fatalf("❌ FATAL: DB_TIMEZONE '%s' tidak valid: %v", dbLoc, err)
+	}
+
 	// Buat DSN (Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=%s",
 		dbUser,
 		dbPass,
 		dbHost,
 		dbPort,
-		dbName)
+		dbName,
+		url.QueryEscape(dbLoc))
 
 	var err error
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
@@ -46,6 +58,17 @@ func Connect() *gorm.DB {
 		&models.Log{},
 		&models.Monitoring{},
 		&models.Remote{},
+		&models.QueueEntry{},
+		&models.JobRun{},
+		&models.BlackoutWindow{},
+		&models.Workflow{},
+		&models.WorkflowStep{},
+		&models.WorkflowEdge{},
+		&models.WorkflowRun{},
+		&models.Snapshot{},
+		&models.CatalogEntry{},
+		&models.RestorePreview{},
+		&models.RestoreRecord{},
 	)
 	if err != nil {
 		log.Fatalf("❌ Gagal melakukan AutoMigrate tabel: %v", err)